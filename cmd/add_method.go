@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// addMethodCmd represents the add method command
+var addMethodCmd = &cobra.Command{
+	Use:     "method",
+	Aliases: []string{"m"},
+	Short:   "Add a method to the service and generate it in all the layers",
+	Long: `Add a method to the service interface and generate its implementation stub, middlewares,
+endpoint, transports and clients, the code of the other methods is not changed.
+
+	kit add method hello -m "Bar(ctx context.Context, n int) (int, error)" --doc "Bar does bar."`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			logrus.Error("You must provide a name for the service")
+			return nil
+		}
+		sig := viper.GetString("a_m_method")
+		if sig == "" {
+			logrus.Error("You must provide the signature of the method")
+			return nil
+		}
+		g := generator.NewAddMethod(args[0], sig, viper.GetString("a_m_doc"))
+		return g.Generate()
+	},
+}
+
+func init() {
+	addCmd.AddCommand(addMethodCmd)
+	addMethodCmd.Flags().StringP("method", "m", "", "The signature of the method e.x \"Bar(ctx context.Context, n int) (int, error)\"")
+	addMethodCmd.Flags().String("doc", "", "The doc comment of the method, it can hold the @http annotations")
+	utils.BindFlag("a_m_method", addMethodCmd.Flags().Lookup("method"))
+	utils.BindFlag("a_m_doc", addMethodCmd.Flags().Lookup("doc"))
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -12,25 +13,24 @@ var clientCmd = &cobra.Command{
 	Use:     "client",
 	Short:   "Generate simple client lib",
 	Aliases: []string{"c"},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			logrus.Error("You must provide a name for the service")
-			return
+			return nil
 		}
 		g := generator.NewGenerateClient(
 			args[0],
 			viper.GetString("g_c_transport"),
+			nil,
 		)
-		if err := g.Generate(); err != nil {
-			logrus.Error(err)
-		}
+		return g.Generate()
 	},
 }
 
 func init() {
 	generateCmd.AddCommand(clientCmd)
 	clientCmd.Flags().StringP("transport", "t", "http", "The transport you want your client to be initiated")
-	viper.BindPFlag("g_c_transport", clientCmd.Flags().Lookup("transport"))
+	utils.BindFlag("g_c_transport", clientCmd.Flags().Lookup("transport"))
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
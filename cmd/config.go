@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Project configuration commands",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each value comes from",
+	Run: func(cmd *cobra.Command, args []string) {
+		if f := viper.GetString("gk_config_file"); f != "" {
+			fmt.Printf("Config file: %s\n\n", f)
+		} else {
+			fmt.Printf("Config file: none (no %s found)\n\n", utils.ConfigFileName)
+		}
+		keys := []string{}
+		for _, k := range viper.AllKeys() {
+			if k == "gk_config_file" {
+				continue
+			}
+			if strings.HasPrefix(k, "gk_") || k == "g_s_transport" || k == "g_c_transport" ||
+				k == "g_s_svc_mdw" || k == "g_s_endpoint_mdw" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%v\t%s\n", k, viper.Get(k), utils.ConfigSource(k))
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+}
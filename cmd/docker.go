@@ -2,7 +2,7 @@ package cmd
 
 import (
 	"github.com/kujtimiihoxha/kit/generator"
-	"github.com/sirupsen/logrus"
+	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -12,17 +12,15 @@ var dockerCmd = &cobra.Command{
 	Use:     "docker",
 	Aliases: []string{"d"},
 	Short:   "Generate docker files",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		g := generator.NewGenerateDocker(viper.GetBool("g_d_glide"))
-		if err := g.Generate(); err != nil {
-			logrus.Error(err)
-		}
+		return g.Generate()
 	},
 }
 
 func init() {
 	generateCmd.AddCommand(dockerCmd)
 	dockerCmd.Flags().Bool("glide", false, "Generate docker for project that uses glide package manager")
-	viper.BindPFlag("g_d_glide", dockerCmd.Flags().Lookup("glide"))
+	utils.BindFlag("g_d_glide", dockerCmd.Flags().Lookup("glide"))
 
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -12,15 +13,13 @@ var initserviceCmd = &cobra.Command{
 	Use:     "service",
 	Short:   "Initiate a service",
 	Aliases: []string{"s"},
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			logrus.Error("You must provide a name for the service")
-			return
+			return nil
 		}
-		if viper.GetString("g_s_transport") == "grpc" {
-			if !checkProtoc() {
-				return
-			}
+		if !checkTransport(viper.GetString("g_s_transport")) {
+			return nil
 		}
 		var emw, smw bool
 		if viper.GetBool("g_s_dmw") {
@@ -38,12 +37,24 @@ var initserviceCmd = &cobra.Command{
 			emw,
 			methods,
 		)
-		if err := g.Generate(); err != nil {
-			logrus.Error(err)
-		}
+		return g.Generate()
 	},
 }
 
+// checkTransport checks that the compiler the transport needs is installed, the
+// compilers are not run during a dry run.
+func checkTransport(transport string) bool {
+	if viper.GetBool("gk_dry_run") {
+		return true
+	}
+	if transport == "grpc" {
+		return checkProtoc()
+	} else if transport == "thrift" {
+		return checkThrift()
+	}
+	return true
+}
+
 func init() {
 	generateCmd.AddCommand(initserviceCmd)
 	initserviceCmd.Flags().StringP("transport", "t", "http", "The transport you want your service to be initiated with")
@@ -52,9 +63,9 @@ func init() {
 	initserviceCmd.Flags().StringArrayVarP(&methods, "methods", "m", []string{}, "Specify methods to be generated")
 	initserviceCmd.Flags().Bool("svc-mdw", false, "If set a default Logging and Instrumental middleware will be created and attached to the service")
 	initserviceCmd.Flags().Bool("endpoint-mdw", false, "If set a default Logging and Tracking middleware will be created and attached to the endpoint")
-	viper.BindPFlag("g_s_transport", initserviceCmd.Flags().Lookup("transport"))
-	viper.BindPFlag("g_s_dmw", initserviceCmd.Flags().Lookup("dmw"))
-	viper.BindPFlag("g_s_gorilla", initserviceCmd.Flags().Lookup("gorilla"))
-	viper.BindPFlag("g_s_svc_mdw", initserviceCmd.Flags().Lookup("svc-mdw"))
-	viper.BindPFlag("g_s_endpoint_mdw", initserviceCmd.Flags().Lookup("endpoint-mdw"))
+	utils.BindFlag("g_s_transport", initserviceCmd.Flags().Lookup("transport"))
+	utils.BindFlag("g_s_dmw", initserviceCmd.Flags().Lookup("dmw"))
+	utils.BindFlag("g_s_gorilla", initserviceCmd.Flags().Lookup("gorilla"))
+	utils.BindFlag("g_s_svc_mdw", initserviceCmd.Flags().Lookup("svc-mdw"))
+	utils.BindFlag("g_s_endpoint_mdw", initserviceCmd.Flags().Lookup("endpoint-mdw"))
 }
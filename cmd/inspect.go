@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// inspectCmd represents the inspect command
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Print the methods, transports, middlewares and stale files of the services",
+	Long: `Print the methods, transports, middlewares and stale files of a service, or of all
+the services of the project if no service is given. Use --json for a machine-readable output.
+
+	kit inspect hello --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		models, err := generator.InspectServices(args...)
+		if err != nil {
+			return err
+		}
+		if viper.GetBool("inspect_json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(models)
+		}
+		printServices(os.Stdout, models)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(inspectCmd)
+	inspectCmd.Flags().Bool("json", false, "Print the project model as JSON")
+	utils.BindFlag("inspect_json", inspectCmd.Flags().Lookup("json"))
+}
+
+// printServices prints a human readable summary of the services.
+func printServices(w io.Writer, models []generator.ServiceModel) {
+	for i, m := range models {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Service %s (%s)\n", m.Name, m.Interface)
+		fmt.Fprintln(w, "  Methods:")
+		for _, v := range m.Methods {
+			fmt.Fprintf(w, "    %s(%s) (%s)\n", v.Name, params(v.Parameters), params(v.Results))
+		}
+		fmt.Fprintf(w, "  Transports: %s\n", list(m.Transports))
+		fmt.Fprintf(w, "  Clients: %s\n", list(m.Clients))
+		fmt.Fprintf(w, "  Service middlewares: %s\n", middlewares(m.Middlewares.Service))
+		fmt.Fprintf(w, "  Endpoint middlewares: %s\n", middlewares(m.Middlewares.Endpoint))
+		if len(m.Stale) == 0 {
+			fmt.Fprintln(w, "  Stale files: none")
+			continue
+		}
+		fmt.Fprintln(w, "  Stale files:")
+		for _, s := range m.Stale {
+			fmt.Fprintf(w, "    %s", s.Path)
+			if len(s.Missing) > 0 {
+				fmt.Fprintf(w, " missing: %s", strings.Join(s.Missing, ", "))
+			}
+			if len(s.Extra) > 0 {
+				fmt.Fprintf(w, " extra: %s", strings.Join(s.Extra, ", "))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func params(ps []generator.ParamModel) string {
+	s := []string{}
+	for _, p := range ps {
+		s = append(s, strings.TrimSpace(p.Name+" "+p.Type))
+	}
+	return strings.Join(s, ", ")
+}
+
+func middlewares(ms []generator.MiddlewareModel) string {
+	s := []string{}
+	for _, m := range ms {
+		if m.Wired {
+			s = append(s, m.Name)
+		} else {
+			s = append(s, m.Name+" (not wired)")
+		}
+	}
+	return list(s)
+}
+
+func list(s []string) string {
+	if len(s) == 0 {
+		return "none"
+	}
+	return strings.Join(s, ", ")
+}
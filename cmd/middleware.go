@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -12,15 +13,15 @@ var middlewareCmd = &cobra.Command{
 	Use:     "middleware",
 	Aliases: []string{"m", "mdw"},
 	Short:   "Generate middleware",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			logrus.Error("You must provide a name for the middleware")
-			return
+			return nil
 		}
 		sn := viper.GetString("g_m_service")
 		if sn == "" {
 			logrus.Error("You must provide the name of the service")
-			return
+			return nil
 		}
 		g := generator.NewGenerateMiddleware(
 			args[0],
@@ -28,7 +29,7 @@ var middlewareCmd = &cobra.Command{
 			viper.GetBool("g_m_endpoint"),
 		)
 		if err := g.Generate(); err != nil {
-			logrus.Error(err)
+			return err
 		}
 		if viper.GetBool("g_m_endpoint") {
 			logrus.Info("Do not forget to append your endpoint middleware to your service middlewares")
@@ -39,6 +40,7 @@ var middlewareCmd = &cobra.Command{
 			logrus.Info("Do not forget to append your service middleware to your service middlewares")
 			logrus.Info("Add it to cmd/service/service.go#getServiceMiddleware()")
 		}
+		return nil
 	},
 }
 
@@ -46,8 +48,8 @@ func init() {
 	generateCmd.AddCommand(middlewareCmd)
 	middlewareCmd.Flags().StringP("service", "s", "",
 		"Service name that the middleware will be created for")
-	viper.BindPFlag("g_m_service", middlewareCmd.Flags().Lookup("service"))
+	utils.BindFlag("g_m_service", middlewareCmd.Flags().Lookup("service"))
 	middlewareCmd.Flags().BoolP("endpoint", "e", false,
 		"If set create endpoint middleware")
-	viper.BindPFlag("g_m_endpoint", middlewareCmd.Flags().Lookup("endpoint"))
+	utils.BindFlag("g_m_endpoint", middlewareCmd.Flags().Lookup("endpoint"))
 }
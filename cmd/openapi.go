@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// openapiCmd represents the openapi command
+var openapiCmd = &cobra.Command{
+	Use:     "openapi",
+	Short:   "Generate the OpenAPI document of the http transport",
+	Aliases: []string{"oa"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			logrus.Error("You must provide a name for the service")
+			return nil
+		}
+		g := generator.NewGenerateOpenAPI(args[0])
+		return g.Generate()
+	},
+}
+
+func init() {
+	generateCmd.AddCommand(openapiCmd)
+}
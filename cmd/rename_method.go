@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// renameMethodCmd represents the rename method command
+var renameMethodCmd = &cobra.Command{
+	Use:   "method",
+	Short: "Rename a method of the service in all the generated layers",
+	Long: `Rename a method of the service in the interface, the middleware, the endpoints,
+the transports, the proto file and the clients, the hand-written bodies are kept.
+
+	kit rename method hello Foo Bar`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 3 {
+			logrus.Error("You must provide the service name, the method name and the new method name")
+			return nil
+		}
+		g := generator.NewRenameMethod(args[0], args[1], args[2])
+		return g.Generate()
+	},
+}
+
+func init() {
+	renameCmd.AddCommand(renameMethodCmd)
+}
@@ -1,15 +1,24 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// Version is the version of kit recorded in the manifest of the generated files, it is
+// set when building a release with `-ldflags "-X github.com/kujtimiihoxha/kit/cmd.Version=<version>"`.
+var Version = "dev"
+
 // RootCmd is the root command of kit
 var RootCmd = &cobra.Command{
 	Use:   "kit",
@@ -17,6 +26,35 @@ var RootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// the config is loaded after the flags are parsed to find it in the --folder.
+		if err := utils.LoadConfig(); err != nil {
+			return err
+		}
+		fs.Get().SetGenerator(cmd.CommandPath(), Version)
+		p := viper.GetString("gk_on_conflict")
+		for _, v := range fs.ConflictPolicies {
+			if p == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown conflict policy `%s`, use one of %s", p, strings.Join(fs.ConflictPolicies, ", "))
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("gk_dry_run") {
+			printDryRun(os.Stdout, fs.Get().Changes())
+			return nil
+		}
+		// the files are written only if the command succeeds, cobra does
+		// not run the post run if the command returns an error.
+		if err := fs.Get().Commit(); err != nil {
+			return err
+		}
+		printConflicts(os.Stdout, fs.Get())
+		return nil
+	},
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 // Execute runs the root command
@@ -28,12 +66,54 @@ func Execute() {
 }
 
 func init() {
+	RootCmd.Version = Version
 	RootCmd.PersistentFlags().BoolP("debug", "d", false, "If you want to se the debug logs.")
 	RootCmd.PersistentFlags().BoolP("force", "f", false, "Force overide existing files without asking.")
 	RootCmd.PersistentFlags().StringP("folder", "b", "", "If you want to specify the base folder of the project.")
-	viper.BindPFlag("gk_folder", RootCmd.PersistentFlags().Lookup("folder"))
-	viper.BindPFlag("gk_force", RootCmd.PersistentFlags().Lookup("force"))
-	viper.BindPFlag("gk_debug", RootCmd.PersistentFlags().Lookup("debug"))
+	RootCmd.PersistentFlags().Bool("dry-run", false, "Print the files that would be created or modified and their diff without writing them.")
+	RootCmd.PersistentFlags().String(
+		"on-conflict", fs.ConflictAsk,
+		"What to do with existing files that differ from the generated ones: "+strings.Join(fs.ConflictPolicies, "|")+".",
+	)
+	utils.BindFlag("gk_on_conflict", RootCmd.PersistentFlags().Lookup("on-conflict"))
+	utils.BindFlag("gk_dry_run", RootCmd.PersistentFlags().Lookup("dry-run"))
+	utils.BindFlag("gk_folder", RootCmd.PersistentFlags().Lookup("folder"))
+	utils.BindFlag("gk_force", RootCmd.PersistentFlags().Lookup("force"))
+	utils.BindFlag("gk_debug", RootCmd.PersistentFlags().Lookup("debug"))
+}
+
+// printDryRun prints the status of every file written during a dry run followed
+// by the diffs of the files that would be created or modified.
+func printDryRun(w io.Writer, changes []*fs.Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Dry run, no files would be written.")
+		return
+	}
+	fmt.Fprintln(w, "Dry run, no files were written:")
+	for _, c := range changes {
+		fmt.Fprintf(w, "  %-9s %s\n", c.Status(), c.Path)
+	}
+	for _, c := range changes {
+		if d := c.Diff(); d != "" {
+			fmt.Fprintf(w, "\n%s", d)
+		}
+	}
+}
+
+// printConflicts prints the existing files that were skipped or backed up because of the conflict policy.
+func printConflicts(w io.Writer, f *fs.KitFs) {
+	if len(f.Skipped()) > 0 {
+		fmt.Fprintln(w, "Skipped the existing files:")
+		for _, p := range f.Skipped() {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+	if len(f.Backups()) > 0 {
+		fmt.Fprintln(w, "Overwrote the existing files, the old versions are in:")
+		for _, p := range f.Backups() {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
 }
 
 func checkProtoc() bool {
@@ -80,3 +160,19 @@ sudo ldconfig # refresh shared library cache.`)
 	}
 	return true
 }
+
+func checkThrift() bool {
+	p := exec.Command("thrift", "-version")
+	if p.Run() != nil {
+		logrus.Error("Please install the thrift compiler first and than rerun the command")
+		logrus.Info(
+			`Install the thrift compiler.
+https://thrift.apache.org/download
+
+See also
+https://github.com/go-kit/kit/tree/master/examples/addsvc/thrift`,
+		)
+		return false
+	}
+	return true
+}
@@ -2,26 +2,77 @@ package cmd
 
 import (
 	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var newMethods []string
+var newTransports []string
 var serviceCmd = &cobra.Command{
 	Use:     "service",
 	Short:   "Generate new service",
 	Aliases: []string{"s"},
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
+	Long: `Generate a new service, the methods of the interface can be given with -m or
+read from a spec file with --from. With -t the service is generated with the
+transport right away like kit g s does.
+
+	kit n s hello -m "Foo(ctx context.Context, s string) (string, error)" -t http
+	kit n s --from service.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec := generator.ServiceSpec{}
+		if from := viper.GetString("n_s_from"); from != "" {
+			var err error
+			if spec, err = generator.ReadServiceSpec(from); err != nil {
+				return err
+			}
+		}
+		name := spec.Name
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
 			logrus.Error("You must provide a name for the service")
-			return
+			return nil
+		}
+		for _, m := range newMethods {
+			spec.Methods = append(spec.Methods, generator.MethodSpec{Signature: m})
 		}
-		g := generator.NewNewService(args[0])
+		transports := spec.Transports
+		if len(newTransports) > 0 {
+			transports = newTransports
+		}
+		for _, t := range transports {
+			if !checkTransport(t) {
+				return nil
+			}
+		}
+		g := generator.NewNewService(name, viper.GetString("n_s_module"), spec)
 		if err := g.Generate(); err != nil {
-			logrus.Error(err)
+			return err
+		}
+		dmw := viper.GetBool("n_s_dmw")
+		for _, t := range transports {
+			g := generator.NewGenerateService(name, t, dmw, viper.GetBool("n_s_gorilla"), dmw, nil)
+			if err := g.Generate(); err != nil {
+				return err
+			}
 		}
+		return nil
 	},
 }
 
 func init() {
 	newCmd.AddCommand(serviceCmd)
+	serviceCmd.Flags().String("module", "", "The module path used if a go.mod needs to be created (defaults to the service name)")
+	serviceCmd.Flags().StringArrayVarP(&newMethods, "methods", "m", []string{}, "A method of the service interface e.x \"Foo(ctx context.Context, s string) (string, error)\"")
+	serviceCmd.Flags().String("from", "", "Read the service from a spec file")
+	serviceCmd.Flags().StringArrayVarP(&newTransports, "transport", "t", []string{}, "Generate the service with the transport like kit g s does")
+	serviceCmd.Flags().BoolP("dmw", "w", false, "Generate default middleware for service and endpoint, used with --transport")
+	serviceCmd.Flags().Bool("gorilla", false, "Generate http using gorilla mux, used with --transport")
+	utils.BindFlag("n_s_module", serviceCmd.Flags().Lookup("module"))
+	utils.BindFlag("n_s_from", serviceCmd.Flags().Lookup("from"))
+	utils.BindFlag("n_s_dmw", serviceCmd.Flags().Lookup("dmw"))
+	utils.BindFlag("n_s_gorilla", serviceCmd.Flags().Lookup("gorilla"))
 }
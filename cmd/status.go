@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List the generated files and the ones modified or orphaned since",
+	Long: `List the files kit generated in the project with their state:
+
+	generated  the file has the content kit wrote
+	modified   the file was edited after kit wrote it
+	deleted    the file was removed
+	orphaned   the service the file was generated for was removed
+
+Use --json for a machine-readable output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := generator.ProjectStatus()
+		if err != nil {
+			return err
+		}
+		if viper.GetBool("status_json") {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(statusModels(files))
+		}
+		printStatus(os.Stdout, files)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().Bool("json", false, "Print the files as JSON")
+	utils.BindFlag("status_json", statusCmd.Flags().Lookup("json"))
+}
+
+type statusModel struct {
+	fs.ManifestFile
+	State string `json:"state"`
+}
+
+func statusModels(files []fs.ManifestFile) []statusModel {
+	s := []statusModel{}
+	for _, f := range files {
+		s = append(s, statusModel{f, f.State})
+	}
+	return s
+}
+
+// printStatus prints the state of every generated file.
+func printStatus(w io.Writer, files []fs.ManifestFile) {
+	if len(files) == 0 {
+		fmt.Fprintf(w, "No generated files, `%s` was not found.\n", fs.ManifestPath)
+		return
+	}
+	for _, f := range files {
+		fmt.Fprintf(w, "  %-9s %s\n", f.State, f.Path)
+	}
+}
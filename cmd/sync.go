@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/kujtimiihoxha/kit/generator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Remove the generated code of the methods that are no longer in the service interface",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			logrus.Error("You must provide a name for the service")
+			return nil
+		}
+		g := generator.NewSyncService(args[0])
+		return g.Generate()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(syncCmd)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// undoCmd represents the undo command
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore the files changed by the last command",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetBool("gk_dry_run") {
+			return errors.New("undo does not support --dry-run")
+		}
+		restored, err := fs.Get().Undo()
+		for _, p := range restored {
+			logrus.Infof("Restored `%s`", p)
+		}
+		return err
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(undoCmd)
+}
@@ -3,8 +3,11 @@ package fs
 import (
 	"fmt"
 	"os"
+	"path"
+	"strings"
 
 	"github.com/Songmu/prompter"
+	"github.com/mattn/go-isatty"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/viper"
@@ -12,9 +15,63 @@ import (
 
 var defaultFs *KitFs
 
+// The policies used when a file that kit wants to write already exists with a different content.
+const (
+	ConflictAsk       = "ask"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictFail      = "fail"
+	ConflictBackup    = "backup"
+)
+
+// ConflictPolicies are the valid values of gk_on_conflict.
+var ConflictPolicies = []string{ConflictAsk, ConflictSkip, ConflictOverwrite, ConflictFail, ConflictBackup}
+
+// BackupSuffix is appended to the path of the backup of a file overwritten with the backup policy.
+const BackupSuffix = ".orig"
+
 // KitFs wraps an afero.Fs
 type KitFs struct {
 	Fs afero.Fs
+
+	// base is the file system the staged files are committed to.
+	base     afero.Fs
+	changes  []*Change
+	skipped  []string
+	backups  []string
+	onCommit []func() error
+
+	// generator and version are recorded in the manifest for the files written during the run.
+	generator string
+	version   string
+}
+
+// Change is a file written during the run.
+type Change struct {
+	Path    string
+	Old     string
+	New     string
+	Existed bool
+}
+
+// Status returns `create`, `modify` or `unchanged` depending on what
+// the write would do to the file.
+func (c *Change) Status() string {
+	if !c.Existed {
+		return "create"
+	} else if c.Old != c.New {
+		return "modify"
+	}
+	return "unchanged"
+}
+
+// Diff returns the unified diff of the change.
+func (c *Change) Diff() string {
+	from := "a/" + c.Path
+	if !c.Existed {
+		from = "/dev/null"
+	}
+	return Diff(from, "b/"+c.Path, c.Old, c.New)
 }
 
 func (f *KitFs) init(dir string) {
@@ -29,10 +86,12 @@ func (f *KitFs) init(dir string) {
 		}
 	}
 	if dir != "" {
-		f.Fs = afero.NewBasePathFs(inFs, dir)
-	} else {
-		f.Fs = inFs
+		inFs = afero.NewBasePathFs(inFs, dir)
 	}
+	// all the writes are staged in memory so the generators still see their own
+	// changes while the tree is left untouched until the run is committed.
+	f.base = inFs
+	f.Fs = afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(inFs), afero.NewMemMapFs())
 }
 
 // ReadFile reads the file from `path` and returns the content in string format
@@ -43,20 +102,102 @@ func (f *KitFs) ReadFile(path string) (string, error) {
 }
 
 // WriteFile writs a file to the `path` with `data` as content, if `force` is set
-// to true it will override the file if it already exists.
+// to true it will override the file if it already exists, otherwise the conflict
+// policy set with gk_on_conflict decides what happens. The `_gen` files that were
+// edited by hand after kit generated them go through the conflict policy even if
+// `force` is set.
 func (f *KitFs) WriteFile(path string, data string, force bool) error {
-	if b, _ := f.Exists(path); b && !(viper.GetBool("gk_force_override") || force) {
+	if viper.GetBool("gk_dry_run") {
+		return f.write(path, data)
+	} else if b, _ := f.Exists(path); b && (!force || f.editedByHand(path)) {
 		s, _ := f.ReadFile(path)
 		if s == data {
 			logrus.Warnf("`%s` exists and is identical it will be ignored", path)
 			return nil
 		}
-		b := prompter.YN(fmt.Sprintf("`%s` already exists do you want to override it ?", path), false)
-		if !b {
+		exists := "already exists"
+		if force {
+			exists = "was edited after kit generated it"
+		}
+		switch conflictPolicy(path, exists) {
+		case ConflictOverwrite:
+		case ConflictAsk:
+			if !prompter.YN(fmt.Sprintf("`%s` %s do you want to override it ?", path, exists), false) {
+				f.skipped = append(f.skipped, path)
+				return nil
+			}
+		case ConflictSkip:
+			f.skipped = append(f.skipped, path)
 			return nil
+		case ConflictFail:
+			return fmt.Errorf("`%s` %s and is different from the generated file", path, exists)
+		case ConflictBackup:
+			if err := f.write(path+BackupSuffix, s); err != nil {
+				return err
+			}
+			f.backups = append(f.backups, path+BackupSuffix)
+		default:
+			return fmt.Errorf(
+				"unknown conflict policy `%s`, use one of %s",
+				viper.GetString("gk_on_conflict"), strings.Join(ConflictPolicies, ", "),
+			)
+		}
+	}
+	return f.write(path, data)
+}
+
+func (f *KitFs) write(pth string, data string) error {
+	f.record(pth, data)
+	if err := f.Fs.MkdirAll(path.Dir(pth), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(f.Fs, pth, []byte(data), os.ModePerm)
+}
+
+// conflictPolicy returns the policy for `path`, --force always overwrites and
+// ask falls back to skip if there is no terminal to answer the prompt, `exists`
+// tells why the file is in conflict in the warning.
+func conflictPolicy(path, exists string) string {
+	if viper.GetBool("gk_force") || viper.GetBool("gk_force_override") {
+		return ConflictOverwrite
+	}
+	p := viper.GetString("gk_on_conflict")
+	if p == "" || p == ConflictAsk {
+		if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+			logrus.Warnf("`%s` %s and there is no terminal to ask, it will be skipped", path, exists)
+			return ConflictSkip
 		}
+		return ConflictAsk
 	}
-	return afero.WriteFile(f.Fs, path, []byte(data), os.ModePerm)
+	return p
+}
+
+// Skipped returns the existing files that were not overwritten because of the conflict policy.
+func (f *KitFs) Skipped() []string {
+	return f.skipped
+}
+
+// Backups returns the backups of the files overwritten with the backup policy.
+func (f *KitFs) Backups() []string {
+	return f.backups
+}
+
+func (f *KitFs) record(path string, data string) {
+	for _, c := range f.changes {
+		if c.Path == path {
+			c.New = data
+			return
+		}
+	}
+	c := &Change{Path: path, New: data}
+	c.Existed, _ = f.Exists(path)
+	c.Old, _ = f.ReadFile(path)
+	f.changes = append(f.changes, c)
+}
+
+// Changes returns the files written during the run in the order they were first written.
+func (f *KitFs) Changes() []*Change {
+	return f.changes
 }
 
 // Mkdir creates a directory.
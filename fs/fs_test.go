@@ -0,0 +1,117 @@
+package fs
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+func TestDiff(t *testing.T) {
+	Convey("Test if equal files have no diff", t, func() {
+		So(Diff("a/f", "b/f", "a\nb\n", "a\nb\n"), ShouldEqual, "")
+	})
+	Convey("Test if a new file is one addition hunk", t, func() {
+		So(Diff("/dev/null", "b/f", "", "a\nb\n"), ShouldEqual, "--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n")
+	})
+	Convey("Test if the changes get three lines of context", t, func() {
+		a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+		b := "1\nx\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\ny\n"
+		So(Diff("a/f", "b/f", a, b), ShouldEqual, "--- a/f\n+++ b/f\n"+
+			"@@ -1,5 +1,5 @@\n 1\n-2\n+x\n 3\n 4\n 5\n"+
+			"@@ -14,3 +14,4 @@\n 14\n 15\n 16\n+y\n")
+	})
+	Convey("Test if close changes are merged in one hunk", t, func() {
+		a := "1\n2\n3\n4\n5\n6\n7\n8\n"
+		b := "x\n2\n3\n4\n5\n6\n7\ny\n"
+		So(Diff("a/f", "b/f", a, b), ShouldEqual, "--- a/f\n+++ b/f\n"+
+			"@@ -1,8 +1,8 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n")
+	})
+	Convey("Test if a missing newline at the end is marked", t, func() {
+		So(Diff("a/f", "b/f", "a", "b"), ShouldEqual, "--- a/f\n+++ b/f\n@@ -1,1 +1,1 @@\n"+
+			"-a\n\\ No newline at end of file\n+b\n\\ No newline at end of file\n")
+	})
+}
+
+func TestKitFs_DryRun(t *testing.T) {
+	viper.Set("gk_dry_run", true)
+	defer viper.Set("gk_dry_run", false)
+	base := afero.NewMemMapFs()
+	afero.WriteFile(base, "svc/service.go", []byte("package service\n"), 0644)
+	afero.WriteFile(base, "svc/same.go", []byte("package same\n"), 0644)
+	f := &KitFs{Fs: afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs())}
+	Convey("Test if the writes are recorded without touching the base", t, func() {
+		So(f.WriteFile("svc/service.go", "package service\n\nvar a int\n", false), ShouldBeNil)
+		So(f.WriteFile("svc/endpoint.go", "package endpoint\n", false), ShouldBeNil)
+		So(f.WriteFile("svc/endpoint.go", "package endpoint\n\nvar b int\n", false), ShouldBeNil)
+		So(f.WriteFile("svc/same.go", "package same\n", false), ShouldBeNil)
+		s, _ := f.ReadFile("svc/endpoint.go")
+		So(s, ShouldEqual, "package endpoint\n\nvar b int\n")
+		b, _ := afero.Exists(base, "svc/endpoint.go")
+		So(b, ShouldBeFalse)
+		d, _ := afero.ReadFile(base, "svc/service.go")
+		So(string(d), ShouldEqual, "package service\n")
+		Convey("Test if the changes have the right status", func() {
+			c := f.Changes()
+			So(len(c), ShouldEqual, 3)
+			So(c[0].Status(), ShouldEqual, "modify")
+			So(c[1].Status(), ShouldEqual, "create")
+			So(c[1].Old, ShouldEqual, "")
+			So(c[1].New, ShouldEqual, "package endpoint\n\nvar b int\n")
+			So(c[2].Status(), ShouldEqual, "unchanged")
+			So(c[2].Diff(), ShouldEqual, "")
+			So(c[0].Diff(), ShouldStartWith, "--- a/svc/service.go\n+++ b/svc/service.go\n")
+		})
+	})
+}
+
+func TestKitFs_WriteFileConflicts(t *testing.T) {
+	defer viper.Set("gk_on_conflict", ConflictAsk)
+	f := &KitFs{Fs: afero.NewMemMapFs()}
+	reset := func() {
+		afero.WriteFile(f.Fs, "svc/service.go", []byte("old"), 0644)
+	}
+	Convey("Test if skip keeps the existing file", t, func() {
+		reset()
+		viper.Set("gk_on_conflict", ConflictSkip)
+		So(f.WriteFile("svc/service.go", "new", false), ShouldBeNil)
+		s, _ := f.ReadFile("svc/service.go")
+		So(s, ShouldEqual, "old")
+		So(f.Skipped(), ShouldResemble, []string{"svc/service.go"})
+		Convey("Test if force still overwrites the file", func() {
+			So(f.WriteFile("svc/service.go", "new", true), ShouldBeNil)
+			s, _ := f.ReadFile("svc/service.go")
+			So(s, ShouldEqual, "new")
+		})
+	})
+	Convey("Test if fail returns an error", t, func() {
+		reset()
+		viper.Set("gk_on_conflict", ConflictFail)
+		So(f.WriteFile("svc/service.go", "new", false), ShouldNotBeNil)
+		So(f.WriteFile("svc/service.go", "old", false), ShouldBeNil)
+		So(f.WriteFile("svc/endpoint.go", "new", false), ShouldBeNil)
+	})
+	Convey("Test if overwrite replaces the file", t, func() {
+		reset()
+		viper.Set("gk_on_conflict", ConflictOverwrite)
+		So(f.WriteFile("svc/service.go", "new", false), ShouldBeNil)
+		s, _ := f.ReadFile("svc/service.go")
+		So(s, ShouldEqual, "new")
+	})
+	Convey("Test if backup keeps the old file next to the new one", t, func() {
+		reset()
+		viper.Set("gk_on_conflict", ConflictBackup)
+		So(f.WriteFile("svc/service.go", "new", false), ShouldBeNil)
+		s, _ := f.ReadFile("svc/service.go")
+		So(s, ShouldEqual, "new")
+		s, _ = f.ReadFile("svc/service.go" + BackupSuffix)
+		So(s, ShouldEqual, "old")
+		So(f.Backups(), ShouldResemble, []string{"svc/service.go" + BackupSuffix})
+	})
+	Convey("Test if an unknown policy returns an error", t, func() {
+		reset()
+		viper.Set("gk_on_conflict", "merge")
+		So(f.WriteFile("svc/service.go", "new", false), ShouldNotBeNil)
+	})
+}
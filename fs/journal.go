@@ -0,0 +1,179 @@
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// JournalPath is where the journal of the last committed run is kept, relative to the project folder.
+var JournalPath = path.Join(".kit", "journal.json")
+
+type journal struct {
+	Files []journalFile `json:"files"`
+	Dirs  []string      `json:"dirs,omitempty"`
+}
+
+type journalFile struct {
+	Path    string `json:"path"`
+	Existed bool   `json:"existed"`
+	Content string `json:"content,omitempty"`
+	// Sum is the checksum of the committed content, used to detect files edited after the run.
+	Sum string `json:"sum"`
+}
+
+func checksum(s string) string {
+	b := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(b[:])
+}
+
+// OnCommit registers `fn` to run after the staged files are written to the disk,
+// if it fails the files are restored.
+func (f *KitFs) OnCommit(fn func() error) {
+	f.onCommit = append(f.onCommit, fn)
+}
+
+// Commit writes the files staged during the run to the disk and keeps their previous
+// versions in the journal so `kit undo` can restore them, the checksums of the new
+// versions are kept in the manifest. If a write or one of the
+// OnCommit functions fails the files are restored and the error is returned.
+func (f *KitFs) Commit() error {
+	if err := f.stageManifest(); err != nil {
+		return err
+	}
+	j := journal{}
+	for _, c := range f.changes {
+		if c.Status() == "unchanged" {
+			continue
+		}
+		j.Files = append(j.Files, journalFile{Path: c.Path, Existed: c.Existed, Content: c.Old, Sum: checksum(c.New)})
+		for d := path.Dir(c.Path); d != "." && d != "/"; d = path.Dir(d) {
+			if b, _ := afero.Exists(f.base, d); b {
+				break
+			}
+			j.Dirs = appendMissing(j.Dirs, d)
+		}
+	}
+	changes, hooks := f.changes, f.onCommit
+	f.changes, f.onCommit = nil, nil
+	// the journal of the previous run is kept if this one is rolled back.
+	prev, _ := afero.ReadFile(f.base, JournalPath)
+	if len(j.Files) > 0 {
+		if err := f.writeJournal(j); err != nil {
+			return err
+		}
+		for _, c := range changes {
+			if c.Status() == "unchanged" {
+				continue
+			}
+			err := f.base.MkdirAll(path.Dir(c.Path), os.ModePerm)
+			if err == nil {
+				err = afero.WriteFile(f.base, c.Path, []byte(c.New), os.ModePerm)
+			}
+			if err != nil {
+				return f.rollback(j, prev, err)
+			}
+		}
+	}
+	for _, fn := range hooks {
+		if err := fn(); err != nil {
+			return f.rollback(j, prev, err)
+		}
+	}
+	return nil
+}
+
+func (f *KitFs) rollback(j journal, prev []byte, err error) error {
+	if _, e := f.restore(j); e != nil {
+		return fmt.Errorf("%s, restoring the files failed: %s", err, e)
+	}
+	if prev != nil {
+		afero.WriteFile(f.base, JournalPath, prev, os.ModePerm)
+	} else {
+		f.base.Remove(JournalPath)
+	}
+	return fmt.Errorf("%s, the changes were rolled back", err)
+}
+
+// Undo restores the files changed by the last committed run and returns their paths.
+// It refuses to restore files edited after the run unless gk_force is set.
+func (f *KitFs) Undo() ([]string, error) {
+	b, err := afero.ReadFile(f.base, JournalPath)
+	if os.IsNotExist(err) {
+		return nil, errors.New("there is nothing to undo")
+	} else if err != nil {
+		return nil, err
+	}
+	j := journal{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return nil, fmt.Errorf("%s: %s", JournalPath, err)
+	}
+	if !viper.GetBool("gk_force") {
+		for _, fl := range j.Files {
+			if s, err := afero.ReadFile(f.base, fl.Path); err == nil && checksum(string(s)) != fl.Sum {
+				return nil, fmt.Errorf("`%s` was changed after the last run, use --force to restore it anyway", fl.Path)
+			}
+		}
+	}
+	restored, err := f.restore(j)
+	if err != nil {
+		return restored, err
+	}
+	return restored, f.base.Remove(JournalPath)
+}
+
+func (f *KitFs) restore(j journal) (restored []string, err error) {
+	for i := len(j.Files) - 1; i >= 0; i-- {
+		fl := j.Files[i]
+		if fl.Existed {
+			err = afero.WriteFile(f.base, fl.Path, []byte(fl.Content), os.ModePerm)
+		} else if err = f.base.Remove(fl.Path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			return restored, err
+		}
+		if fl.Path != ManifestPath {
+			restored = append(restored, fl.Path)
+		}
+	}
+	// remove the folders created by the run, the deepest first.
+	sort.SliceStable(j.Dirs, func(a, b int) bool {
+		return strings.Count(j.Dirs[a], "/") > strings.Count(j.Dirs[b], "/")
+	})
+	for _, d := range j.Dirs {
+		if b, _ := afero.IsEmpty(f.base, d); b {
+			f.base.Remove(d)
+		}
+	}
+	return restored, nil
+}
+
+func (f *KitFs) writeJournal(j journal) error {
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := f.base.MkdirAll(path.Dir(JournalPath), os.ModePerm); err != nil {
+		return err
+	}
+	return afero.WriteFile(f.base, JournalPath, b, os.ModePerm)
+}
+
+func appendMissing(s []string, v string) []string {
+	for _, e := range s {
+		if e == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
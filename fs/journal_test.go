@@ -0,0 +1,92 @@
+package fs
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+func newStagedFs(base afero.Fs) *KitFs {
+	return &KitFs{base: base, Fs: afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(base), afero.NewMemMapFs())}
+}
+
+func TestKitFs_CommitUndo(t *testing.T) {
+	base := afero.NewMemMapFs()
+	afero.WriteFile(base, "svc/pkg/service/service.go", []byte("old"), 0644)
+	f := newStagedFs(base)
+	Convey("Test if the files are staged until the run is committed", t, func() {
+		So(f.WriteFile("svc/pkg/service/service.go", "new", true), ShouldBeNil)
+		So(f.WriteFile("svc/pkg/endpoint/endpoint.go", "endpoint", true), ShouldBeNil)
+		b, _ := afero.Exists(base, "svc/pkg/endpoint/endpoint.go")
+		So(b, ShouldBeFalse)
+		So(f.Commit(), ShouldBeNil)
+		d, _ := afero.ReadFile(base, "svc/pkg/service/service.go")
+		So(string(d), ShouldEqual, "new")
+		d, _ = afero.ReadFile(base, "svc/pkg/endpoint/endpoint.go")
+		So(string(d), ShouldEqual, "endpoint")
+		b, _ = afero.Exists(base, JournalPath)
+		So(b, ShouldBeTrue)
+		Convey("Test if undo restores the files of the last run", func() {
+			restored, err := newStagedFs(base).Undo()
+			So(err, ShouldBeNil)
+			So(restored, ShouldResemble, []string{"svc/pkg/endpoint/endpoint.go", "svc/pkg/service/service.go"})
+			d, _ := afero.ReadFile(base, "svc/pkg/service/service.go")
+			So(string(d), ShouldEqual, "old")
+			b, _ := afero.Exists(base, "svc/pkg/endpoint")
+			So(b, ShouldBeFalse)
+			_, err = newStagedFs(base).Undo()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestKitFs_UndoEditedFile(t *testing.T) {
+	base := afero.NewMemMapFs()
+	f := newStagedFs(base)
+	f.WriteFile("svc/service.go", "new", true)
+	f.Commit()
+	afero.WriteFile(base, "svc/service.go", []byte("edited"), 0644)
+	Convey("Test if undo refuses to restore files edited after the run", t, func() {
+		_, err := newStagedFs(base).Undo()
+		So(err, ShouldNotBeNil)
+		b, _ := afero.Exists(base, "svc/service.go")
+		So(b, ShouldBeTrue)
+		Convey("Test if force restores them anyway", func() {
+			viper.Set("gk_force", true)
+			defer viper.Set("gk_force", false)
+			_, err := newStagedFs(base).Undo()
+			So(err, ShouldBeNil)
+			b, _ := afero.Exists(base, "svc/service.go")
+			So(b, ShouldBeFalse)
+		})
+	})
+}
+
+func TestKitFs_CommitRollback(t *testing.T) {
+	base := afero.NewMemMapFs()
+	f := newStagedFs(base)
+	f.WriteFile("svc/service.go", "first", true)
+	f.Commit()
+	f = newStagedFs(base)
+	f.WriteFile("svc/service.go", "second", true)
+	f.WriteFile("svc/pb/svc.proto", "proto", true)
+	f.OnCommit(func() error {
+		return errors.New("protoc failed")
+	})
+	Convey("Test if the files are restored when an OnCommit function fails", t, func() {
+		So(f.Commit(), ShouldNotBeNil)
+		d, _ := afero.ReadFile(base, "svc/service.go")
+		So(string(d), ShouldEqual, "first")
+		b, _ := afero.Exists(base, "svc/pb")
+		So(b, ShouldBeFalse)
+		Convey("Test if the journal of the previous run is kept", func() {
+			_, err := newStagedFs(base).Undo()
+			So(err, ShouldBeNil)
+			b, _ := afero.Exists(base, "svc/service.go")
+			So(b, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,151 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// ManifestPath is where the manifest of the generated files is kept, relative to the project folder.
+var ManifestPath = path.Join(".kit", "manifest.json")
+
+// The states of a generated file.
+const (
+	StateGenerated = "generated"
+	StateModified  = "modified"
+	StateDeleted   = "deleted"
+)
+
+type manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file written by kit.
+type ManifestFile struct {
+	Path string `json:"path"`
+	// Generator is the command that wrote the file e.x `kit generate service`.
+	Generator string `json:"generator"`
+	Version   string `json:"version"`
+	// Sum is the checksum of the content kit wrote, used to detect the files edited by hand.
+	Sum string `json:"sum"`
+	// State is `generated`, `modified` or `deleted`, it is not kept in the manifest.
+	State string `json:"-"`
+}
+
+// SetGenerator sets the command and the version of kit recorded in the manifest for the
+// files written during the run.
+func (f *KitFs) SetGenerator(name, version string) {
+	f.generator, f.version = name, version
+}
+
+// Manifest returns the files kit wrote in the project and whether they were modified
+// or deleted after, sorted by path.
+func (f *KitFs) Manifest() ([]ManifestFile, error) {
+	m, err := f.readManifest()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range m.Files {
+		s, err := afero.ReadFile(f.base, v.Path)
+		if os.IsNotExist(err) {
+			m.Files[i].State = StateDeleted
+		} else if err != nil {
+			return nil, err
+		} else if checksum(string(s)) != v.Sum {
+			m.Files[i].State = StateModified
+		} else {
+			m.Files[i].State = StateGenerated
+		}
+	}
+	return m.Files, nil
+}
+
+func (f *KitFs) readManifest() (manifest, error) {
+	m := manifest{}
+	b, err := afero.ReadFile(f.base, ManifestPath)
+	if os.IsNotExist(err) {
+		return m, nil
+	} else if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, fmt.Errorf("%s: %s", ManifestPath, err)
+	}
+	return m, nil
+}
+
+// stageManifest adds the files changed during the run to the manifest, the manifest
+// is staged like the other files so `kit undo` restores it too.
+func (f *KitFs) stageManifest() error {
+	m, err := f.readManifest()
+	if err != nil {
+		return err
+	}
+	files := map[string]ManifestFile{}
+	for _, v := range m.Files {
+		files[v.Path] = v
+	}
+	changed := false
+	for _, c := range f.changes {
+		if c.Status() == "unchanged" || c.Path == ManifestPath || f.isBackup(c.Path) {
+			continue
+		}
+		files[c.Path] = ManifestFile{Path: c.Path, Generator: f.generator, Version: f.version, Sum: checksum(c.New)}
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	m.Files = []ManifestFile{}
+	for _, v := range files {
+		m.Files = append(m.Files, v)
+	}
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return f.write(ManifestPath, string(b)+"\n")
+}
+
+// editedByHand reports whether a file kit regenerates as a whole (the `_gen` files)
+// was changed after kit wrote it. The files that are not in the manifest e.x of
+// projects generated before the manifest existed are never reported.
+func (f *KitFs) editedByHand(pth string) bool {
+	if !strings.HasSuffix(strings.TrimSuffix(path.Base(pth), path.Ext(pth)), "_gen") {
+		return false
+	}
+	for _, c := range f.changes {
+		// the file was already written during this run.
+		if c.Path == pth {
+			return false
+		}
+	}
+	m, err := f.readManifest()
+	if err != nil {
+		return false
+	}
+	for _, v := range m.Files {
+		if v.Path == pth {
+			s, err := f.ReadFile(pth)
+			return err == nil && checksum(s) != v.Sum
+		}
+	}
+	return false
+}
+
+func (f *KitFs) isBackup(pth string) bool {
+	for _, b := range f.backups {
+		if b == pth {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package fs
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+func TestKitFs_Manifest(t *testing.T) {
+	base := afero.NewMemMapFs()
+	f := newStagedFs(base)
+	f.SetGenerator("kit generate service", "v1")
+	f.WriteFile("svc/endpoint.go", "endpoint", true)
+	f.WriteFile("svc/endpoint_gen.go", "endpoint gen", true)
+	f.WriteFile("svc/http.go", "http", true)
+	f.Commit()
+	afero.WriteFile(base, "svc/endpoint.go", []byte("edited"), 0644)
+	base.Remove("svc/http.go")
+	Convey("Test if the manifest keeps the state of the generated files", t, func() {
+		files, err := newStagedFs(base).Manifest()
+		So(err, ShouldBeNil)
+		So(files, ShouldHaveLength, 3)
+		So(files[0], ShouldResemble, ManifestFile{
+			Path:      "svc/endpoint.go",
+			Generator: "kit generate service",
+			Version:   "v1",
+			Sum:       checksum("endpoint"),
+			State:     StateModified,
+		})
+		So(files[1].State, ShouldEqual, StateGenerated)
+		So(files[2].State, ShouldEqual, StateDeleted)
+		Convey("Test if undo restores the manifest", func() {
+			f := newStagedFs(base)
+			f.WriteFile("svc/endpoint_gen.go", "endpoint gen 2", true)
+			So(f.Commit(), ShouldBeNil)
+			files, _ := newStagedFs(base).Manifest()
+			So(files[1].Sum, ShouldEqual, checksum("endpoint gen 2"))
+			restored, err := newStagedFs(base).Undo()
+			So(err, ShouldBeNil)
+			So(restored, ShouldResemble, []string{"svc/endpoint_gen.go"})
+			files, _ = newStagedFs(base).Manifest()
+			So(files[1].Sum, ShouldEqual, checksum("endpoint gen"))
+			So(files[1].State, ShouldEqual, StateGenerated)
+		})
+	})
+}
+
+func TestKitFs_WriteEditedGenFile(t *testing.T) {
+	base := afero.NewMemMapFs()
+	f := newStagedFs(base)
+	f.WriteFile("svc/endpoint_gen.go", "endpoint gen", true)
+	f.WriteFile("svc/service.go", "service", true)
+	f.Commit()
+	afero.WriteFile(base, "svc/endpoint_gen.go", []byte("edited"), 0644)
+	afero.WriteFile(base, "svc/service.go", []byte("edited"), 0644)
+	defer viper.Set("gk_on_conflict", ConflictAsk)
+	Convey("Test if the _gen files edited by hand are not regenerated silently", t, func() {
+		// goconvey runs this block again for every leaf, the fail leaf changes the policy.
+		viper.Set("gk_on_conflict", ConflictSkip)
+		f := newStagedFs(base)
+		So(f.WriteFile("svc/endpoint_gen.go", "endpoint gen 2", true), ShouldBeNil)
+		So(f.WriteFile("svc/service.go", "service 2", true), ShouldBeNil)
+		So(f.Skipped(), ShouldResemble, []string{"svc/endpoint_gen.go"})
+		s, _ := f.ReadFile("svc/service.go")
+		So(s, ShouldEqual, "service 2")
+		Convey("Test if the fail policy refuses to regenerate them", func() {
+			viper.Set("gk_on_conflict", ConflictFail)
+			err := newStagedFs(base).WriteFile("svc/endpoint_gen.go", "endpoint gen 2", true)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "was edited after kit generated it")
+		})
+		Convey("Test if force regenerates them", func() {
+			viper.Set("gk_force", true)
+			defer viper.Set("gk_force", false)
+			f := newStagedFs(base)
+			So(f.WriteFile("svc/endpoint_gen.go", "endpoint gen 2", true), ShouldBeNil)
+			s, _ := f.ReadFile("svc/endpoint_gen.go")
+			So(s, ShouldEqual, "endpoint gen 2")
+		})
+	})
+}
@@ -0,0 +1,215 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// AddMethod implements Gen and is used to add a method to the service interface and
+// to generate its code in every layer of the service.
+type AddMethod struct {
+	BaseGenerator
+	name          string
+	interfaceName string
+	destPath      string
+	filePath      string
+	signature     string
+	doc           string
+}
+
+// NewAddMethod returns a initialized and ready generator.
+//
+// The signature parameter is the method as it is declared in the interface
+// e.x `Foo(ctx context.Context, s string) (string, error)`, doc is its doc comment.
+func NewAddMethod(name, signature, doc string) Gen {
+	i := &AddMethod{
+		name:          name,
+		interfaceName: utils.ToCamelCase(name + "Service"),
+		destPath:      fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+		signature:     strings.TrimSpace(signature),
+		doc:           strings.TrimSpace(doc),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_service_file_name"))
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate adds the method to the interface and generates the implementation stub,
+// the middlewares, the endpoint, the transports and the clients of the method. The
+// signature is validated before any file is changed.
+func (g *AddMethod) Generate() error {
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		return fmt.Errorf("service %s was not found", g.name)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	m, err := g.method()
+	if err != nil {
+		return err
+	}
+	file, err := parseServiceFile(g.fs, src, g.filePath)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, v := range file.Interfaces {
+		if v.Name != g.interfaceName {
+			continue
+		}
+		found = true
+		for _, e := range v.Methods {
+			if e.Name == m.Name {
+				return fmt.Errorf("the method `%s` already exists in the service interface", m.Name)
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("could not find the service interface in `%s`", g.name)
+	}
+	if src, err = g.insertMethod(src); err != nil {
+		return err
+	}
+	// the imports of the packages the signature uses.
+	if src, err = g.MergeSource(g.destPath, src, "", nil); err != nil {
+		return err
+	}
+	if err = g.fs.WriteFile(g.filePath, src, true); err != nil {
+		return err
+	}
+	return g.generateMethod(m.Name)
+}
+
+// method parses the signature and checks that kit can generate the code of the method.
+func (g *AddMethod) method() (m parser.Method, err error) {
+	if _, err = methodName(g.signature); err != nil {
+		return m, err
+	}
+	f, err := parser.NewFileParser().Parse([]byte("package service\n\ntype s interface {\n" + g.signature + "\n}\n"))
+	if err != nil {
+		return m, err
+	}
+	m = f.Interfaces[0].Methods[0]
+	if reason := unsupportedMethod(m); reason != "" {
+		return m, fmt.Errorf("the method `%s` %s, kit can not generate its code", m.Name, reason)
+	}
+	return m, nil
+}
+
+// insertMethod adds the method at the end of the service interface, the rest of
+// the source is not changed.
+func (g *AddMethod) insertMethod(src string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "", src, goparser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	lines := []string{}
+	for _, v := range strings.Split(g.doc, "\n") {
+		if v = strings.TrimSpace(v); v != "" {
+			lines = append(lines, "\t// "+v)
+		}
+	}
+	lines = append(lines, "\t"+g.signature)
+	for _, d := range f.Decls {
+		d, ok := d.(*ast.GenDecl)
+		if !ok || d.Tok != token.TYPE {
+			continue
+		}
+		for _, s := range d.Specs {
+			s := s.(*ast.TypeSpec)
+			it, ok := s.Type.(*ast.InterfaceType)
+			if !ok || s.Name.Name != g.interfaceName {
+				continue
+			}
+			lbrace := fset.Position(it.Methods.Opening)
+			rbrace := fset.Position(it.Methods.Closing)
+			if lbrace.Line == rbrace.Line {
+				// e.x `interface{}`
+				return src[:lbrace.Offset+1] + "\n" + strings.Join(lines, "\n") + "\n" + src[rbrace.Offset:], nil
+			}
+			at := strings.LastIndex(src[:rbrace.Offset], "\n") + 1
+			return src[:at] + strings.Join(lines, "\n") + "\n" + src[at:], nil
+		}
+	}
+	return "", errors.New("could not find the service interface in `" + g.name + "`")
+}
+
+// generateMethod generates the code of the method for the transports, the
+// middlewares and the clients the service already has.
+func (g *AddMethod) generateMethod(method string) error {
+	m, err := inspectService(g.name)
+	if err != nil {
+		return err
+	}
+	if len(m.Transports) == 0 {
+		logrus.Infof("The service %s has no transport, run `kit g s %s` to generate it", g.name, g.name)
+		return nil
+	}
+	// the default middlewares are kept only if the service was generated with them.
+	smw := hasMiddleware(m.Middlewares.Service, "LoggingMiddleware")
+	emw := hasMiddleware(m.Middlewares.Endpoint, "LoggingMiddleware")
+	gorilla, err := g.usesGorilla()
+	if err != nil {
+		return err
+	}
+	for _, t := range m.Transports {
+		if err := NewGenerateService(g.name, t, smw, gorilla, emw, []string{method}).Generate(); err != nil {
+			return err
+		}
+	}
+	for _, v := range m.Middlewares.Service {
+		if v.Name == "LoggingMiddleware" {
+			continue
+		}
+		mdw := NewGenerateMiddleware(utils.ToLowerFirstCamelCase(strings.TrimSuffix(v.Name, "Middleware")), g.name, false)
+		if err := mdw.Generate(); err != nil {
+			return err
+		}
+	}
+	for _, t := range m.Clients {
+		if err := NewGenerateClient(g.name, t, []string{method}).Generate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usesGorilla reports whether the http transport of the service uses the gorilla mux.
+func (g *AddMethod) usesGorilla() (bool, error) {
+	pth := path.Join(
+		fmt.Sprintf(viper.GetString("gk_http_path_format"), utils.ToLowerSnakeCase(g.name)),
+		viper.GetString("gk_http_file_name"),
+	)
+	if b, err := g.fs.Exists(pth); err != nil || !b {
+		return false, err
+	}
+	src, err := g.fs.ReadFile(pth)
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(src, `"github.com/gorilla/mux"`), nil
+}
+
+func hasMiddleware(ms []MiddlewareModel, name string) bool {
+	for _, m := range ms {
+		if m.Name == name {
+			return true
+		}
+	}
+	return false
+}
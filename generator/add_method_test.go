@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddMethod_Generate(t *testing.T) {
+	setDefaults()
+	svc := `package service
+
+import "context"
+
+// AddSvcService describes the service.
+type AddSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`
+	fs.Get().WriteFile("add_svc/pkg/service/service.go", svc, true)
+	err := NewGenerateService("add_svc", "http", true, false, true, nil).Generate()
+	Convey("Test if the method is added to the interface and generated in every layer", t, func() {
+		So(err, ShouldBeNil)
+		So(NewGenerateClient("add_svc", "http", nil).Generate(), ShouldBeNil)
+		client, _ := fs.Get().ReadFile("add_svc/client/http/http.go")
+		client = strings.Replace(client, "\treturn endpoint1.Endpoints{", "\t// edited by hand.\n\treturn endpoint1.Endpoints{", 1)
+		fs.Get().WriteFile("add_svc/client/http/http.go", client, true)
+		// a method of the interface that has no endpoint yet is not generated.
+		s, _ := fs.Get().ReadFile("add_svc/pkg/service/service.go")
+		s = strings.Replace(s, "\tFoo(ctx context.Context, s string) (r string, err error)\n", "\tFoo(ctx context.Context, s string) (r string, err error)\n\tBar(ctx context.Context) (err error)\n", 1)
+		fs.Get().WriteFile("add_svc/pkg/service/service.go", s, true)
+		g := NewAddMethod("add_svc", "Wait(ctx context.Context, d time.Duration) (err error)", "Wait waits.\n@http POST /wait")
+		So(g.Generate(), ShouldBeNil)
+		src, err := fs.Get().ReadFile("add_svc/pkg/service/service.go")
+		So(err, ShouldBeNil)
+		So(src, ShouldContainSubstring, "\t// Wait waits.\n\t// @http POST /wait\n\tWait(ctx context.Context, d time.Duration) (err error)\n}")
+		So(src, ShouldContainSubstring, `"time"`)
+		So(src, ShouldContainSubstring, "func (b *basicAddSvcService) Wait(")
+		for pth, code := range map[string]string{
+			"add_svc/pkg/service/middleware.go": "func (l loggingMiddleware) Wait(",
+			"add_svc/pkg/endpoint/endpoint.go":  "func MakeWaitEndpoint(",
+			"add_svc/pkg/http/handler.go":       "func makeWaitHandler(",
+		} {
+			src, err := fs.Get().ReadFile(pth)
+			So(err, ShouldBeNil)
+			So(src, ShouldContainSubstring, code)
+		}
+		src, _ = fs.Get().ReadFile("add_svc/pkg/endpoint/endpoint.go")
+		So(src, ShouldNotContainSubstring, "MakeBarEndpoint")
+		src, _ = fs.Get().ReadFile("add_svc/client/http/http.go")
+		So(src, ShouldContainSubstring, "// edited by hand.\n")
+		So(src, ShouldContainSubstring, "\tvar waitEndpoint endpoint.Endpoint\n")
+		So(src, ShouldContainSubstring, "\t\tFooEndpoint:  fooEndpoint,\n\t\tWaitEndpoint: waitEndpoint,\n")
+		So(src, ShouldContainSubstring, "func decodeWaitResponse(")
+		So(src, ShouldNotContainSubstring, "barEndpoint")
+		fset := token.NewFileSet()
+		f, err := goparser.ParseFile(fset, "http.go", src, 0)
+		So(err, ShouldBeNil)
+		So(undeclaredNames(fset, []*ast.File{f}), ShouldBeEmpty)
+	})
+	Convey("Test if the unsupported methods are refused before any file is changed", t, func() {
+		before, _ := fs.Get().ReadFile("add_svc/pkg/service/service.go")
+		for _, sig := range []string{
+			"wait(ctx context.Context) error",
+			"Nope(s string) error",
+			"Nope(ctx context.Context)",
+			"Foo(ctx context.Context) error",
+			"Bad(ctx context.Context",
+		} {
+			So(NewAddMethod("add_svc", sig, "").Generate(), ShouldNotBeNil)
+		}
+		after, _ := fs.Get().ReadFile("add_svc/pkg/service/service.go")
+		So(after, ShouldEqual, before)
+	})
+	Convey("Test if a missing service is reported", t, func() {
+		So(NewAddMethod("add_missing", "Foo(ctx context.Context) error", "").Generate(), ShouldNotBeNil)
+	})
+}
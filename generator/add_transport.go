@@ -15,6 +15,10 @@ import (
 
 	"errors"
 
+	"sort"
+
+	"strconv"
+
 	"github.com/dave/jennifer/jen"
 	"github.com/emicklei/proto"
 	"github.com/emicklei/proto-contrib/pkg/protofmt"
@@ -76,7 +80,10 @@ func (g *GenerateTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	g.file, err = parser.NewFileParser().Parse([]byte(svcSrc))
+	g.file, err = parseServiceFile(g.fs, svcSrc, g.filePath)
+	if err != nil {
+		return err
+	}
 	if !g.serviceFound() {
 		return errors.New(fmt.Sprintf("could not find the service interface in `%s`", g.name))
 	}
@@ -99,12 +106,12 @@ func (g *GenerateTransport) Generate() (err error) {
 			return err
 		}
 	case "grpc":
-		gp := newGenerateGRPCTransportProto(g.name, g.serviceInterface, g.methods)
+		gp := newGenerateGRPCTransportProto(g.name, g.serviceInterface, g.methods, g.file.Structures)
 		err = gp.Generate()
 		if err != nil {
 			return err
 		}
-		gt := newGenerateGRPCTransport(g.name, g.serviceInterface, g.methods)
+		gt := newGenerateGRPCTransport(g.name, g.serviceInterface, g.methods, g.file.Structures)
 		err = gt.Generate()
 		if err != nil {
 			return err
@@ -114,12 +121,55 @@ func (g *GenerateTransport) Generate() (err error) {
 		if err != nil {
 			return err
 		}
-		logrus.Warn("===============================================================")
-		logrus.Warn("The GRPC implementation is not finished you need to update your")
-		logrus.Warn(" service proto buffer and run the compile script.")
-		logrus.Warn("---------------------------------------------------------------")
-		logrus.Warn("You also need to implement the Encoders and Decoders!")
-		logrus.Warn("===============================================================")
+	case "thrift":
+		tt := newGenerateThriftTransport(g.name, g.serviceInterface)
+		err = tt.Generate()
+		if err != nil {
+			return err
+		}
+		tb := newGenerateThriftTransportBase(g.name, g.serviceInterface)
+		err = tb.Generate()
+		if err != nil {
+			return err
+		}
+		ti := newGenerateThriftTransportIDL(g.name, g.serviceInterface, mth)
+		err = ti.Generate()
+		if err != nil {
+			return err
+		}
+	case "nats":
+		nt := newGenerateNATSTransport(g.name, g.serviceInterface)
+		err = nt.Generate()
+		if err != nil {
+			return err
+		}
+		nb := newGenerateNATSTransportBase(g.name, g.serviceInterface, mth)
+		err = nb.Generate()
+		if err != nil {
+			return err
+		}
+	case "amqp":
+		at := newGenerateAMQPTransport(g.name, g.serviceInterface)
+		err = at.Generate()
+		if err != nil {
+			return err
+		}
+		ab := newGenerateAMQPTransportBase(g.name, g.serviceInterface, mth)
+		err = ab.Generate()
+		if err != nil {
+			return err
+		}
+	case "jsonrpc":
+		jt := newGenerateJSONRPCTransport(g.name, g.serviceInterface)
+		err = jt.Generate()
+		if err != nil {
+			return err
+		}
+		jb := newGenerateJSONRPCTransportBase(g.name, g.serviceInterface, mth)
+		err = jb.Generate()
+		if err != nil {
+			return err
+		}
 	default:
 		return errors.New("this transport type is not yet implemented")
 	}
@@ -180,6 +230,284 @@ func (g *GenerateTransport) removeUnwantedMethods() {
 	g.serviceInterface.Methods = keepMethods
 }
 
+// httpMethods are the http verbs that can be used in the `@http` annotation.
+var httpMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"}
+
+// httpStatusNames are the names of the net/http constants of the common success codes.
+var httpStatusNames = map[int]string{
+	200: "StatusOK",
+	201: "StatusCreated",
+	202: "StatusAccepted",
+	204: "StatusNoContent",
+}
+
+// httpRoute is the REST route of a service method, the route is set with annotations in
+// the doc comment of the method e.x:
+//
+//	// @http GET /users/{id}
+//	// @http-query limit
+//	// @http-header token X-Auth-Token
+//	// @http-status 200
+//	GetUser(ctx context.Context, id int, limit int, token string) (User, error)
+//
+// The parameters in the path template are read from the path, the parameters that are
+// not bound are read from the JSON body or from the query if the verb has no body.
+// Methods without annotations are served as `POST /<method-name>` with a JSON body.
+type httpRoute struct {
+	Method string
+	Path   string
+	Status int
+	// Params are the parameters that are not read from the JSON body.
+	Params []httpParam
+	// Body is true if some parameters are read from the JSON body.
+	Body bool
+	// Annotated is true if the route was set with annotations.
+	Annotated bool
+}
+
+// httpParam is a method parameter that is read from the path, the query or the headers.
+type httpParam struct {
+	// Name is the name of the field in the endpoint request struct.
+	Name string
+	Type string
+	// In is where the value is read from, `path`, `query` or `header`.
+	In string
+	// Key is the name of the path variable, the query key or the header.
+	Key string
+}
+
+// httpPath returns the path of the method when it has no @http annotation.
+func httpPath(method string) string {
+	return "/" + strings.Replace(utils.ToLowerSnakeCase(method), "_", "-", -1)
+}
+
+func newHTTPRoute(m parser.Method) (r httpRoute, err error) {
+	r = httpRoute{
+		Method: "POST",
+		Path:   httpPath(m.Name),
+		Status: 200,
+	}
+	bound := map[string]httpParam{}
+	for _, line := range strings.Split(m.Comment, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "@http") {
+			continue
+		}
+		switch {
+		case fields[0] == "@http" && len(fields) == 3:
+			r.Method, r.Path, r.Annotated = strings.ToUpper(fields[1]), fields[2], true
+			if !httpMethod(r.Method) {
+				return r, fmt.Errorf("the http method `%s` of %s is not supported", fields[1], m.Name)
+			}
+			if !strings.HasPrefix(r.Path, "/") {
+				return r, fmt.Errorf("the path `%s` of %s must start with `/`", r.Path, m.Name)
+			}
+		case fields[0] == "@http-status" && len(fields) == 2:
+			if r.Status, err = strconv.Atoi(fields[1]); err != nil || r.Status < 200 || r.Status > 299 {
+				return r, fmt.Errorf("the status `%s` of %s is not a success status code", fields[1], m.Name)
+			}
+		case fields[0] == "@http-query" && (len(fields) == 2 || len(fields) == 3):
+			key := utils.ToJSONName(fields[1])
+			if len(fields) == 3 {
+				key = fields[2]
+			}
+			bound[fields[1]] = httpParam{In: "query", Key: key}
+		case fields[0] == "@http-header" && (len(fields) == 2 || len(fields) == 3):
+			key := strings.Replace(utils.ToCamelCase(fields[1]), "_", "-", -1)
+			if len(fields) == 3 {
+				key = fields[2]
+			}
+			bound[fields[1]] = httpParam{In: "header", Key: key}
+		default:
+			return r, fmt.Errorf("the annotation `%s` of %s is not valid", strings.TrimSpace(line), m.Name)
+		}
+	}
+	for _, v := range httpPathVars(r.Path) {
+		bound[v] = httpParam{In: "path", Key: v}
+	}
+	bodyAllowed := r.Method == "POST" || r.Method == "PUT" || r.Method == "PATCH"
+	for _, p := range m.Parameters {
+		if p.Type == "context.Context" {
+			continue
+		}
+		prm, ok := bound[p.Name]
+		if !ok {
+			// The annotations can use the snake case name of the parameter e.x {user_id}.
+			prm, ok = bound[utils.ToLowerSnakeCase(p.Name)]
+		}
+		delete(bound, p.Name)
+		delete(bound, utils.ToLowerSnakeCase(p.Name))
+		if !ok && (bodyAllowed || !r.Annotated) {
+			r.Body = true
+			continue
+		}
+		if !ok {
+			prm = httpParam{In: "query", Key: utils.ToJSONName(p.Name)}
+		}
+		tp := p.Type
+		if prm.In == "query" {
+			// the slices are read from the repeated keys e.x `?ids=1&ids=2`.
+			tp = strings.TrimPrefix(tp, "[]")
+		}
+		if _, ok := httpParamParsers[tp]; !ok && tp != "string" {
+			return r, fmt.Errorf(
+				"the parameter `%s` of %s has type %s and can not be read from the %s",
+				p.Name, m.Name, p.Type, prm.In,
+			)
+		}
+		prm.Name, prm.Type = utils.ToCamelCase(p.Name), p.Type
+		r.Params = append(r.Params, prm)
+	}
+	if len(bound) > 0 {
+		keys := []string{}
+		for k := range bound {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return r, fmt.Errorf(
+			"the %s parameter `%s` of %s is not a parameter of the method", bound[keys[0]].In, keys[0], m.Name,
+		)
+	}
+	return r, nil
+}
+
+func httpMethod(method string) bool {
+	for _, v := range httpMethods {
+		if v == method {
+			return true
+		}
+	}
+	return false
+}
+
+// httpPathVars returns the variables of the path template e.x `/users/{id}` returns `id`.
+func httpPathVars(path string) []string {
+	vars := []string{}
+	for _, s := range strings.Split(path, "/") {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			// Gorilla mux variables can have a pattern e.x {id:[0-9]+}.
+			vars = append(vars, strings.Split(s[1:len(s)-1], ":")[0])
+		}
+	}
+	return vars
+}
+
+// httpParamParsers are the strconv functions and their arguments used to parse the
+// parameters that are read from the path, the query or the headers.
+var httpParamParsers = map[string]struct {
+	Func string
+	Args []jen.Code
+}{
+	"bool":    {"ParseBool", nil},
+	"int":     {"ParseInt", []jen.Code{jen.Lit(10), jen.Lit(0)}},
+	"int8":    {"ParseInt", []jen.Code{jen.Lit(10), jen.Lit(8)}},
+	"int16":   {"ParseInt", []jen.Code{jen.Lit(10), jen.Lit(16)}},
+	"int32":   {"ParseInt", []jen.Code{jen.Lit(10), jen.Lit(32)}},
+	"rune":    {"ParseInt", []jen.Code{jen.Lit(10), jen.Lit(32)}},
+	"int64":   {"ParseInt", []jen.Code{jen.Lit(10), jen.Lit(64)}},
+	"uint":    {"ParseUint", []jen.Code{jen.Lit(10), jen.Lit(0)}},
+	"uint8":   {"ParseUint", []jen.Code{jen.Lit(10), jen.Lit(8)}},
+	"byte":    {"ParseUint", []jen.Code{jen.Lit(10), jen.Lit(8)}},
+	"uint16":  {"ParseUint", []jen.Code{jen.Lit(10), jen.Lit(16)}},
+	"uint32":  {"ParseUint", []jen.Code{jen.Lit(10), jen.Lit(32)}},
+	"uint64":  {"ParseUint", []jen.Code{jen.Lit(10), jen.Lit(64)}},
+	"float32": {"ParseFloat", []jen.Code{jen.Lit(32)}},
+	"float64": {"ParseFloat", []jen.Code{jen.Lit(64)}},
+}
+
+// readParam returns the statements that read the parameter from `v` to the field of `dst`,
+// `v` is a string or for the slices the values of a query key.
+func (p httpParam) readParam(dst string, v jen.Code) jen.Code {
+	field := jen.Id(dst).Dot(p.Name)
+	if p.Type == "string" || p.Type == "[]string" {
+		return field.Op("=").Add(v)
+	}
+	if strings.HasPrefix(p.Type, "[]") {
+		return jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Add(v)).Block(
+			parseParam(p.Type[2:], func(x jen.Code) jen.Code {
+				return jen.Id(dst).Dot(p.Name).Op("=").Append(jen.Id(dst).Dot(p.Name), x)
+			})...,
+		)
+	}
+	return jen.If(jen.Id("v").Op(":=").Add(v), jen.Id("v").Op("!=").Lit("")).Block(
+		parseParam(p.Type, func(x jen.Code) jen.Code {
+			return field.Op("=").Add(x)
+		})...,
+	)
+}
+
+// parseParam returns the statements that parse the string `v` to the type `tp` and
+// pass the value to `set`.
+func parseParam(tp string, set func(x jen.Code) jen.Code) []jen.Code {
+	prs := httpParamParsers[tp]
+	x := jen.Id("x")
+	if tp != "bool" && tp != "int64" && tp != "uint64" && tp != "float64" {
+		x = jen.Id(tp).Call(jen.Id("x"))
+	}
+	return []jen.Code{
+		jen.List(jen.Id("x"), jen.Err()).Op(":=").Qual("strconv", prs.Func).Call(
+			append([]jen.Code{jen.Id("v")}, prs.Args...)...,
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		set(x),
+	}
+}
+
+// writeParam returns the value of the parameter of `src` as a string.
+func (p httpParam) writeParam(src string) jen.Code {
+	if p.Type == "string" {
+		return jen.Id(src).Dot(p.Name)
+	}
+	return jen.Qual("fmt", "Sprint").Call(jen.Id(src).Dot(p.Name))
+}
+
+// pathValue returns the escaped path of the route with the variables set to the parameters of `src`.
+func (r httpRoute) pathValue(src string) jen.Code {
+	params := map[string]httpParam{}
+	for _, p := range r.Params {
+		if p.In == "path" {
+			params[p.Key] = p
+		}
+	}
+	var code *jen.Statement
+	lit := ""
+	add := func(c jen.Code) {
+		if code == nil {
+			code = jen.Add(c)
+			return
+		}
+		code = code.Op("+").Add(c)
+	}
+	for i, s := range strings.Split(r.Path, "/") {
+		if i > 0 {
+			lit += "/"
+		}
+		if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+			lit += s
+			continue
+		}
+		if lit != "" {
+			add(jen.Lit(lit))
+			lit = ""
+		}
+		add(jen.Qual("net/url", "PathEscape").Call(params[strings.Split(s[1:len(s)-1], ":")[0]].writeParam(src)))
+	}
+	if lit != "" {
+		add(jen.Lit(lit))
+	}
+	return code
+}
+
+// statusCode returns the net/http constant of the status code if there is one.
+func (r httpRoute) statusCode() jen.Code {
+	if n, ok := httpStatusNames[r.Status]; ok {
+		return jen.Qual("net/http", n)
+	}
+	return jen.Lit(r.Status)
+}
+
 type generateHTTPTransport struct {
 	BaseGenerator
 	name                          string
@@ -270,21 +598,33 @@ func (g *generateHTTPTransport) Generate() (err error) {
 				handlerFound = true
 			}
 		}
+		route, err := newHTTPRoute(m)
+		if err != nil {
+			return err
+		}
+		if !g.gorillaMux && len(httpPathVars(route.Path)) > 0 {
+			return fmt.Errorf("the path of %s has variables, use --gorilla to generate the http transport", m.Name)
+		}
+		// the ServeMux does not match the methods, the handlers accept POST like the routes
+		// that are not annotated.
+		if !g.gorillaMux && route.Method != "POST" {
+			return fmt.Errorf("%s is served with %s, use --gorilla to generate the http transport", m.Name, route.Method)
+		}
 		if !handlerFound {
-			g.code.appendMultilineComment([]string{
+			g.code.appendMultilineComment(withMethodDoc([]string{
 				fmt.Sprintf("make%sHandler creates the handler logic", m.Name),
-			})
+			}, m))
 			g.code.NewLine()
 			var st *jen.Statement
 			if g.gorillaMux {
 				st = jen.Id("m").Dot("Methods").Call(
-					jen.Lit("POST"),
+					jen.Lit(route.Method),
 				).Dot("Path").Call(
-					jen.Lit("/" + strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1)),
+					jen.Lit(route.Path),
 				).Dot("Handler").Call(
 					jen.Qual("github.com/gorilla/handlers", "CORS").Call(
 						jen.Qual("github.com/gorilla/handlers", "AllowedMethods").Call(
-							jen.Index().String().Values(jen.Lit("POST")),
+							jen.Index().String().Values(jen.Lit(route.Method)),
 						),
 						jen.Qual("github.com/gorilla/handlers", "AllowedOrigins").Call(
 							jen.Index().String().Values(jen.Lit("*")),
@@ -300,7 +640,7 @@ func (g *generateHTTPTransport) Generate() (err error) {
 				)
 			} else {
 				st = jen.Id("m").Dot("Handle").Call(
-					jen.Lit("/"+strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1)),
+					jen.Lit(route.Path),
 					jen.Qual("github.com/go-kit/kit/transport/http", "NewServer").Call(
 						jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
 						jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
@@ -334,7 +674,9 @@ func (g *generateHTTPTransport) Generate() (err error) {
 
 		}
 
-		if !decoderFound {
+		if !decoderFound && route.Annotated {
+			g.generateRouteDecoder(m, route, endpImports)
+		} else if !decoderFound {
 			g.code.appendMultilineComment([]string{
 				fmt.Sprintf("decode%sRequest is a transport/http.DecodeRequestFunc that decodes a", m.Name),
 				"JSON-encoded request from the HTTP request body.",
@@ -387,15 +729,31 @@ func (g *generateHTTPTransport) Generate() (err error) {
 					),
 				)
 			}
-			pt = append(
-				pt,
-				jen.Id("w").Dot("Header").Call().Dot("Set").Call(
-					jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8")),
-				jen.Err().Op("=").Qual("encoding/json", "NewEncoder").Call(
-					jen.Id("w"),
-				).Dot("Encode").Call(jen.Id("response")),
-				jen.Return(),
-			)
+			switch route.Status {
+			case 200:
+				pt = append(
+					pt,
+					jen.Id("w").Dot("Header").Call().Dot("Set").Call(
+						jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8")),
+					jen.Err().Op("=").Qual("encoding/json", "NewEncoder").Call(
+						jen.Id("w"),
+					).Dot("Encode").Call(jen.Id("response")),
+				)
+			case 204:
+				// The response can not have a body.
+				pt = append(pt, jen.Id("w").Dot("WriteHeader").Call(route.statusCode()))
+			default:
+				pt = append(
+					pt,
+					jen.Id("w").Dot("Header").Call().Dot("Set").Call(
+						jen.Lit("Content-Type"), jen.Lit("application/json; charset=utf-8")),
+					jen.Id("w").Dot("WriteHeader").Call(route.statusCode()),
+					jen.Err().Op("=").Qual("encoding/json", "NewEncoder").Call(
+						jen.Id("w"),
+					).Dot("Encode").Call(jen.Id("response")),
+				)
+			}
+			pt = append(pt, jen.Return())
 			g.code.appendFunction(
 				fmt.Sprintf("encode%sResponse", m.Name),
 				nil,
@@ -493,7 +851,6 @@ func (g *generateHTTPTransport) Generate() (err error) {
 		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
 	}
 	tmpSrc := g.srcFile.GoString()
-	src += "\n" + g.code.Raw().GoString()
 	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
 	if err != nil {
 		return err
@@ -503,19 +860,85 @@ func (g *generateHTTPTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	if len(imp) > 0 {
-		src, err = g.AddImportsToFile(imp, src)
-		if err != nil {
-			return err
-		}
-	}
-	s, err := utils.GoImportsSource(g.destPath, src)
+	s, err := g.MergeSource(g.destPath, src, g.code.Raw().GoString(), imp)
 	if err != nil {
 		return err
 	}
 	return g.fs.WriteFile(g.filePath, s, true)
 }
 
+// generateRouteDecoder generates the decoder of a method with an annotated route, the
+// decoder reads the parameters from the JSON body, the path, the query and the headers.
+func (g *generateHTTPTransport) generateRouteDecoder(m parser.Method, route httpRoute, endpImports string) {
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("decode%sRequest is a transport/http.DecodeRequestFunc that decodes a", m.Name),
+		fmt.Sprintf("%s %s request.", route.Method, route.Path),
+	})
+	g.code.NewLine()
+	body := []jen.Code{
+		jen.Id("req").Op(":=").Qual(endpImports, m.Name+"Request").Block(),
+	}
+	if route.Body {
+		body = append(
+			body,
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(
+					jen.Id("r").Dot("Body"),
+				).Dot("Decode").Call(jen.Id("&req")),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+		)
+	}
+	vars, query := false, false
+	for _, p := range route.Params {
+		switch p.In {
+		case "path":
+			vars = true
+		case "query":
+			query = true
+		}
+	}
+	if vars {
+		body = append(body, jen.Id("vars").Op(":=").Qual("github.com/gorilla/mux", "Vars").Call(jen.Id("r")))
+	}
+	if query {
+		body = append(body, jen.Id("q").Op(":=").Id("r").Dot("URL").Dot("Query").Call())
+	}
+	for _, p := range route.Params {
+		var v jen.Code
+		switch p.In {
+		case "path":
+			v = jen.Id("vars").Index(jen.Lit(p.Key))
+		case "query":
+			v = jen.Id("q").Dot("Get").Call(jen.Lit(p.Key))
+			if strings.HasPrefix(p.Type, "[]") {
+				v = jen.Id("q").Index(jen.Lit(p.Key))
+			}
+		default:
+			v = jen.Id("r").Dot("Header").Dot("Get").Call(jen.Lit(p.Key))
+		}
+		body = append(body, p.readParam("req", v))
+	}
+	body = append(body, jen.Return(jen.Id("req"), jen.Nil()))
+	g.code.appendFunction(
+		fmt.Sprintf("decode%sRequest", m.Name),
+		nil,
+		[]jen.Code{
+			jen.Id("_").Qual("context", "Context"),
+			jen.Id("r").Id("*").Qual("net/http", "Request"),
+		},
+		[]jen.Code{
+			jen.Interface(),
+			jen.Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+}
+
 type generateHTTPTransportBase struct {
 	BaseGenerator
 	name             string
@@ -641,15 +1064,18 @@ type generateGRPCTransportProto struct {
 	pbFilePath        string
 	compileFilePath   string
 	serviceInterface  parser.Interface
+	structs           []parser.Struct
+	structMessages    map[string]bool
 }
 
-func newGenerateGRPCTransportProto(name string, serviceInterface parser.Interface, methods []string) Gen {
+func newGenerateGRPCTransportProto(name string, serviceInterface parser.Interface, methods []string, structs []parser.Struct) Gen {
 	t := &generateGRPCTransportProto{
 		name:             name,
 		methods:          methods,
 		interfaceName:    utils.ToCamelCase(name + "Service"),
 		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_pb_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceInterface: serviceInterface,
+		structs:          structs,
 	}
 	t.pbFilePath = path.Join(
 		t.destPath,
@@ -659,6 +1085,30 @@ func newGenerateGRPCTransportProto(name string, serviceInterface parser.Interfac
 	t.fs = fs.Get()
 	return t
 }
+
+// protoScalarTypes maps the go types to the proto3 scalar types.
+var protoScalarTypes = map[string]string{
+	"string":  "string",
+	"bool":    "bool",
+	"int8":    "int32",
+	"int16":   "int32",
+	"int32":   "int32",
+	"rune":    "int32",
+	"int":     "int64",
+	"int64":   "int64",
+	"uint8":   "uint32",
+	"byte":    "uint32",
+	"uint16":  "uint32",
+	"uint32":  "uint32",
+	"uint":    "uint64",
+	"uint64":  "uint64",
+	"float32": "float",
+	"float64": "double",
+	"[]byte":  "bytes",
+	// the durations are sent as nanoseconds.
+	"time.Duration": "int64",
+}
+
 func (g *generateGRPCTransportProto) Generate() (err error) {
 	g.CreateFolderStructure(g.destPath)
 	if b, err := g.fs.Exists(g.pbFilePath); err != nil {
@@ -687,6 +1137,9 @@ func (g *generateGRPCTransportProto) Generate() (err error) {
 		},
 		Name: utils.ToCamelCase(g.name),
 	}
+	if doc := strings.TrimSpace(g.serviceInterface.Comment); doc != "" {
+		svc.Comment.Lines = protoCommentLines(strings.Split(doc, "\n"))
+	}
 	if g.generateFirstTime {
 		g.getServiceRPC(svc)
 		g.protoSrc.Elements = append(
@@ -718,13 +1171,19 @@ func (g *generateGRPCTransportProto) Generate() (err error) {
 	if viper.GetString("gk_folder") != "" {
 		g.pbFilePath = path.Join(viper.GetString("gk_folder"), g.pbFilePath)
 	}
-	if !viper.GetBool("gk_testing") {
-		cmd := exec.Command("protoc", g.pbFilePath, "--go_out=plugins=grpc:.")
-		cmd.Stdout = os.Stdout
-		err = cmd.Run()
-		if err != nil {
-			return err
-		}
+	if viper.GetBool("gk_dry_run") {
+		logrus.Infof("Dry run, skipping `protoc %s --go_out=plugins=grpc:.`", g.pbFilePath)
+	} else if !viper.GetBool("gk_testing") {
+		// protoc reads the proto file from the disk so it runs after the files are committed.
+		pbFilePath := g.pbFilePath
+		g.fs.OnCommit(func() error {
+			cmd := exec.Command("protoc", pbFilePath, "--go_out=plugins=grpc:.")
+			cmd.Stdout = os.Stdout
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("protoc failed: %s", err)
+			}
+			return nil
+		})
 	}
 	if b, e := g.fs.Exists(g.compileFilePath); e != nil {
 		return e
@@ -804,163 +1263,2661 @@ func (g *generateGRPCTransportProto) getService() *proto.Service {
 	return nil
 }
 func (g *generateGRPCTransportProto) generateRequestResponse() {
+	g.structMessages = map[string]bool{}
 	for _, v := range g.serviceInterface.Methods {
-		foundRequest := false
-		foundReply := false
-		for _, e := range g.protoSrc.Elements {
-			if r, ok := e.(*proto.Message); ok {
-				if r.Name == v.Name+"Request" {
-					foundRequest = true
-				}
-				if r.Name == v.Name+"Reply" {
-					foundReply = true
-				}
-			}
+		g.addMessageFields(g.getMessage(v.Name+"Request"), v.Parameters)
+		g.addMessageFields(g.getMessage(v.Name+"Reply"), v.Results)
+	}
+}
+
+// getMessage returns the top level message with the given name, the message
+// is added to the proto if it does not exist.
+func (g *generateGRPCTransportProto) getMessage(name string) *proto.Message {
+	for _, e := range g.protoSrc.Elements {
+		if r, ok := e.(*proto.Message); ok && r.Name == name {
+			return r
 		}
-		if !foundRequest {
-			g.protoSrc.Elements = append(g.protoSrc.Elements, &proto.Message{
-				Name: v.Name + "Request",
-			})
+	}
+	m := &proto.Message{
+		Name: name,
+	}
+	g.protoSrc.Elements = append(g.protoSrc.Elements, m)
+	return m
+}
+
+// addMessageFields adds the fields that are missing from the message.
+// Fields that already exist keep their number and new fields are numbered
+// after the highest one so regenerating the proto does not break clients.
+func (g *generateGRPCTransportProto) addMessageFields(m *proto.Message, list []parser.NamedTypeValue) {
+	existing := map[string]bool{}
+	seq := 0
+	for _, e := range m.Elements {
+		var f *proto.Field
+		switch r := e.(type) {
+		case *proto.NormalField:
+			f = r.Field
+		case *proto.MapField:
+			f = r.Field
+		default:
+			continue
 		}
-		if !foundReply {
-			g.protoSrc.Elements = append(g.protoSrc.Elements, &proto.Message{
-				Name: v.Name + "Reply",
-			})
+		existing[f.Name] = true
+		if f.Sequence > seq {
+			seq = f.Sequence
 		}
 	}
-}
-func (g *generateGRPCTransportProto) getServiceRPC(svc *proto.Service) {
-	for _, v := range g.serviceInterface.Methods {
-		found := false
-		for _, e := range svc.Elements {
-			if r, ok := e.(*proto.RPC); ok {
-				if r.Name == v.Name {
-					found = true
-				}
-			}
+	for _, p := range list {
+		if p.Type == "context.Context" {
+			continue
 		}
-		if found {
+		name := utils.ToLowerSnakeCase(p.Name)
+		if existing[name] {
 			continue
 		}
-		svc.Elements = append(svc.Elements,
-			&proto.RPC{
-				Name:        v.Name,
-				ReturnsType: v.Name + "Reply",
-				RequestType: v.Name + "Request",
-			},
-		)
+		existing[name] = true
+		seq++
+		tp, key, repeated := g.fieldType(p.Type)
+		f := &proto.Field{
+			Name:     name,
+			Type:     tp,
+			Sequence: seq,
+		}
+		if key != "" {
+			m.Elements = append(m.Elements, &proto.MapField{Field: f, KeyType: key})
+			continue
+		}
+		m.Elements = append(m.Elements, &proto.NormalField{Field: f, Repeated: repeated})
 	}
 }
 
-type generateGRPCTransportBase struct {
-	BaseGenerator
-	name             string
-	methods          []string
-	allMethods       []parser.Method
-	interfaceName    string
-	destPath         string
-	filePath         string
-	file             *parser.File
-	grpcFilePath     string
-	serviceInterface parser.Interface
+// fieldType returns the proto3 type of the go type and adds the messages and
+// imports the type needs to the proto.
+func (g *generateGRPCTransportProto) fieldType(tp string) (typ string, key string, repeated bool) {
+	typ, key, repeated = protoType(tp, g.structs)
+	if typ == "google.protobuf.Timestamp" {
+		g.addImport("google/protobuf/timestamp.proto")
+	}
+	for _, s := range g.structs {
+		if s.Name == typ {
+			g.addStructMessage(s)
+		}
+	}
+	return
 }
 
-func newGenerateGRPCTransportBase(name string, serviceInterface parser.Interface, methods []string, allMethods []parser.Method) Gen {
-	t := &generateGRPCTransportBase{
-		name:             name,
-		methods:          methods,
-		allMethods:       allMethods,
-		interfaceName:    utils.ToCamelCase(name + "Service"),
-		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_path_format"), utils.ToLowerSnakeCase(name)),
-		serviceInterface: serviceInterface,
+// protoType returns the proto3 type of a go type, key is set for maps.
+// Types that can not be mapped are sent as JSON encoded bytes.
+func protoType(tp string, structs []parser.Struct) (typ string, key string, repeated bool) {
+	if elem, ok := grpcChanElem(tp); ok {
+		return protoType(elem, structs)
 	}
-	t.filePath = path.Join(t.destPath, viper.GetString("gk_grpc_base_file_name"))
-	t.grpcFilePath = path.Join(t.destPath, viper.GetString("gk_grpc_file_name"))
-	t.srcFile = jen.NewFilePath(t.destPath)
-	t.InitPg()
-	t.fs = fs.Get()
-	return t
-}
-func (g *generateGRPCTransportBase) Generate() (err error) {
-	err = g.CreateFolderStructure(g.destPath)
-	if err != nil {
-		return err
+	tp = strings.TrimPrefix(strings.Replace(tp, "...", "[]", 1), "*")
+	if tp == "error" {
+		return "string", "", false
 	}
-	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
-	endpointImport, err := utils.GetEndpointImportPath(g.name)
-	if err != nil {
-		return err
+	if t, ok := protoScalarTypes[tp]; ok {
+		return t, "", false
 	}
-	pbImport, err := utils.GetPbImportPath(g.name)
-	if err != nil {
-		return err
+	if tp == "time.Time" {
+		return "google.protobuf.Timestamp", "", false
 	}
-	g.code.appendMultilineComment([]string{
-		"NewGRPCServer makes a set of endpoints available as a gRPC AddServer",
-	})
-	g.code.NewLine()
-	existingGrpc := false
-	if b, err := g.fs.Exists(g.grpcFilePath); err != nil {
-		return err
-	} else if b {
-		existingGrpc = true
+	if strings.HasPrefix(tp, "[]") {
+		t, k, r := protoType(tp[2:], structs)
+		if k == "" && !r && !protoJSON(tp[2:], structs) {
+			return t, "", true
+		}
+		return "bytes", "", false
 	}
-	vl := jen.Dict{}
-	fields := []jen.Code{}
-	if existingGrpc {
-		src, err := g.fs.ReadFile(g.grpcFilePath)
-		if err != nil {
-			return err
+	if strings.HasPrefix(tp, "map[") {
+		kv := strings.SplitN(tp[4:], "]", 2)
+		k, kOk := protoScalarTypes[kv[0]]
+		v, vk, r := protoType(kv[1], structs)
+		// Map values can not be maps or repeated.
+		if kOk && k != "float" && k != "double" && k != "bytes" && vk == "" && !r && !protoJSON(kv[1], structs) {
+			return v, k, false
 		}
-		g.file, err = parser.NewFileParser().Parse([]byte(src))
+		return "bytes", "", false
+	}
+	for _, s := range structs {
+		if s.Name == tp {
+			return s.Name, "", false
+		}
+	}
+	return "bytes", "", false
+}
+
+// grpcChanElem returns the element type of a channel that can be streamed,
+// send only channels are not streamed because the receiver is the service.
+func grpcChanElem(tp string) (string, bool) {
+	for _, p := range []string{"<-chan ", "chan "} {
+		if strings.HasPrefix(tp, p) {
+			return tp[len(p):], true
+		}
+	}
+	return "", false
+}
+
+// grpcStreaming returns true if the method streams the requests or the replies.
+func grpcStreaming(m parser.Method) bool {
+	_, _, streamsRequest := grpcStream(m.Parameters)
+	_, _, streamsReturns := grpcStream(m.Results)
+	return streamsRequest || streamsReturns
+}
+
+// grpcStream returns the first channel of the list, the values of the channel
+// are streamed.
+func grpcStream(list []parser.NamedTypeValue) (f grpcField, elem string, ok bool) {
+	for _, v := range grpcFields(list) {
+		if elem, ok = grpcChanElem(v.Type); ok {
+			return v, elem, true
+		}
+	}
+	return
+}
+
+// protoJSON returns true if the go type is sent as JSON encoded bytes.
+func protoJSON(tp string, structs []parser.Struct) bool {
+	typ, _, _ := protoType(tp, structs)
+	return typ == "bytes" && strings.TrimPrefix(strings.Replace(tp, "...", "[]", 1), "*") != "[]byte"
+}
+
+// protoGoTypes maps the proto3 scalar types to the go types generated by protoc.
+var protoGoTypes = map[string]string{
+	"string": "string",
+	"bool":   "bool",
+	"int32":  "int32",
+	"int64":  "int64",
+	"uint32": "uint32",
+	"uint64": "uint64",
+	"float":  "float32",
+	"double": "float64",
+	"bytes":  "[]byte",
+}
+
+// protoGoName returns the name protoc-gen-go gives to the go field of a proto field.
+func protoGoName(s string) string {
+	isLower := func(c byte) bool { return 'a' <= c && c <= 'z' }
+	isDigit := func(c byte) bool { return '0' <= c && c <= '9' }
+	t := []byte{}
+	i := 0
+	if len(s) > 0 && s[0] == '_' {
+		t = append(t, 'X')
+		i++
+	}
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '_' && i+1 < len(s) && isLower(s[i+1]) {
+			continue
+		}
+		if isDigit(c) {
+			t = append(t, c)
+			continue
+		}
+		if isLower(c) {
+			c ^= ' '
+		}
+		t = append(t, c)
+		for i+1 < len(s) && isLower(s[i+1]) {
+			i++
+			t = append(t, s[i])
+		}
+	}
+	return string(t)
+}
+
+// grpcField is a field of an endpoint request/response or of a service struct.
+type grpcField struct {
+	// Name is the name of the go field.
+	Name string
+	// PbName is the name of the field in the struct generated by protoc.
+	PbName string
+	// Type is the go type of the field.
+	Type string
+}
+
+func grpcFields(list []parser.NamedTypeValue) []grpcField {
+	fields := []grpcField{}
+	for _, p := range list {
+		if p.Type == "context.Context" {
+			continue
+		}
+		fields = append(fields, grpcField{
+			Name:   utils.ToCamelCase(p.Name),
+			PbName: protoGoName(utils.ToLowerSnakeCase(p.Name)),
+			Type:   p.Type,
+		})
+	}
+	return fields
+}
+
+// grpcConverts reports whether any of the fields is converted by toPB and
+// fromPB, the channels are sent through the stream instead.
+func grpcConverts(fields []grpcField) bool {
+	for _, f := range fields {
+		if _, ok := grpcChanElem(f.Type); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcConverter generates the code that converts the user-domain types to
+// the types generated by protoc and back, the conversion follows the mapping
+// of generateGRPCTransportProto.
+type grpcConverter struct {
+	pbImport      string
+	serviceImport string
+	structs       []parser.Struct
+	// used are the structs that need an encode and decode function.
+	used []parser.Struct
+	// onErr is the statement that handles the conversion errors.
+	onErr   jen.Code
+	usesErr bool
+}
+
+func newGRPCConverter(pbImport, serviceImport string, structs []parser.Struct) *grpcConverter {
+	return &grpcConverter{
+		pbImport:      pbImport,
+		serviceImport: serviceImport,
+		structs:       structs,
+	}
+}
+
+// toPB returns the statements that convert the endpoint struct `src` to the
+// protoc struct `dst` of type `tp`.
+func (c *grpcConverter) toPB(tp *jen.Statement, src, dst string, fields []grpcField, errReturn jen.Code) []jen.Code {
+	c.onErr, c.usesErr = jen.Return(errReturn, jen.Err()), false
+	vl := jen.Dict{}
+	st := []jen.Code{}
+	for _, f := range fields {
+		tp := strings.Replace(f.Type, "...", "[]", 1)
+		if _, ok := grpcChanElem(tp); ok {
+			continue
+		}
+		if _, ok := protoScalarTypes[tp]; ok || tp == "time.Time" || c.sameType(tp) {
+			vl[jen.Id(f.PbName)] = c.toPBValue(jen.Id(src).Dot(f.Name), tp)
+			continue
+		}
+		st = append(st, c.toPBField(jen.Id(dst).Dot(f.PbName), jen.Id(src).Dot(f.Name), tp, 0)...)
+	}
+	code := []jen.Code{jen.Id(dst).Op(":=").Id("&").Add(tp).Values(vl)}
+	if c.usesErr {
+		code = append(code, jen.Var().Err().Error())
+	}
+	return append(code, st...)
+}
+
+// fromPB returns the statements that convert the protoc struct `src` to the
+// endpoint struct `dst` of type `tp`.
+func (c *grpcConverter) fromPB(tp *jen.Statement, src, dst string, fields []grpcField, errReturn jen.Code) []jen.Code {
+	c.onErr, c.usesErr = jen.Return(errReturn, jen.Err()), false
+	vl := jen.Dict{}
+	st := []jen.Code{}
+	for _, f := range fields {
+		tp := strings.Replace(f.Type, "...", "[]", 1)
+		if _, ok := grpcChanElem(tp); ok {
+			continue
+		}
+		if _, ok := protoScalarTypes[tp]; ok || c.sameType(tp) {
+			vl[jen.Id(f.Name)] = c.fromPBValue(jen.Id(src).Dot(f.PbName), tp)
+			continue
+		}
+		st = append(st, c.fromPBField(jen.Id(dst).Dot(f.Name), jen.Id(src).Dot(f.PbName), tp, 0)...)
+	}
+	code := []jen.Code{jen.Id(dst).Op(":=").Add(tp).Values(vl)}
+	if c.usesErr {
+		code = append(code, jen.Var().Err().Error())
+	}
+	return append(code, st...)
+}
+
+// sameType returns true if protoc generates the same go type, so the value
+// can be assigned without a conversion.
+func (c *grpcConverter) sameType(tp string) bool {
+	switch {
+	case strings.HasPrefix(tp, "[]") && tp != "[]byte":
+		return c.sameType(tp[2:])
+	case strings.HasPrefix(tp, "map["):
+		kv := strings.SplitN(tp[4:], "]", 2)
+		return c.sameType(kv[0]) && c.sameType(kv[1])
+	}
+	t, ok := protoScalarTypes[tp]
+	return ok && protoGoTypes[t] == tp
+}
+
+// toPBElem returns the statements that convert the value `src` received from
+// a streamed channel to the field `dst` of a message.
+func (c *grpcConverter) toPBElem(dst, src *jen.Statement, tp string, onErr jen.Code) []jen.Code {
+	c.onErr = onErr
+	return c.toPBField(dst, src, tp, 1)
+}
+
+// fromPBElem returns the statements that convert the field `src` of a message
+// to the value `dst` that is sent on a streamed channel.
+func (c *grpcConverter) fromPBElem(dst, src *jen.Statement, tp string, onErr jen.Code) []jen.Code {
+	c.onErr = onErr
+	return c.fromPBField(dst, src, tp, 1)
+}
+
+// toPBValue returns the expression that converts a scalar or a time.Time.
+func (c *grpcConverter) toPBValue(src *jen.Statement, tp string) jen.Code {
+	if c.sameType(tp) {
+		return src
+	}
+	if tp == "time.Time" {
+		return jen.Id("&").Qual("github.com/golang/protobuf/ptypes/timestamp", "Timestamp").Values(jen.Dict{
+			jen.Id("Seconds"): jen.Add(src).Dot("Unix").Call(),
+			jen.Id("Nanos"):   jen.Int32().Call(jen.Add(src).Dot("Nanosecond").Call()),
+		})
+	}
+	if t := protoGoTypes[protoScalarTypes[tp]]; t != tp {
+		return jen.Id(t).Call(src)
+	}
+	return src
+}
+
+// fromPBValue returns the expression that converts a scalar.
+func (c *grpcConverter) fromPBValue(src *jen.Statement, tp string) jen.Code {
+	if c.sameType(tp) {
+		return src
+	}
+	if t := protoGoTypes[protoScalarTypes[tp]]; t != tp {
+		return c.goType(tp).Call(src)
+	}
+	return src
+}
+
+func (c *grpcConverter) toPBField(dst, src *jen.Statement, tp string, depth int) []jen.Code {
+	i, k, v := c.loopVars(depth)
+	switch {
+	case c.sameType(tp):
+		return []jen.Code{jen.Add(dst).Op("=").Add(src)}
+	case tp == "error":
+		return []jen.Code{
+			jen.If(jen.Add(src).Op("!=").Nil()).Block(
+				jen.Add(dst).Op("=").Add(src).Dot("Error").Call(),
+			),
+		}
+	case protoJSON(tp, c.structs):
+		c.usesErr = true
+		return []jen.Code{
+			jen.If(
+				jen.List(jen.Add(dst), jen.Err()).Op("=").Qual("encoding/json", "Marshal").Call(src),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				c.onErr,
+			),
+		}
+	case tp == "*time.Time":
+		return []jen.Code{
+			jen.If(jen.Add(src).Op("!=").Nil()).Block(
+				jen.Add(dst).Op("=").Add(c.toPBValue(src, "time.Time")),
+			),
+		}
+	case strings.HasPrefix(tp, "*"):
+		return []jen.Code{
+			jen.If(jen.Add(src).Op("!=").Nil()).Block(
+				c.toPBField(dst, jen.Op("*").Add(src), tp[1:], depth)...,
+			),
+		}
+	case tp == "time.Time":
+		return []jen.Code{jen.Add(dst).Op("=").Add(c.toPBValue(src, tp))}
+	case strings.HasPrefix(tp, "[]") && tp != "[]byte":
+		return []jen.Code{
+			jen.Add(dst).Op("=").Make(c.pbType(tp), jen.Len(src)),
+			jen.For(jen.List(jen.Id(i), jen.Id(v)).Op(":=").Range().Add(src)).Block(
+				c.toPBField(jen.Add(dst).Index(jen.Id(i)), jen.Id(v), tp[2:], depth+1)...,
+			),
+		}
+	case strings.HasPrefix(tp, "map["):
+		kv := strings.SplitN(tp[4:], "]", 2)
+		return []jen.Code{
+			jen.Add(dst).Op("=").Make(c.pbType(tp), jen.Len(src)),
+			jen.For(jen.List(jen.Id(k), jen.Id(v)).Op(":=").Range().Add(src)).Block(
+				c.toPBField(jen.Add(dst).Index(c.toPBValue(jen.Id(k), kv[0])), jen.Id(v), kv[1], depth+1)...,
+			),
+		}
+	}
+	if s := c.structType(tp); s != nil {
+		c.usesErr = true
+		return []jen.Code{
+			jen.If(
+				jen.List(jen.Add(dst), jen.Err()).Op("=").Id("encode"+s.Name).Call(src),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				c.onErr,
+			),
+		}
+	}
+	return []jen.Code{jen.Add(dst).Op("=").Add(c.toPBValue(src, tp))}
+}
+
+func (c *grpcConverter) fromPBField(dst, src *jen.Statement, tp string, depth int) []jen.Code {
+	i, k, v := c.loopVars(depth)
+	switch {
+	case c.sameType(tp):
+		return []jen.Code{jen.Add(dst).Op("=").Add(src)}
+	case tp == "error":
+		return []jen.Code{
+			jen.If(jen.Add(src).Op("!=").Lit("")).Block(
+				jen.Add(dst).Op("=").Qual("errors", "New").Call(src),
+			),
+		}
+	case protoJSON(tp, c.structs):
+		c.usesErr = true
+		return []jen.Code{
+			jen.If(
+				jen.Err().Op("=").Qual("encoding/json", "Unmarshal").Call(src, jen.Op("&").Add(dst)),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				c.onErr,
+			),
+		}
+	case strings.HasPrefix(tp, "*"):
+		el := jen.Op("*").Add(dst)
+		if strings.HasPrefix(tp, "*[]") || strings.HasPrefix(tp, "*map[") {
+			el = jen.Parens(el)
+		}
+		code := []jen.Code{jen.Add(dst).Op("=").New(c.goType(tp[1:]))}
+		if tp == "*time.Time" {
+			code = append(code, jen.Add(el).Op("=").Add(c.timeValue(src)))
+		} else {
+			code = append(code, c.fromPBField(el, src, tp[1:], depth)...)
+		}
+		if tp == "*time.Time" || c.structType(tp[1:]) != nil {
+			return []jen.Code{jen.If(jen.Add(src).Op("!=").Nil()).Block(code...)}
+		}
+		return code
+	case tp == "time.Time":
+		return []jen.Code{
+			jen.If(jen.Add(src).Op("!=").Nil()).Block(
+				jen.Add(dst).Op("=").Add(c.timeValue(src)),
+			),
+		}
+	case strings.HasPrefix(tp, "[]") && tp != "[]byte":
+		return []jen.Code{
+			jen.Add(dst).Op("=").Make(c.goType(tp), jen.Len(src)),
+			jen.For(jen.List(jen.Id(i), jen.Id(v)).Op(":=").Range().Add(src)).Block(
+				c.fromPBField(jen.Add(dst).Index(jen.Id(i)), jen.Id(v), tp[2:], depth+1)...,
+			),
+		}
+	case strings.HasPrefix(tp, "map["):
+		kv := strings.SplitN(tp[4:], "]", 2)
+		return []jen.Code{
+			jen.Add(dst).Op("=").Make(c.goType(tp), jen.Len(src)),
+			jen.For(jen.List(jen.Id(k), jen.Id(v)).Op(":=").Range().Add(src)).Block(
+				c.fromPBField(jen.Add(dst).Index(c.fromPBValue(jen.Id(k), kv[0])), jen.Id(v), kv[1], depth+1)...,
+			),
+		}
+	}
+	if s := c.structType(tp); s != nil {
+		c.usesErr = true
+		return []jen.Code{
+			jen.If(
+				jen.List(jen.Add(dst), jen.Err()).Op("=").Id("decode"+s.Name).Call(src),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				c.onErr,
+			),
+		}
+	}
+	return []jen.Code{jen.Add(dst).Op("=").Add(c.fromPBValue(src, tp))}
+}
+
+// timeValue returns the expression that converts a protobuf timestamp to a time.Time.
+func (c *grpcConverter) timeValue(src *jen.Statement) jen.Code {
+	return jen.Qual("time", "Unix").Call(
+		jen.Add(src).Dot("Seconds"),
+		jen.Int64().Call(jen.Add(src).Dot("Nanos")),
+	).Dot("UTC").Call()
+}
+
+// loopVars returns the names of the index, key and value variables used
+// to convert slices and maps nested `depth` levels deep.
+func (c *grpcConverter) loopVars(depth int) (i, k, v string) {
+	if depth == 0 {
+		return "i", "k", "v"
+	}
+	return fmt.Sprintf("i%d", depth), fmt.Sprintf("k%d", depth), fmt.Sprintf("v%d", depth)
+}
+
+// structType returns the service struct of the type and marks it as used.
+func (c *grpcConverter) structType(tp string) *parser.Struct {
+	for i, s := range c.structs {
+		if s.Name != tp {
+			continue
+		}
+		found := false
+		for _, u := range c.used {
+			if u.Name == s.Name {
+				found = true
+			}
+		}
+		if !found {
+			c.used = append(c.used, s)
+		}
+		return &c.structs[i]
+	}
+	return nil
+}
+
+// pbType returns the type protoc generates for the go type.
+func (c *grpcConverter) pbType(tp string) *jen.Statement {
+	tp = strings.TrimPrefix(tp, "*")
+	if t, ok := protoScalarTypes[tp]; ok {
+		return jen.Id(protoGoTypes[t])
+	}
+	switch {
+	case tp == "time.Time":
+		return jen.Id("*").Qual("github.com/golang/protobuf/ptypes/timestamp", "Timestamp")
+	case strings.HasPrefix(tp, "[]"):
+		return jen.Index().Add(c.pbType(tp[2:]))
+	case strings.HasPrefix(tp, "map["):
+		kv := strings.SplitN(tp[4:], "]", 2)
+		return jen.Map(c.pbType(kv[0])).Add(c.pbType(kv[1]))
+	}
+	return jen.Id("*").Qual(c.pbImport, tp)
+}
+
+// goType returns the user-domain go type.
+func (c *grpcConverter) goType(tp string) *jen.Statement {
+	switch {
+	case strings.HasPrefix(tp, "*"):
+		return jen.Id("*").Add(c.goType(tp[1:]))
+	case strings.HasPrefix(tp, "[]"):
+		return jen.Index().Add(c.goType(tp[2:]))
+	case strings.HasPrefix(tp, "map["):
+		kv := strings.SplitN(tp[4:], "]", 2)
+		return jen.Map(c.goType(kv[0])).Add(c.goType(kv[1]))
+	case tp == "time.Time":
+		return jen.Qual("time", "Time")
+	case tp == "time.Duration":
+		return jen.Qual("time", "Duration")
+	}
+	for _, s := range c.structs {
+		if s.Name == tp {
+			return jen.Qual(c.serviceImport, tp)
+		}
+	}
+	return jen.Id(tp)
+}
+
+// appendStructFunctions appends the encode and decode functions of the used
+// structs that are not in `exclude`, the structs used by the appended functions
+// get their functions too.
+func (c *grpcConverter) appendStructFunctions(code *PartialGenerator, exclude []parser.Method) {
+	for n := 0; n < len(c.used); n++ {
+		s := c.used[n]
+		fields := []grpcField{}
+		for _, v := range s.Vars {
+			if v.Name != "" && v.Name[:1] == strings.ToUpper(v.Name[:1]) {
+				fields = append(fields, grpcField{
+					Name:   v.Name,
+					PbName: protoGoName(utils.ToLowerSnakeCase(v.Name)),
+					Type:   v.Type,
+				})
+			}
+		}
+		encodeFound := false
+		decodeFound := false
+		for _, m := range exclude {
+			if m.Name == "encode"+s.Name {
+				encodeFound = true
+			}
+			if m.Name == "decode"+s.Name {
+				decodeFound = true
+			}
+		}
+		if !encodeFound {
+			body := c.toPB(jen.Qual(c.pbImport, s.Name), "in", "out", fields, jen.Nil())
+			body = append(body, jen.Return(jen.Id("out"), jen.Nil()))
+			code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%s converts a user-domain %s to a gRPC message.", s.Name, s.Name),
+			})
+			code.NewLine()
+			code.appendFunction(
+				"encode"+s.Name,
+				nil,
+				[]jen.Code{
+					jen.Id("in").Qual(c.serviceImport, s.Name),
+				},
+				[]jen.Code{
+					jen.Id("*").Qual(c.pbImport, s.Name),
+					jen.Error(),
+				},
+				"",
+				body...,
+			)
+			code.NewLine()
+		}
+		if !decodeFound {
+			body := []jen.Code{
+				jen.If(jen.Id("in").Op("==").Nil()).Block(
+					jen.Return(jen.Qual(c.serviceImport, s.Name).Values(), jen.Nil()),
+				),
+			}
+			body = append(body, c.fromPB(jen.Qual(c.serviceImport, s.Name), "in", "out", fields, jen.Id("out"))...)
+			body = append(body, jen.Return(jen.Id("out"), jen.Nil()))
+			code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%s converts a gRPC message to a user-domain %s.", s.Name, s.Name),
+			})
+			code.NewLine()
+			code.appendFunction(
+				"decode"+s.Name,
+				nil,
+				[]jen.Code{
+					jen.Id("in").Id("*").Qual(c.pbImport, s.Name),
+				},
+				[]jen.Code{
+					jen.Qual(c.serviceImport, s.Name),
+					jen.Error(),
+				},
+				"",
+				body...,
+			)
+			code.NewLine()
+		}
+	}
+}
+
+// addStructMessage adds a message for a struct of the service package with
+// a field for each of the exported struct fields.
+func (g *generateGRPCTransportProto) addStructMessage(s parser.Struct) {
+	if g.structMessages[s.Name] {
+		return
+	}
+	g.structMessages[s.Name] = true
+	fields := []parser.NamedTypeValue{}
+	for _, v := range s.Vars {
+		if v.Name != "" && v.Name[:1] == strings.ToUpper(v.Name[:1]) {
+			fields = append(fields, v)
+		}
+	}
+	g.addMessageFields(g.getMessage(s.Name), fields)
+}
+
+// addImport adds the import to the proto after the syntax, package and
+// the other imports if it is not already imported.
+func (g *generateGRPCTransportProto) addImport(filename string) {
+	n := 0
+	for i, e := range g.protoSrc.Elements {
+		switch r := e.(type) {
+		case *proto.Import:
+			if r.Filename == filename {
+				return
+			}
+			n = i + 1
+		case *proto.Syntax, *proto.Package:
+			n = i + 1
+		}
+	}
+	g.protoSrc.Elements = append(g.protoSrc.Elements, nil)
+	copy(g.protoSrc.Elements[n+1:], g.protoSrc.Elements[n:])
+	g.protoSrc.Elements[n] = &proto.Import{
+		Filename: filename,
+	}
+}
+func (g *generateGRPCTransportProto) getServiceRPC(svc *proto.Service) {
+	for _, v := range g.serviceInterface.Methods {
+		_, _, streamsRequest := grpcStream(v.Parameters)
+		_, _, streamsReturns := grpcStream(v.Results)
+		var comment *proto.Comment
+		if doc := methodDoc(v); len(doc) > 0 {
+			comment = &proto.Comment{Lines: protoCommentLines(doc)}
+		}
+		found := false
+		for _, e := range svc.Elements {
+			if r, ok := e.(*proto.RPC); ok {
+				if r.Name == v.Name {
+					found = true
+					r.StreamsRequest, r.StreamsReturns = streamsRequest, streamsReturns
+					if comment != nil {
+						r.Comment = comment
+					}
+				}
+			}
+		}
+		if found {
+			continue
+		}
+		svc.Elements = append(svc.Elements,
+			&proto.RPC{
+				Comment:        comment,
+				Name:           v.Name,
+				ReturnsType:    v.Name + "Reply",
+				RequestType:    v.Name + "Request",
+				StreamsRequest: streamsRequest,
+				StreamsReturns: streamsReturns,
+			},
+		)
+	}
+}
+
+// protoCommentLines returns the lines of a go doc comment as the lines of a proto comment,
+// the formatter writes them right after the slashes and can not write blank lines.
+func protoCommentLines(doc []string) []string {
+	lines := []string{}
+	for _, v := range doc {
+		if v != "" {
+			lines = append(lines, " "+v)
+		}
+	}
+	return lines
+}
+
+type generateGRPCTransportBase struct {
+	BaseGenerator
+	name             string
+	methods          []string
+	allMethods       []parser.Method
+	interfaceName    string
+	destPath         string
+	filePath         string
+	file             *parser.File
+	grpcFilePath     string
+	serviceInterface parser.Interface
+}
+
+func newGenerateGRPCTransportBase(name string, serviceInterface parser.Interface, methods []string, allMethods []parser.Method) Gen {
+	t := &generateGRPCTransportBase{
+		name:             name,
+		methods:          methods,
+		allMethods:       allMethods,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_grpc_base_file_name"))
+	t.grpcFilePath = path.Join(t.destPath, viper.GetString("gk_grpc_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateGRPCTransportBase) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	pbImport, err := utils.GetPbImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.code.appendMultilineComment([]string{
+		"NewGRPCServer makes a set of endpoints available as a gRPC AddServer",
+	})
+	g.code.NewLine()
+	existingGrpc := false
+	if b, err := g.fs.Exists(g.grpcFilePath); err != nil {
+		return err
+	} else if b {
+		existingGrpc = true
+	}
+	vl := jen.Dict{}
+	fields := []jen.Code{}
+	if existingGrpc {
+		src, err := g.fs.ReadFile(g.grpcFilePath)
 		if err != nil {
 			return err
 		}
-		for _, m := range g.allMethods {
-			n := utils.ToLowerFirstCamelCase(m.Name)
+		g.file, err = parser.NewFileParser().Parse([]byte(src))
+		if err != nil {
+			return err
+		}
+		for _, m := range g.allMethods {
+			n := utils.ToLowerFirstCamelCase(m.Name)
+			if grpcStreaming(m) {
+				// Streaming methods call the endpoint directly because go-kit
+				// does not support gRPC streams.
+				for _, v := range g.file.Methods {
+					if v.Name == m.Name && v.Struct.Type == "*grpcServer" {
+						vl[jen.Id(n)] = jen.Id("endpoints").Dot(m.Name + "Endpoint")
+					}
+				}
+				fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/endpoint", "Endpoint"))
+				continue
+			}
 			for _, v := range g.file.Methods {
 				if v.Name == "make"+m.Name+"Handler" {
 					vl[jen.Id(n)] = jen.Id("make"+m.Name+"Handler").Call(
 						jen.Id("endpoints"),
 						jen.Id("options").Index(jen.Lit(m.Name)),
-					)
-				}
+					)
+				}
+			}
+			fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/transport/grpc", "Handler"))
+		}
+	} else {
+		for _, m := range g.serviceInterface.Methods {
+			n := utils.ToLowerFirstCamelCase(m.Name)
+			if grpcStreaming(m) {
+				vl[jen.Id(n)] = jen.Id("endpoints").Dot(m.Name + "Endpoint")
+				fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/endpoint", "Endpoint"))
+				continue
+			}
+			vl[jen.Id(n)] = jen.Id("make"+m.Name+"Handler").Call(
+				jen.Id("endpoints"),
+				jen.Id("options").Index(jen.Lit(m.Name)),
+			)
+			fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/transport/grpc", "Handler"))
+		}
+	}
+	g.code.appendStruct("grpcServer", fields...)
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewGRPCServer",
+		nil,
+		[]jen.Code{
+			jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
+			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/grpc", "ServerOption"),
+		},
+		[]jen.Code{
+			jen.Qual(pbImport, utils.ToCamelCase(g.name)+"Server"),
+		},
+		"",
+		jen.Return(jen.Id("&grpcServer").Values(vl)),
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
+
+type generateGRPCTransport struct {
+	BaseGenerator
+	name              string
+	methods           []string
+	interfaceName     string
+	destPath          string
+	generateFirstTime bool
+	file              *parser.File
+	filePath          string
+	serviceInterface  parser.Interface
+	structs           []parser.Struct
+}
+
+func newGenerateGRPCTransport(name string, serviceInterface parser.Interface, methods []string, structs []parser.Struct) Gen {
+	t := &generateGRPCTransport{
+		name:             name,
+		methods:          methods,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		structs:          structs,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_grpc_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateGRPCTransport) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	endpImports, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	pbImport, err := utils.GetPbImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("grpc")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	conv := newGRPCConverter(pbImport, serviceImport, g.structs)
+	for _, m := range g.serviceInterface.Methods {
+		decoderFound := false
+		encoderFound := false
+		handlerFound := false
+		funcFound := false
+		for _, v := range g.file.Methods {
+			if v.Name == fmt.Sprintf("decode%sRequest", m.Name) {
+				decoderFound = true
+			}
+			if v.Name == fmt.Sprintf("encode%sResponse", m.Name) {
+				encoderFound = true
+			}
+			if v.Name == fmt.Sprintf("make%sHandler", m.Name) {
+				handlerFound = true
+			}
+			if v.Name == m.Name && v.Struct.Type == "*grpcServer" {
+				funcFound = true
+			}
+		}
+		if !handlerFound && !grpcStreaming(m) {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("make%sHandler creates the handler logic", m.Name),
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("make%sHandler", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("endpoints").Qual(endpImports, "Endpoints"),
+					jen.Id("options").Index().Qual(
+						"github.com/go-kit/kit/transport/grpc",
+						"ServerOption",
+					),
+				},
+				[]jen.Code{
+					jen.Qual("github.com/go-kit/kit/transport/grpc", "Handler"),
+				},
+				"",
+				jen.Return(
+					jen.Qual("github.com/go-kit/kit/transport/grpc", "NewServer").Call(
+						jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
+						jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
+						jen.Id(fmt.Sprintf("encode%sResponse", m.Name)),
+						jen.Id("options..."),
+					),
+				),
+			)
+			g.code.NewLine()
+
+		}
+
+		if !decoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sRequest is a transport/grpc.DecodeRequestFunc that converts a", m.Name),
+				fmt.Sprintf("gRPC request to a user-domain %s request.", m.Name),
+			})
+			g.code.NewLine()
+			body := []jen.Code{}
+			if grpcConverts(grpcFields(m.Parameters)) {
+				body = append(body, jen.Id("req").Op(":=").Id("r").Dot("").Call(jen.Id("*").Qual(pbImport, m.Name+"Request")))
+			}
+			body = append(body, conv.fromPB(
+				jen.Qual(endpImports, m.Name+"Request"), "req", "request", grpcFields(m.Parameters), jen.Nil(),
+			)...)
+			body = append(body, jen.Return(jen.Id("request"), jen.Nil()))
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("r").Interface(),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				body...,
+			)
+			g.code.NewLine()
+		}
+		if !encoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sResponse is a transport/grpc.EncodeResponseFunc that converts", m.Name),
+				"a user-domain response to a gRPC reply.",
+			})
+			g.code.NewLine()
+			body := []jen.Code{}
+			if grpcConverts(grpcFields(m.Results)) {
+				body = append(body, jen.Id("resp").Op(":=").Id("r").Dot("").Call(jen.Qual(endpImports, m.Name+"Response")))
+			}
+			body = append(body, conv.toPB(
+				jen.Qual(pbImport, m.Name+"Reply"), "resp", "rep", grpcFields(m.Results), jen.Nil(),
+			)...)
+			body = append(body, jen.Return(jen.Id("rep"), jen.Nil()))
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sResponse", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("r").Interface(),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				body...,
+			)
+			g.code.NewLine()
+		}
+		if !funcFound && grpcStreaming(m) {
+			stp := g.GenerateNameBySample("grpcServer", append(m.Parameters, m.Results...))
+			g.appendStreamFunction(m, stp, conv, endpImports, pbImport)
+		} else if !funcFound {
+			stp := g.GenerateNameBySample("grpcServer", append(m.Parameters, m.Results...))
+			n := utils.ToCamelCase(m.Name)
+			g.code.appendFunction(
+				n,
+				jen.Id(stp).Id("*grpcServer"),
+				[]jen.Code{
+					jen.Id("ctx").Qual("golang.org/x/net/context", "Context"),
+					jen.Id("req").Id("*").Qual(pbImport, n+"Request"),
+				},
+				[]jen.Code{
+					jen.Id("*").Qual(pbImport, n+"Reply"),
+					jen.Error(),
+				},
+				"",
+				jen.List(
+					jen.Id("_"),
+					jen.Id("rep"),
+					jen.Err(),
+				).Op(":=").Id(stp).Dot(utils.ToLowerFirstCamelCase(m.Name)).Dot("ServeGRPC").Call(
+					jen.Id("ctx"),
+					jen.Id("req"),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Nil(), jen.Err()),
+				),
+				jen.Return(
+					jen.Id("rep").Dot("").Call(
+						jen.Id("*").Qual(pbImport, n+"Reply"),
+					),
+					jen.Nil(),
+				),
+			)
+			g.code.NewLine()
+		}
+	}
+	conv.appendStructFunctions(g.code, g.file.Methods)
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	// See if we need to add any new import
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	s, err := g.MergeSource(g.destPath, src, g.code.Raw().GoString(), imp)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+// appendStreamFunction appends the method of the gRPC server of a streaming
+// method. The first message of a stream carries the values that are not
+// streamed and each of the messages that follow carries a value of the channel.
+func (g *generateGRPCTransport) appendStreamFunction(m parser.Method, stp string, conv *grpcConverter, endpImports, pbImport string) {
+	reqField, reqElem, streamsRequest := grpcStream(m.Parameters)
+	repField, repElem, streamsReturns := grpcStream(m.Results)
+	stream := jen.Id("stream").Qual(pbImport, fmt.Sprintf("%s_%sServer", utils.ToCamelCase(g.name), m.Name))
+	params := []jen.Code{stream}
+	body := []jen.Code{
+		jen.Id("ctx").Op(":=").Id("stream").Dot("Context").Call(),
+	}
+	if streamsRequest {
+		body = append(
+			body,
+			jen.List(jen.Id("req"), jen.Err()).Op(":=").Id("stream").Dot("Recv").Call(),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+		)
+	} else {
+		params = []jen.Code{jen.Id("req").Id("*").Qual(pbImport, m.Name+"Request"), stream}
+	}
+	body = append(
+		body,
+		jen.List(jen.Id("request"), jen.Err()).Op(":=").Id(fmt.Sprintf("decode%sRequest", m.Name)).Call(
+			jen.Id("ctx"),
+			jen.Id("req"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+	)
+	if streamsRequest {
+		recv := []jen.Code{
+			jen.Defer().Close(jen.Id("in")),
+		}
+		loop := []jen.Code{
+			jen.List(jen.Id("req"), jen.Err()).Op(":=").Id("stream").Dot("Recv").Call(),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(),
+			),
+			jen.Var().Id("v").Add(conv.goType(reqElem)),
+		}
+		loop = append(loop, conv.fromPBElem(jen.Id("v"), jen.Id("req").Dot(reqField.PbName), reqElem, jen.Return())...)
+		loop = append(loop, jen.Select().Block(
+			jen.Case(jen.Id("in").Op("<-").Id("v")),
+			jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+				jen.Return(),
+			),
+		))
+		recv = append(recv, jen.For().Block(loop...))
+		body = append(
+			body,
+			jen.Id("r").Op(":=").Id("request").Dot("").Call(jen.Qual(endpImports, m.Name+"Request")),
+			jen.Id("in").Op(":=").Make(jen.Chan().Add(conv.goType(reqElem))),
+			jen.Id("r").Dot(reqField.Name).Op("=").Id("in"),
+			jen.Id("request").Op("=").Id("r"),
+			jen.Go().Func().Params().Block(recv...).Call(),
+		)
+	}
+	body = append(
+		body,
+		jen.List(jen.Id("response"), jen.Err()).Op(":=").Id(stp).Dot(utils.ToLowerFirstCamelCase(m.Name)).Call(
+			jen.Id("ctx"),
+			jen.Id("request"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.List(jen.Id("rep"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sResponse", m.Name)).Call(
+			jen.Id("ctx"),
+			jen.Id("response"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+	)
+	if !streamsReturns {
+		body = append(body, jen.Return(jen.Id("stream").Dot("SendAndClose").Call(
+			jen.Id("rep").Dot("").Call(jen.Id("*").Qual(pbImport, m.Name+"Reply")),
+		)))
+	} else {
+		send := []jen.Code{
+			jen.Id("rep").Op(":=").Id("&").Qual(pbImport, m.Name+"Reply").Values(),
+		}
+		send = append(send, conv.toPBElem(
+			jen.Id("rep").Dot(repField.PbName), jen.Id("v"), repElem, jen.Return(jen.Err()),
+		)...)
+		send = append(send, jen.If(
+			jen.Err().Op("=").Id("stream").Dot("Send").Call(jen.Id("rep")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Err()),
+		))
+		body = append(
+			body,
+			jen.If(
+				jen.Err().Op("=").Id("stream").Dot("Send").Call(
+					jen.Id("rep").Dot("").Call(jen.Id("*").Qual(pbImport, m.Name+"Reply")),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.Id("out").Op(":=").Id("response").Dot("").Call(
+				jen.Qual(endpImports, m.Name+"Response"),
+			).Dot(repField.Name),
+			jen.If(jen.Id("out").Op("==").Nil()).Block(
+				jen.Return(jen.Nil()),
+			),
+			jen.For().Block(
+				jen.Select().Block(
+					jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+						jen.Return(jen.Id("ctx").Dot("Err").Call()),
+					),
+					jen.Case(jen.List(jen.Id("v"), jen.Id("ok")).Op(":=").Op("<-").Id("out")).Block(
+						append([]jen.Code{
+							jen.If(jen.Op("!").Id("ok")).Block(
+								jen.Return(jen.Nil()),
+							),
+						}, send...)...,
+					),
+				),
+			),
+		)
+	}
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("%s serves the %s stream, the first message carries the", m.Name, m.Name),
+		"values that are not streamed and each message that follows a value of the channel.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		m.Name,
+		jen.Id(stp).Id("*grpcServer"),
+		params,
+		[]jen.Code{
+			jen.Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+}
+
+// thriftBaseTypes maps go types to the thrift IDL type and the go type that
+// the thrift compiler generates for it.
+var thriftBaseTypes = map[string][2]string{
+	"string":  {"string", "string"},
+	"bool":    {"bool", "bool"},
+	"int8":    {"byte", "int8"},
+	"int16":   {"i16", "int16"},
+	"int32":   {"i32", "int32"},
+	"rune":    {"i32", "int32"},
+	"int":     {"i64", "int64"},
+	"int64":   {"i64", "int64"},
+	"uint8":   {"i16", "int16"},
+	"byte":    {"i16", "int16"},
+	"uint16":  {"i32", "int32"},
+	"uint32":  {"i64", "int64"},
+	"uint":    {"i64", "int64"},
+	"uint64":  {"i64", "int64"},
+	"float32": {"double", "float64"},
+	"float64": {"double", "float64"},
+	"[]byte":  {"binary", "[]byte"},
+}
+
+// thriftInitialisms are the names that the thrift go generator writes in uppercase.
+var thriftInitialisms = []string{
+	"API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP",
+	"JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SSH", "TCP", "TLS", "TTL", "UDP",
+	"UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML", "XSRF", "XSS",
+}
+
+// thriftField describes how a method parameter or result is represented in the thrift IDL.
+type thriftField struct {
+	// Name is the name of the field in the endpoint request/response struct.
+	Name string
+	// Type is the go type of the field in the endpoint request/response struct.
+	Type string
+	// IDLType is the type used in the thrift IDL.
+	IDLType string
+	// GoName is the name of the field in the struct generated by the thrift compiler.
+	GoName string
+	// GoType is the go type of the field in the struct generated by the thrift compiler.
+	GoType string
+	// JSON is true if the value is not supported by thrift and is sent as JSON.
+	JSON bool
+	// Error is true if the field is the error result of the method.
+	Error bool
+}
+
+func newThriftField(p parser.NamedTypeValue) thriftField {
+	f := thriftField{
+		Name: utils.ToCamelCase(p.Name),
+		Type: strings.Replace(p.Type, "...", "[]", 1),
+	}
+	f.GoName = f.Name
+	for _, v := range thriftInitialisms {
+		if strings.ToUpper(f.Name) == v {
+			f.GoName = v
+			break
+		}
+	}
+	if f.Type == "error" {
+		f.Error = true
+		f.IDLType, f.GoType = "string", "string"
+		return f
+	}
+	if t, ok := thriftBaseTypes[f.Type]; ok {
+		f.IDLType, f.GoType = t[0], t[1]
+		return f
+	}
+	// Lists and maps are supported only when thrift generates the same go type.
+	if strings.HasPrefix(f.Type, "[]") {
+		if t, ok := thriftBaseTypes[f.Type[2:]]; ok && t[1] == f.Type[2:] {
+			f.IDLType, f.GoType = "list<"+t[0]+">", f.Type
+			return f
+		}
+	}
+	if strings.HasPrefix(f.Type, "map[") {
+		kv := strings.SplitN(f.Type[4:], "]", 2)
+		k, kOk := thriftBaseTypes[kv[0]]
+		v, vOk := thriftBaseTypes[kv[1]]
+		if kOk && vOk && k[1] == kv[0] && v[1] == kv[1] {
+			f.IDLType, f.GoType = "map<"+k[0]+","+v[0]+">", f.Type
+			return f
+		}
+	}
+	f.IDLType, f.GoType, f.JSON = "binary", "[]byte", true
+	return f
+}
+
+func thriftFields(list []parser.NamedTypeValue) []thriftField {
+	fields := []thriftField{}
+	for _, p := range list {
+		if p.Type == "context.Context" {
+			continue
+		}
+		fields = append(fields, newThriftField(p))
+	}
+	return fields
+}
+
+// thriftToEndpoint returns the statements that convert the thrift struct `src` to the
+// endpoint struct `dst` of type `tp`.
+func thriftToEndpoint(tp *jen.Statement, src, dst string, fields []thriftField) []jen.Code {
+	vl := jen.Dict{}
+	st := []jen.Code{}
+	for _, f := range fields {
+		switch {
+		case f.Error:
+			st = append(st, jen.If(jen.Id(src).Dot(f.GoName).Op("!=").Lit("")).Block(
+				jen.Id(dst).Dot(f.Name).Op("=").Qual("errors", "New").Call(jen.Id(src).Dot(f.GoName)),
+			))
+		case f.JSON:
+			st = append(st, jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+					jen.Id(src).Dot(f.GoName), jen.Id("&"+dst).Dot(f.Name),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			))
+		case f.GoType != f.Type:
+			vl[jen.Id(f.Name)] = jen.Id(f.Type).Call(jen.Id(src).Dot(f.GoName))
+		default:
+			vl[jen.Id(f.Name)] = jen.Id(src).Dot(f.GoName)
+		}
+	}
+	return append([]jen.Code{jen.Id(dst).Op(":=").Add(tp).Values(vl)}, st...)
+}
+
+// endpointToThrift returns the statements that convert the endpoint struct `src` to the
+// thrift struct `dst` of type `tp`.
+func endpointToThrift(tp *jen.Statement, src, dst string, fields []thriftField) []jen.Code {
+	vl := jen.Dict{}
+	st := []jen.Code{}
+	hasJSON := false
+	for _, f := range fields {
+		switch {
+		case f.Error:
+			st = append(st, jen.If(jen.Id(src).Dot(f.Name).Op("!=").Nil()).Block(
+				jen.Id(dst).Dot(f.GoName).Op("=").Id(src).Dot(f.Name).Dot("Error").Call(),
+			))
+		case f.JSON:
+			hasJSON = true
+			st = append(st, jen.If(
+				jen.List(jen.Id(dst).Dot(f.GoName), jen.Err()).Op("=").Qual("encoding/json", "Marshal").Call(
+					jen.Id(src).Dot(f.Name),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			))
+		case f.GoType != f.Type:
+			vl[jen.Id(f.GoName)] = jen.Id(f.GoType).Call(jen.Id(src).Dot(f.Name))
+		default:
+			vl[jen.Id(f.GoName)] = jen.Id(src).Dot(f.Name)
+		}
+	}
+	code := []jen.Code{jen.Id(dst).Op(":=").Id("&").Add(tp).Values(vl)}
+	if hasJSON {
+		code = append(code, jen.Var().Err().Error())
+	}
+	return append(code, st...)
+}
+
+type generateThriftTransportIDL struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	idlFilePath      string
+	compileFilePath  string
+	thriftFilePath   string
+	serviceInterface parser.Interface
+	allMethods       []parser.Method
+}
+
+func newGenerateThriftTransportIDL(name string, serviceInterface parser.Interface, allMethods []parser.Method) Gen {
+	t := &generateThriftTransportIDL{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_thrift_gen_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		allMethods:       allMethods,
+	}
+	t.idlFilePath = path.Join(
+		t.destPath,
+		fmt.Sprintf(viper.GetString("gk_thrift_idl_file_name"), utils.ToLowerSnakeCase(name)),
+	)
+	t.compileFilePath = path.Join(t.destPath, viper.GetString("gk_thrift_compile_file_name"))
+	t.thriftFilePath = path.Join(
+		fmt.Sprintf(viper.GetString("gk_thrift_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_thrift_file_name"),
+	)
+	t.fs = fs.Get()
+	return t
+}
+
+// Generate generates the thrift IDL, the IDL contains all the methods that
+// have a handler in the thrift transport.
+func (g *generateThriftTransportIDL) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	src, err := g.fs.ReadFile(g.thriftFilePath)
+	if err != nil {
+		return err
+	}
+	file, err := parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	svcName := utils.ToCamelCase(g.name)
+	buf := bytes.NewBufferString("")
+	fmt.Fprintf(buf, "// THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!\n")
+	fmt.Fprintf(buf, "// Values of types that thrift does not support are sent as JSON encoded binary.\n\n")
+	fmt.Fprintf(buf, "namespace go %s\n\n", path.Base(g.destPath))
+	rpc := []string{}
+	for _, m := range g.allMethods {
+		found := false
+		for _, v := range file.Methods {
+			if v.Name == m.Name && v.Struct.Type == "*thriftServer" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		g.writeStruct(buf, m.Name+"Request", thriftFields(m.Parameters))
+		g.writeStruct(buf, m.Name+"Reply", thriftFields(m.Results))
+		rpc = append(rpc, fmt.Sprintf("  %sReply %s(1: %sRequest req)", m.Name, m.Name, m.Name))
+	}
+	fmt.Fprintf(buf, "// The %s service definition.\n", svcName)
+	fmt.Fprintf(buf, "service %s {\n%s\n}\n", svcName, strings.Join(rpc, "\n"))
+	err = g.fs.WriteFile(g.idlFilePath, buf.String(), true)
+	if err != nil {
+		return err
+	}
+	idlFilePath := g.idlFilePath
+	outPath := path.Dir(g.destPath)
+	if viper.GetString("gk_folder") != "" {
+		idlFilePath = path.Join(viper.GetString("gk_folder"), idlFilePath)
+		outPath = path.Join(viper.GetString("gk_folder"), outPath)
+	}
+	if viper.GetBool("gk_dry_run") {
+		logrus.Infof("Dry run, skipping `thrift -r --gen go:skip_remote -out %s %s`", outPath, idlFilePath)
+	} else if !viper.GetBool("gk_testing") {
+		// thrift reads the idl file from the disk so it runs after the files are committed.
+		g.fs.OnCommit(func() error {
+			cmd := exec.Command("thrift", "-r", "--gen", "go:skip_remote", "-out", outPath, idlFilePath)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("thrift failed: %s", err)
+			}
+			return nil
+		})
+	}
+	if b, e := g.fs.Exists(g.compileFilePath); e != nil {
+		return e
+	} else if b {
+		return
+	}
+	compile := fmt.Sprintf(
+		"thrift -r --gen go:skip_remote -out .. %s",
+		fmt.Sprintf(viper.GetString("gk_thrift_idl_file_name"), utils.ToLowerSnakeCase(g.name)),
+	)
+	if runtime.GOOS == "windows" {
+		return g.fs.WriteFile(
+			g.compileFilePath,
+			`:: Install the thrift compiler.
+:: https://thrift.apache.org/download
+::
+:: See also
+::  https://github.com/go-kit/kit/tree/master/examples/addsvc/thrift
+
+`+compile,
+			false,
+		)
+	}
+	return g.fs.WriteFile(
+		g.compileFilePath,
+		`#!/usr/bin/env sh
+
+# Install the thrift compiler.
+# https://thrift.apache.org/download
+#
+# See also
+#  https://github.com/go-kit/kit/tree/master/examples/addsvc/thrift
+
+`+compile,
+		false,
+	)
+}
+func (g *generateThriftTransportIDL) writeStruct(buf *bytes.Buffer, name string, fields []thriftField) {
+	fmt.Fprintf(buf, "struct %s {\n", name)
+	for i, f := range fields {
+		fmt.Fprintf(buf, "  %d: %s %s\n", i+1, f.IDLType, f.Name)
+	}
+	fmt.Fprintf(buf, "}\n\n")
+}
+
+type generateThriftTransportBase struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+}
+
+func newGenerateThriftTransportBase(name string, serviceInterface parser.Interface) Gen {
+	t := &generateThriftTransportBase{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_thrift_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_thrift_base_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateThriftTransportBase) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	genImport, err := utils.GetThriftGenImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.code.appendStruct(
+		"thriftServer",
+		jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"NewThriftServer makes a set of endpoints available as a thrift service.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewThriftServer",
+		nil,
+		[]jen.Code{
+			jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
+		},
+		[]jen.Code{
+			jen.Qual(genImport, utils.ToCamelCase(g.name)),
+		},
+		"",
+		jen.Return(jen.Id("&thriftServer").Values(jen.Dict{
+			jen.Id("endpoints"): jen.Id("endpoints"),
+		})),
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
+
+type generateThriftTransport struct {
+	BaseGenerator
+	name              string
+	interfaceName     string
+	destPath          string
+	generateFirstTime bool
+	file              *parser.File
+	filePath          string
+	serviceInterface  parser.Interface
+}
+
+func newGenerateThriftTransport(name string, serviceInterface parser.Interface) Gen {
+	t := &generateThriftTransport{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_thrift_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_thrift_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateThriftTransport) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	endpImports, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	genImport, err := utils.GetThriftGenImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("thrift")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	for _, m := range g.serviceInterface.Methods {
+		decoderFound := false
+		encoderFound := false
+		funcFound := false
+		for _, v := range g.file.Methods {
+			if v.Name == fmt.Sprintf("decode%sRequest", m.Name) {
+				decoderFound = true
+			}
+			if v.Name == fmt.Sprintf("encode%sResponse", m.Name) {
+				encoderFound = true
+			}
+			if v.Name == m.Name && v.Struct.Type == "*thriftServer" {
+				funcFound = true
+			}
+		}
+		if !decoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sRequest converts a thrift request to a user-domain %s request.", m.Name, m.Name),
+			})
+			g.code.NewLine()
+			body := thriftToEndpoint(
+				jen.Qual(endpImports, m.Name+"Request"), "r", "req", thriftFields(m.Parameters),
+			)
+			body = append(body, jen.Return(jen.Id("req"), jen.Nil()))
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("r").Id("*").Qual(genImport, m.Name+"Request"),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				body...,
+			)
+			g.code.NewLine()
+		}
+		if !encoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sResponse converts a user-domain response to a thrift reply.", m.Name),
+			})
+			g.code.NewLine()
+			body := []jen.Code{}
+			if grpcConverts(grpcFields(m.Results)) {
+				body = append(body, jen.Id("resp").Op(":=").Id("r").Dot("").Call(jen.Qual(endpImports, m.Name+"Response")))
+			}
+			body = append(body, endpointToThrift(
+				jen.Qual(genImport, m.Name+"Reply"), "resp", "rep", thriftFields(m.Results),
+			)...)
+			body = append(body, jen.Return(jen.Id("rep"), jen.Nil()))
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sResponse", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("r").Interface(),
+				},
+				[]jen.Code{
+					jen.Id("*").Qual(genImport, m.Name+"Reply"),
+					jen.Error(),
+				},
+				"",
+				body...,
+			)
+			g.code.NewLine()
+		}
+		if !funcFound {
+			stp := g.GenerateNameBySample("thriftServer", append(m.Parameters, m.Results...))
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("%s implements the thrift %s service.", m.Name, utils.ToCamelCase(g.name)),
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				m.Name,
+				jen.Id(stp).Id("*thriftServer"),
+				[]jen.Code{
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("req").Id("*").Qual(genImport, m.Name+"Request"),
+				},
+				[]jen.Code{
+					jen.Id("*").Qual(genImport, m.Name+"Reply"),
+					jen.Error(),
+				},
+				"",
+				jen.List(jen.Id("request"), jen.Err()).Op(":=").Id(fmt.Sprintf("decode%sRequest", m.Name)).Call(
+					jen.Id("ctx"),
+					jen.Id("req"),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Nil(), jen.Err()),
+				),
+				jen.List(jen.Id("response"), jen.Err()).Op(":=").Id(stp).Dot("endpoints").Dot(m.Name+"Endpoint").Call(
+					jen.Id("ctx"),
+					jen.Id("request"),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Nil(), jen.Err()),
+				),
+				jen.Return(jen.Id(fmt.Sprintf("encode%sResponse", m.Name)).Call(
+					jen.Id("ctx"),
+					jen.Id("response"),
+				)),
+			)
+			g.code.NewLine()
+		}
+	}
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	// See if we need to add any new import
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	s, err := g.MergeSource(g.destPath, src, g.code.Raw().GoString(), imp)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+// natsSubject returns the subject the method of the service is served on.
+func natsSubject(name, method string) string {
+	return utils.ToLowerSnakeCase(name) + "." + utils.ToLowerSnakeCase(method)
+}
+
+type generateNATSTransport struct {
+	BaseGenerator
+	name              string
+	interfaceName     string
+	destPath          string
+	generateFirstTime bool
+	file              *parser.File
+	filePath          string
+	serviceInterface  parser.Interface
+}
+
+func newGenerateNATSTransport(name string, serviceInterface parser.Interface) Gen {
+	t := &generateNATSTransport{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_nats_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_nats_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateNATSTransport) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	endpImports, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("nats")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	errorEncoderFound := false
+	errorDecoderFound := false
+	errorWrapperFound := false
+	for _, v := range g.file.Methods {
+		if v.Name == "ErrorEncoder" {
+			errorEncoderFound = true
+		}
+		if v.Name == "ErrorDecoder" {
+			errorDecoderFound = true
+		}
+	}
+	for _, v := range g.file.Structures {
+		if v.Name == "errorWrapper" {
+			errorWrapperFound = true
+		}
+	}
+	for _, m := range g.serviceInterface.Methods {
+		hasError := false
+		for _, v := range m.Results {
+			if v.Type == "error" {
+				hasError = true
+			}
+		}
+		decoderFound := false
+		encoderFound := false
+		handlerFound := false
+		for _, v := range g.file.Methods {
+			if v.Name == fmt.Sprintf("decode%sRequest", m.Name) {
+				decoderFound = true
+			}
+			if v.Name == fmt.Sprintf("encode%sResponse", m.Name) {
+				encoderFound = true
+			}
+			if v.Name == fmt.Sprintf("make%sHandler", m.Name) {
+				handlerFound = true
+			}
+		}
+		if !handlerFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("make%sHandler creates the subscriber of the `%s` subject", m.Name, natsSubject(g.name, m.Name)),
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("make%sHandler", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("subscribers").Map(jen.String()).Id("*").Qual("github.com/go-kit/kit/transport/nats", "Subscriber"),
+					jen.Id("endpoints").Qual(endpImports, "Endpoints"),
+					jen.Id("options").Index().Qual(
+						"github.com/go-kit/kit/transport/nats",
+						"SubscriberOption",
+					),
+				},
+				[]jen.Code{},
+				"",
+				jen.Id("subscribers").Index(jen.Lit(natsSubject(g.name, m.Name))).Op("=").Qual(
+					"github.com/go-kit/kit/transport/nats", "NewSubscriber",
+				).Call(
+					jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
+					jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
+					jen.Id(fmt.Sprintf("encode%sResponse", m.Name)),
+					jen.Id("options..."),
+				),
+			)
+			g.code.NewLine()
+		}
+		if !decoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sRequest is a transport/nats.DecodeRequestFunc that decodes a", m.Name),
+				"JSON-encoded request from the NATS message data.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("msg").Id("*").Qual("github.com/nats-io/nats.go", "Msg"),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				jen.Id("req").Op(":=").Qual(endpImports, m.Name+"Request").Block(),
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+					jen.Id("msg").Dot("Data"),
+					jen.Id("&req"),
+				),
+				jen.Return(jen.Id("req"), jen.Id("err")),
+			)
+			g.code.NewLine()
+		}
+		if !encoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sResponse is a transport/nats.EncodeResponseFunc that publishes", m.Name),
+				"the response as JSON to the reply subject",
+			})
+			g.code.NewLine()
+			pt := []jen.Code{}
+			if hasError {
+				pt = append(
+					pt,
+					jen.If(
+						jen.List(jen.Id("f"), jen.Id("ok")).Op(":=").Id("response.").Call(
+							jen.Qual(endpImports, "Failure"),
+						).Id(";").Id("ok").Id("&&").Id("f").Dot("Failed").Call().Op("!=").Nil(),
+					).Block(
+						jen.Id("ErrorEncoder").Call(
+							jen.Id("ctx"),
+							jen.Id("f").Dot("Failed").Call(),
+							jen.Id("reply"),
+							jen.Id("nc"),
+						),
+						jen.Return(jen.Nil()),
+					),
+				)
+			}
+			pt = append(
+				pt,
+				jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(
+					jen.Id("response"),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Return(jen.Err()),
+				),
+				jen.Return(jen.Id("nc").Dot("Publish").Call(jen.Id("reply"), jen.Id("b"))),
+			)
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sResponse", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("reply").String(),
+					jen.Id("nc").Id("*").Qual("github.com/nats-io/nats.go", "Conn"),
+					jen.Id("response").Interface(),
+				},
+				[]jen.Code{},
+				"error",
+				pt...,
+			)
+			g.code.NewLine()
+		}
+	}
+	if !errorEncoderFound {
+		g.code.appendMultilineComment([]string{
+			"ErrorEncoder publishes the error as JSON to the reply subject.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ErrorEncoder",
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("err").Error(),
+				jen.Id("reply").String(),
+				jen.Id("nc").Id("*").Qual("github.com/nats-io/nats.go", "Conn"),
+			},
+			[]jen.Code{},
+			"",
+			jen.List(jen.Id("b"), jen.Id("_")).Op(":=").Qual("encoding/json", "Marshal").Call(
+				jen.Id("errorWrapper").Values(
+					jen.Dict{
+						jen.Id("Error"): jen.Err().Dot("Error").Call(),
+					},
+				),
+			),
+			jen.Id("nc").Dot("Publish").Call(jen.Id("reply"), jen.Id("b")),
+		)
+		g.code.NewLine()
+	}
+	if !errorDecoderFound {
+		g.code.appendMultilineComment([]string{
+			"ErrorDecoder returns the error published by ErrorEncoder, or nil if the",
+			"message is not an error.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ErrorDecoder",
+			nil,
+			[]jen.Code{
+				jen.Id("msg").Id("*").Qual("github.com/nats-io/nats.go", "Msg"),
+			},
+			[]jen.Code{},
+			"error",
+			jen.Var().Id("w").Id("errorWrapper"),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+					jen.Id("msg").Dot("Data"),
+					jen.Id("&w"),
+				).Id(";").Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.If(jen.Id("w").Dot("Error").Op("==").Lit("")).Block(
+				jen.Return(jen.Nil()),
+			),
+			jen.Return(jen.Qual("errors", "New").Call(jen.Id("w").Dot("Error"))),
+		)
+		g.code.NewLine()
+	}
+	if !errorWrapperFound {
+		g.code.Raw().Type().Id("errorWrapper").Struct(
+			jen.Id("Error").String().Tag(
+				map[string]string{
+					"json": "error",
+				},
+			),
+		)
+		g.code.NewLine()
+	}
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	// See if we need to add any new import
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	s, err := g.MergeSource(g.destPath, src, g.code.Raw().GoString(), imp)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+type generateNATSTransportBase struct {
+	BaseGenerator
+	name             string
+	allMethods       []parser.Method
+	interfaceName    string
+	destPath         string
+	filePath         string
+	file             *parser.File
+	natsFilePath     string
+	serviceInterface parser.Interface
+}
+
+func newGenerateNATSTransportBase(name string, serviceInterface parser.Interface, allMethods []parser.Method) Gen {
+	t := &generateNATSTransportBase{
+		name:             name,
+		allMethods:       allMethods,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_nats_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_nats_base_file_name"))
+	t.natsFilePath = path.Join(t.destPath, viper.GetString("gk_nats_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateNATSTransportBase) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	src, err := g.fs.ReadFile(g.natsFilePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	handles := []jen.Code{
+		jen.Id("subscribers").Op(":=").Map(jen.String()).Id("*").Qual(
+			"github.com/go-kit/kit/transport/nats", "Subscriber",
+		).Values(),
+	}
+	for _, m := range g.allMethods {
+		for _, v := range g.file.Methods {
+			if v.Name == "make"+m.Name+"Handler" {
+				handles = append(
+					handles,
+					jen.Id("make"+m.Name+"Handler").Call(
+						jen.Id("subscribers"),
+						jen.Id("endpoints"),
+						jen.Id("options").Index(jen.Lit(m.Name)),
+					),
+				)
+			}
+		}
+	}
+	handles = append(handles, jen.Return(jen.Id("subscribers")))
+	g.code.appendMultilineComment([]string{
+		"NewNATSHandler returns the subscribers that make a set of endpoints available",
+		"on predefined subjects, keyed by subject.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewNATSHandler",
+		nil,
+		[]jen.Code{
+			jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
+			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/nats", "SubscriberOption"),
+		},
+		[]jen.Code{
+			jen.Map(jen.String()).Id("*").Qual("github.com/go-kit/kit/transport/nats", "Subscriber"),
+		},
+		"",
+		handles...,
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"Subscribe subscribes the subscribers to their subjects on the given connection.",
+		"Instances of the service should use the same queue so each request is handled once.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Subscribe",
+		nil,
+		[]jen.Code{
+			jen.Id("nc").Id("*").Qual("github.com/nats-io/nats.go", "Conn"),
+			jen.Id("queue").String(),
+			jen.Id("subscribers").Map(jen.String()).Id("*").Qual("github.com/go-kit/kit/transport/nats", "Subscriber"),
+		},
+		[]jen.Code{
+			jen.Index().Id("*").Qual("github.com/nats-io/nats.go", "Subscription"),
+			jen.Error(),
+		},
+		"",
+		jen.Id("subs").Op(":=").Index().Id("*").Qual("github.com/nats-io/nats.go", "Subscription").Values(),
+		jen.For(jen.List(jen.Id("subject"), jen.Id("s")).Op(":=").Range().Id("subscribers")).Block(
+			jen.List(jen.Id("sub"), jen.Err()).Op(":=").Id("nc").Dot("QueueSubscribe").Call(
+				jen.Id("subject"),
+				jen.Id("queue"),
+				jen.Id("s").Dot("ServeMsg").Call(jen.Id("nc")),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("subs")).Block(
+					jen.Id("v").Dot("Unsubscribe").Call(),
+				),
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Id("subs").Op("=").Append(jen.Id("subs"), jen.Id("sub")),
+		),
+		jen.Return(jen.Id("subs"), jen.Nil()),
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
+
+// amqpQueue returns the queue (and routing key) the method of the service is served on.
+func amqpQueue(name, method string) string {
+	return utils.ToLowerSnakeCase(name) + "." + utils.ToLowerSnakeCase(method)
+}
+
+type generateAMQPTransport struct {
+	BaseGenerator
+	name              string
+	interfaceName     string
+	destPath          string
+	generateFirstTime bool
+	file              *parser.File
+	filePath          string
+	serviceInterface  parser.Interface
+}
+
+func newGenerateAMQPTransport(name string, serviceInterface parser.Interface) Gen {
+	t := &generateAMQPTransport{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_amqp_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_amqp_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateAMQPTransport) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	endpImports, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		g.generateFirstTime = true
+		f := jen.NewFile("amqp")
+		g.fs.WriteFile(g.filePath, f.GoString(), false)
+	}
+	src, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	errorEncoderFound := false
+	errorDecoderFound := false
+	errorWrapperFound := false
+	for _, v := range g.file.Methods {
+		if v.Name == "ErrorEncoder" {
+			errorEncoderFound = true
+		}
+		if v.Name == "ErrorDecoder" {
+			errorDecoderFound = true
+		}
+	}
+	for _, v := range g.file.Structures {
+		if v.Name == "errorWrapper" {
+			errorWrapperFound = true
+		}
+	}
+	for _, m := range g.serviceInterface.Methods {
+		hasError := false
+		for _, v := range m.Results {
+			if v.Type == "error" {
+				hasError = true
+			}
+		}
+		decoderFound := false
+		encoderFound := false
+		handlerFound := false
+		for _, v := range g.file.Methods {
+			if v.Name == fmt.Sprintf("decode%sRequest", m.Name) {
+				decoderFound = true
+			}
+			if v.Name == fmt.Sprintf("encode%sResponse", m.Name) {
+				encoderFound = true
+			}
+			if v.Name == fmt.Sprintf("make%sHandler", m.Name) {
+				handlerFound = true
+			}
+		}
+		if !handlerFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("make%sHandler creates the subscriber of the `%s` queue", m.Name, amqpQueue(g.name, m.Name)),
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("make%sHandler", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("subscribers").Map(jen.String()).Id("*").Qual("github.com/go-kit/kit/transport/amqp", "Subscriber"),
+					jen.Id("endpoints").Qual(endpImports, "Endpoints"),
+					jen.Id("options").Index().Qual(
+						"github.com/go-kit/kit/transport/amqp",
+						"SubscriberOption",
+					),
+				},
+				[]jen.Code{},
+				"",
+				jen.Id("subscribers").Index(jen.Lit(amqpQueue(g.name, m.Name))).Op("=").Qual(
+					"github.com/go-kit/kit/transport/amqp", "NewSubscriber",
+				).Call(
+					jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
+					jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
+					jen.Id(fmt.Sprintf("encode%sResponse", m.Name)),
+					jen.Id("options..."),
+				),
+			)
+			g.code.NewLine()
+		}
+		if !decoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("decode%sRequest is a transport/amqp.DecodeRequestFunc that decodes a", m.Name),
+				"JSON-encoded request from the AMQP delivery body.",
+			})
+			g.code.NewLine()
+			g.code.appendFunction(
+				fmt.Sprintf("decode%sRequest", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("d").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Delivery"),
+				},
+				[]jen.Code{
+					jen.Interface(),
+					jen.Error(),
+				},
+				"",
+				jen.Id("req").Op(":=").Qual(endpImports, m.Name+"Request").Block(),
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+					jen.Id("d").Dot("Body"),
+					jen.Id("&req"),
+				),
+				jen.Return(jen.Id("req"), jen.Id("err")),
+			)
+			g.code.NewLine()
+		}
+		if !encoderFound {
+			g.code.appendMultilineComment([]string{
+				fmt.Sprintf("encode%sResponse is a transport/amqp.EncodeResponseFunc that encodes", m.Name),
+				"the response as JSON to the reply publishing",
+			})
+			g.code.NewLine()
+			pt := []jen.Code{}
+			if hasError {
+				pt = append(
+					pt,
+					jen.If(
+						jen.List(jen.Id("f"), jen.Id("ok")).Op(":=").Id("response.").Call(
+							jen.Qual(endpImports, "Failure"),
+						).Id(";").Id("ok").Id("&&").Id("f").Dot("Failed").Call().Op("!=").Nil(),
+					).Block(
+						jen.Return(jen.Id("f").Dot("Failed").Call()),
+					),
+				)
+			}
+			pt = append(
+				pt,
+				jen.Id("p").Dot("ContentType").Op("=").Lit("application/json"),
+				jen.List(jen.Id("p").Dot("Body"), jen.Err()).Op("=").Qual("encoding/json", "Marshal").Call(
+					jen.Id("response"),
+				),
+				jen.Return(),
+			)
+			g.code.appendFunction(
+				fmt.Sprintf("encode%sResponse", m.Name),
+				nil,
+				[]jen.Code{
+					jen.Id("_").Qual("context", "Context"),
+					jen.Id("p").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Publishing"),
+					jen.Id("response").Interface(),
+				},
+				[]jen.Code{
+					jen.Id("err").Error(),
+				},
+				"",
+				pt...,
+			)
+			g.code.NewLine()
+		}
+	}
+	if !errorEncoderFound {
+		g.code.appendMultilineComment([]string{
+			"ErrorEncoder publishes the error as JSON to the reply queue of the delivery.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ErrorEncoder",
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("err").Error(),
+				jen.Id("d").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Delivery"),
+				jen.Id("ch").Qual("github.com/go-kit/kit/transport/amqp", "Channel"),
+				jen.Id("p").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Publishing"),
+			},
+			[]jen.Code{},
+			"",
+			jen.Id("p").Dot("CorrelationId").Op("=").Id("d").Dot("CorrelationId"),
+			jen.Id("p").Dot("ContentType").Op("=").Lit("application/json"),
+			jen.List(jen.Id("p").Dot("Body"), jen.Id("_")).Op("=").Qual("encoding/json", "Marshal").Call(
+				jen.Id("errorWrapper").Values(
+					jen.Dict{
+						jen.Id("Error"): jen.Err().Dot("Error").Call(),
+					},
+				),
+			),
+			jen.Id("ch").Dot("Publish").Call(
+				jen.Lit(""),
+				jen.Id("d").Dot("ReplyTo"),
+				jen.False(),
+				jen.False(),
+				jen.Id("*p"),
+			),
+		)
+		g.code.NewLine()
+	}
+	if !errorDecoderFound {
+		g.code.appendMultilineComment([]string{
+			"ErrorDecoder returns the error published by ErrorEncoder, or nil if the",
+			"delivery is not an error.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			"ErrorDecoder",
+			nil,
+			[]jen.Code{
+				jen.Id("d").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Delivery"),
+			},
+			[]jen.Code{},
+			"error",
+			jen.Var().Id("w").Id("errorWrapper"),
+			jen.If(
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+					jen.Id("d").Dot("Body"),
+					jen.Id("&w"),
+				).Id(";").Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.If(jen.Id("w").Dot("Error").Op("==").Lit("")).Block(
+				jen.Return(jen.Nil()),
+			),
+			jen.Return(jen.Qual("errors", "New").Call(jen.Id("w").Dot("Error"))),
+		)
+		g.code.NewLine()
+	}
+	if !errorWrapperFound {
+		g.code.Raw().Type().Id("errorWrapper").Struct(
+			jen.Id("Error").String().Tag(
+				map[string]string{
+					"json": "error",
+				},
+			),
+		)
+		g.code.NewLine()
+	}
+	if g.generateFirstTime {
+		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+	}
+	tmpSrc := g.srcFile.GoString()
+	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
+	if err != nil {
+		return err
+	}
+	// See if we need to add any new import
+	imp, err := g.getMissingImports(f.Imports, g.file)
+	if err != nil {
+		return err
+	}
+	s, err := g.MergeSource(g.destPath, src, g.code.Raw().GoString(), imp)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, s, true)
+}
+
+type generateAMQPTransportBase struct {
+	BaseGenerator
+	name             string
+	allMethods       []parser.Method
+	interfaceName    string
+	destPath         string
+	filePath         string
+	file             *parser.File
+	amqpFilePath     string
+	serviceInterface parser.Interface
+}
+
+func newGenerateAMQPTransportBase(name string, serviceInterface parser.Interface, allMethods []parser.Method) Gen {
+	t := &generateAMQPTransportBase{
+		name:             name,
+		allMethods:       allMethods,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_amqp_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_amqp_base_file_name"))
+	t.amqpFilePath = path.Join(t.destPath, viper.GetString("gk_amqp_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateAMQPTransportBase) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	src, err := g.fs.ReadFile(g.amqpFilePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	amqpPkg := "github.com/rabbitmq/amqp091-go"
+	g.code.appendMultilineComment([]string{
+		"Channel is the part of *amqp.Channel used by the transport, it can be",
+		"replaced with a fake channel in tests.",
+	})
+	g.code.NewLine()
+	g.code.Raw().Type().Id("Channel").Interface(
+		jen.Qual("github.com/go-kit/kit/transport/amqp", "Channel"),
+		jen.Id("ExchangeDeclare").Params(
+			jen.List(jen.Id("name"), jen.Id("kind")).String(),
+			jen.List(jen.Id("durable"), jen.Id("autoDelete"), jen.Id("internal"), jen.Id("noWait")).Bool(),
+			jen.Id("args").Qual(amqpPkg, "Table"),
+		).Error(),
+		jen.Id("QueueDeclare").Params(
+			jen.Id("name").String(),
+			jen.List(jen.Id("durable"), jen.Id("autoDelete"), jen.Id("exclusive"), jen.Id("noWait")).Bool(),
+			jen.Id("args").Qual(amqpPkg, "Table"),
+		).Params(jen.Qual(amqpPkg, "Queue"), jen.Error()),
+		jen.Id("QueueBind").Params(
+			jen.List(jen.Id("name"), jen.Id("key"), jen.Id("exchange")).String(),
+			jen.Id("noWait").Bool(),
+			jen.Id("args").Qual(amqpPkg, "Table"),
+		).Error(),
+	)
+	g.code.NewLine()
+	handles := []jen.Code{
+		jen.Id("subscribers").Op(":=").Map(jen.String()).Id("*").Qual(
+			"github.com/go-kit/kit/transport/amqp", "Subscriber",
+		).Values(),
+	}
+	for _, m := range g.allMethods {
+		for _, v := range g.file.Methods {
+			if v.Name == "make"+m.Name+"Handler" {
+				handles = append(
+					handles,
+					jen.Id("make"+m.Name+"Handler").Call(
+						jen.Id("subscribers"),
+						jen.Id("endpoints"),
+						jen.Id("options").Index(jen.Lit(m.Name)),
+					),
+				)
 			}
-			fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/transport/grpc", "Handler"))
-		}
-	} else {
-		for _, m := range g.serviceInterface.Methods {
-			n := utils.ToLowerFirstCamelCase(m.Name)
-			vl[jen.Id(n)] = jen.Id("make"+m.Name+"Handler").Call(
-				jen.Id("endpoints"),
-				jen.Id("options").Index(jen.Lit(m.Name)),
-			)
-			fields = append(fields, jen.Id(n).Qual("github.com/go-kit/kit/transport/grpc", "Handler"))
 		}
 	}
-	g.code.appendStruct("grpcServer", fields...)
+	handles = append(handles, jen.Return(jen.Id("subscribers")))
+	g.code.appendMultilineComment([]string{
+		"NewAMQPHandler returns the subscribers that make a set of endpoints available",
+		"on predefined queues, keyed by queue.",
+	})
 	g.code.NewLine()
 	g.code.appendFunction(
-		"NewGRPCServer",
+		"NewAMQPHandler",
 		nil,
 		[]jen.Code{
 			jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
-			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/grpc", "ServerOption"),
+			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/amqp", "SubscriberOption"),
 		},
 		[]jen.Code{
-			jen.Qual(pbImport, utils.ToCamelCase(g.name)+"Server"),
+			jen.Map(jen.String()).Id("*").Qual("github.com/go-kit/kit/transport/amqp", "Subscriber"),
 		},
 		"",
-		jen.Return(jen.Id("&grpcServer").Values(vl)),
+		handles...,
+	)
+	g.code.NewLine()
+	exchange := utils.ToLowerSnakeCase(g.name)
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("Subscribe declares the `%s` exchange and binds the queue of each subscriber to it,", exchange),
+		"the deliveries of each queue are served by its subscriber.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"Subscribe",
+		nil,
+		[]jen.Code{
+			jen.Id("ch").Id("Channel"),
+			jen.Id("subscribers").Map(jen.String()).Id("*").Qual("github.com/go-kit/kit/transport/amqp", "Subscriber"),
+		},
+		[]jen.Code{},
+		"error",
+		jen.If(
+			jen.Err().Op(":=").Id("ch").Dot("ExchangeDeclare").Call(
+				jen.Lit(exchange), jen.Lit("direct"), jen.True(), jen.False(), jen.False(), jen.False(), jen.Nil(),
+			).Id(";").Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.For(jen.List(jen.Id("queue"), jen.Id("s")).Op(":=").Range().Id("subscribers")).Block(
+			jen.List(jen.Id("q"), jen.Err()).Op(":=").Id("ch").Dot("QueueDeclare").Call(
+				jen.Id("queue"), jen.True(), jen.False(), jen.False(), jen.False(), jen.Nil(),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.If(
+				jen.Err().Op(":=").Id("ch").Dot("QueueBind").Call(
+					jen.Id("q").Dot("Name"), jen.Id("queue"), jen.Lit(exchange), jen.False(), jen.Nil(),
+				).Id(";").Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.List(jen.Id("deliveries"), jen.Err()).Op(":=").Id("ch").Dot("Consume").Call(
+				jen.Id("q").Dot("Name"), jen.Lit(""), jen.True(), jen.False(), jen.False(), jen.False(), jen.Nil(),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Err()),
+			),
+			jen.Go().Func().Params(
+				jen.Id("handler").Func().Params(jen.Id("*").Qual(amqpPkg, "Delivery")),
+				jen.Id("deliveries").Op("<-").Chan().Qual(amqpPkg, "Delivery"),
+			).Block(
+				jen.For(jen.Id("d").Op(":=").Range().Id("deliveries")).Block(
+					jen.Id("handler").Call(jen.Id("&d")),
+				),
+			).Call(
+				jen.Id("s").Dot("ServeDelivery").Call(jen.Id("ch")),
+				jen.Id("deliveries"),
+			),
+		),
+		jen.Return(jen.Nil()),
 	)
 	g.code.NewLine()
 	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
 }
 
-type generateGRPCTransport struct {
+// jsonRPCMethod returns the JSON-RPC method name of the service method.
+func jsonRPCMethod(method string) string {
+	return utils.ToLowerFirstCamelCase(method)
+}
+
+type generateJSONRPCTransport struct {
 	BaseGenerator
 	name              string
-	methods           []string
 	interfaceName     string
 	destPath          string
 	generateFirstTime bool
@@ -969,21 +3926,20 @@ type generateGRPCTransport struct {
 	serviceInterface  parser.Interface
 }
 
-func newGenerateGRPCTransport(name string, serviceInterface parser.Interface, methods []string) Gen {
-	t := &generateGRPCTransport{
+func newGenerateJSONRPCTransport(name string, serviceInterface parser.Interface) Gen {
+	t := &generateJSONRPCTransport{
 		name:             name,
-		methods:          methods,
 		interfaceName:    utils.ToCamelCase(name + "Service"),
-		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_path_format"), utils.ToLowerSnakeCase(name)),
+		destPath:         fmt.Sprintf(viper.GetString("gk_jsonrpc_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceInterface: serviceInterface,
 	}
-	t.filePath = path.Join(t.destPath, viper.GetString("gk_grpc_file_name"))
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_jsonrpc_file_name"))
 	t.srcFile = jen.NewFilePath(t.destPath)
 	t.InitPg()
 	t.fs = fs.Get()
 	return t
 }
-func (g *generateGRPCTransport) Generate() (err error) {
+func (g *generateJSONRPCTransport) Generate() (err error) {
 	err = g.CreateFolderStructure(g.destPath)
 	if err != nil {
 		return err
@@ -992,15 +3948,11 @@ func (g *generateGRPCTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	pbImport, err := utils.GetPbImportPath(g.name)
-	if err != nil {
-		return err
-	}
 	if b, err := g.fs.Exists(g.filePath); err != nil {
 		return err
 	} else if !b {
 		g.generateFirstTime = true
-		f := jen.NewFile("grpc")
+		f := jen.NewFile("jsonrpc")
 		g.fs.WriteFile(g.filePath, f.GoString(), false)
 	}
 	src, err := g.fs.ReadFile(g.filePath)
@@ -1012,10 +3964,15 @@ func (g *generateGRPCTransport) Generate() (err error) {
 		return err
 	}
 	for _, m := range g.serviceInterface.Methods {
+		hasError := false
+		for _, v := range m.Results {
+			if v.Type == "error" {
+				hasError = true
+			}
+		}
 		decoderFound := false
 		encoderFound := false
-		handlerFound := false
-		funcFound := false
+		codecFound := false
 		for _, v := range g.file.Methods {
 			if v.Name == fmt.Sprintf("decode%sRequest", m.Name) {
 				decoderFound = true
@@ -1023,50 +3980,38 @@ func (g *generateGRPCTransport) Generate() (err error) {
 			if v.Name == fmt.Sprintf("encode%sResponse", m.Name) {
 				encoderFound = true
 			}
-			if v.Name == fmt.Sprintf("make%sHandler", m.Name) {
-				handlerFound = true
-			}
-			if v.Name == m.Name && v.Struct.Type == "*grpcServer" {
-				funcFound = true
+			if v.Name == fmt.Sprintf("make%sCodec", m.Name) {
+				codecFound = true
 			}
 		}
-		if !handlerFound {
+		if !codecFound {
 			g.code.appendMultilineComment([]string{
-				fmt.Sprintf("make%sHandler creates the handler logic", m.Name),
+				fmt.Sprintf("make%sCodec adds the codec of the `%s` JSON-RPC method", m.Name, jsonRPCMethod(m.Name)),
 			})
 			g.code.NewLine()
 			g.code.appendFunction(
-				fmt.Sprintf("make%sHandler", m.Name),
+				fmt.Sprintf("make%sCodec", m.Name),
 				nil,
 				[]jen.Code{
+					jen.Id("ecm").Qual("github.com/go-kit/kit/transport/http/jsonrpc", "EndpointCodecMap"),
 					jen.Id("endpoints").Qual(endpImports, "Endpoints"),
-					jen.Id("options").Index().Qual(
-						"github.com/go-kit/kit/transport/grpc",
-						"ServerOption",
-					),
-				},
-				[]jen.Code{
-					jen.Qual("github.com/go-kit/kit/transport/grpc", "Handler"),
 				},
+				[]jen.Code{},
 				"",
-				jen.Return(
-					jen.Qual("github.com/go-kit/kit/transport/grpc", "NewServer").Call(
-						jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
-						jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
-						jen.Id(fmt.Sprintf("encode%sResponse", m.Name)),
-						jen.Id("options..."),
-					),
-				),
+				jen.Id("ecm").Index(jen.Lit(jsonRPCMethod(m.Name))).Op("=").Qual(
+					"github.com/go-kit/kit/transport/http/jsonrpc", "EndpointCodec",
+				).Values(jen.Dict{
+					jen.Id("Endpoint"): jen.Id(fmt.Sprintf("endpoints.%sEndpoint", m.Name)),
+					jen.Id("Decode"):   jen.Id(fmt.Sprintf("decode%sRequest", m.Name)),
+					jen.Id("Encode"):   jen.Id(fmt.Sprintf("encode%sResponse", m.Name)),
+				}),
 			)
 			g.code.NewLine()
-
 		}
-
 		if !decoderFound {
 			g.code.appendMultilineComment([]string{
-				fmt.Sprintf("decode%sResponse is a transport/grpc.DecodeRequestFunc that converts a", m.Name),
-				fmt.Sprintf("gRPC request to a user-domain %s request.", m.Name),
-				"TODO implement the decoder",
+				fmt.Sprintf("decode%sRequest is a transport/http/jsonrpc.DecodeRequestFunc that decodes", m.Name),
+				"the JSON-RPC params to the endpoint request.",
 			})
 			g.code.NewLine()
 			g.code.appendFunction(
@@ -1074,80 +4019,59 @@ func (g *generateGRPCTransport) Generate() (err error) {
 				nil,
 				[]jen.Code{
 					jen.Id("_").Qual("context", "Context"),
-					jen.Id("r").Interface(),
+					jen.Id("params").Qual("encoding/json", "RawMessage"),
 				},
 				[]jen.Code{
 					jen.Interface(),
 					jen.Error(),
 				},
 				"",
-				jen.Return(
-					jen.Nil(), jen.Qual("errors", "New").Call(
-						jen.Lit(fmt.Sprintf("'%s' Decoder is not impelemented", utils.ToCamelCase(g.name))),
-					),
+				jen.Id("req").Op(":=").Qual(endpImports, m.Name+"Request").Block(),
+				jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+					jen.Id("params"),
+					jen.Id("&req"),
 				),
+				jen.Return(jen.Id("req"), jen.Id("err")),
 			)
 			g.code.NewLine()
 		}
 		if !encoderFound {
 			g.code.appendMultilineComment([]string{
-				fmt.Sprintf("encode%sResponse is a transport/grpc.EncodeResponseFunc that converts", m.Name),
-				"a user-domain response to a gRPC reply.",
-				"TODO implement the encoder",
+				fmt.Sprintf("encode%sResponse is a transport/http/jsonrpc.EncodeResponseFunc that encodes", m.Name),
+				"the endpoint response to the JSON-RPC result, failed responses are returned",
+				"as JSON-RPC errors.",
 			})
 			g.code.NewLine()
+			pt := []jen.Code{}
+			if hasError {
+				pt = append(
+					pt,
+					jen.If(
+						jen.List(jen.Id("f"), jen.Id("ok")).Op(":=").Id("response.").Call(
+							jen.Qual(endpImports, "Failure"),
+						).Id(";").Id("ok").Id("&&").Id("f").Dot("Failed").Call().Op("!=").Nil(),
+					).Block(
+						jen.Return(jen.Nil(), jen.Id("f").Dot("Failed").Call()),
+					),
+				)
+			}
+			pt = append(
+				pt,
+				jen.Return(jen.Qual("encoding/json", "Marshal").Call(jen.Id("response"))),
+			)
 			g.code.appendFunction(
 				fmt.Sprintf("encode%sResponse", m.Name),
 				nil,
 				[]jen.Code{
 					jen.Id("_").Qual("context", "Context"),
-					jen.Id("r").Interface(),
-				},
-				[]jen.Code{
-					jen.Interface(),
-					jen.Error(),
-				},
-				"",
-				jen.Return(
-					jen.Nil(), jen.Qual("errors", "New").Call(
-						jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
-					),
-				),
-			)
-			g.code.NewLine()
-		}
-		if !funcFound {
-			stp := g.GenerateNameBySample("grpcServer", append(m.Parameters, m.Results...))
-			n := utils.ToCamelCase(m.Name)
-			g.code.appendFunction(
-				n,
-				jen.Id(stp).Id("*grpcServer"),
-				[]jen.Code{
-					jen.Id("ctx").Qual("golang.org/x/net/context", "Context"),
-					jen.Id("req").Id("*").Qual(pbImport, n+"Request"),
+					jen.Id("response").Interface(),
 				},
 				[]jen.Code{
-					jen.Id("*").Qual(pbImport, n+"Reply"),
+					jen.Qual("encoding/json", "RawMessage"),
 					jen.Error(),
 				},
 				"",
-				jen.List(
-					jen.Id("_"),
-					jen.Id("rep"),
-					jen.Err(),
-				).Op(":=").Id(stp).Dot(utils.ToLowerFirstCamelCase(m.Name)).Dot("ServeGRPC").Call(
-					jen.Id("ctx"),
-					jen.Id("req"),
-				),
-				jen.If(jen.Err().Op("!=").Nil()).Block(
-					jen.Return(jen.Nil(), jen.Err()),
-				),
-				jen.Return(
-					jen.Id("rep").Dot("").Call(
-						jen.Id("*").Qual(pbImport, n+"Reply"),
-					),
-					jen.Nil(),
-				),
+				pt...,
 			)
 			g.code.NewLine()
 		}
@@ -1156,7 +4080,6 @@ func (g *generateGRPCTransport) Generate() (err error) {
 		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
 	}
 	tmpSrc := g.srcFile.GoString()
-	src += "\n" + g.code.Raw().GoString()
 	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
 	if err != nil {
 		return err
@@ -1166,15 +4089,98 @@ func (g *generateGRPCTransport) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	if len(imp) > 0 {
-		src, err = g.AddImportsToFile(imp, src)
-		if err != nil {
-			return err
-		}
-	}
-	s, err := utils.GoImportsSource(g.destPath, src)
+	s, err := g.MergeSource(g.destPath, src, g.code.Raw().GoString(), imp)
 	if err != nil {
 		return err
 	}
 	return g.fs.WriteFile(g.filePath, s, true)
 }
+
+type generateJSONRPCTransportBase struct {
+	BaseGenerator
+	name             string
+	allMethods       []parser.Method
+	interfaceName    string
+	destPath         string
+	filePath         string
+	file             *parser.File
+	jsonRPCFilePath  string
+	serviceInterface parser.Interface
+}
+
+func newGenerateJSONRPCTransportBase(name string, serviceInterface parser.Interface, allMethods []parser.Method) Gen {
+	t := &generateJSONRPCTransportBase{
+		name:             name,
+		allMethods:       allMethods,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_jsonrpc_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+	}
+	t.filePath = path.Join(t.destPath, viper.GetString("gk_jsonrpc_base_file_name"))
+	t.jsonRPCFilePath = path.Join(t.destPath, viper.GetString("gk_jsonrpc_file_name"))
+	t.srcFile = jen.NewFilePath(t.destPath)
+	t.InitPg()
+	t.fs = fs.Get()
+	return t
+}
+func (g *generateJSONRPCTransportBase) Generate() (err error) {
+	err = g.CreateFolderStructure(g.destPath)
+	if err != nil {
+		return err
+	}
+	g.srcFile.PackageComment("THIS FILE IS AUTO GENERATED BY GK-CLI DO NOT EDIT!!")
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	src, err := g.fs.ReadFile(g.jsonRPCFilePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	body := []jen.Code{
+		jen.Id("ecm").Op(":=").Qual("github.com/go-kit/kit/transport/http/jsonrpc", "EndpointCodecMap").Values(),
+	}
+	for _, m := range g.allMethods {
+		for _, v := range g.file.Methods {
+			if v.Name == "make"+m.Name+"Codec" {
+				body = append(
+					body,
+					jen.Id("make"+m.Name+"Codec").Call(
+						jen.Id("ecm"),
+						jen.Id("endpoints"),
+					),
+				)
+			}
+		}
+	}
+	body = append(body, jen.Return(
+		jen.Qual("github.com/go-kit/kit/transport/http/jsonrpc", "NewServer").Call(
+			jen.Id("ecm"),
+			jen.Id("options..."),
+		),
+	))
+	g.code.appendMultilineComment([]string{
+		"NewJSONRPCHandler returns a handler that makes a set of endpoints available",
+		"as the methods of a JSON-RPC 2.0 service.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"NewJSONRPCHandler",
+		nil,
+		[]jen.Code{
+			jen.Id("endpoints").Qual(endpointImport, "Endpoints"),
+			jen.Id("options").Index().Qual("github.com/go-kit/kit/transport/http/jsonrpc", "ServerOption"),
+		},
+		[]jen.Code{
+			jen.Qual("net/http", "Handler"),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
+}
@@ -2,12 +2,14 @@ package generator
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/emicklei/proto"
 	"github.com/kujtimiihoxha/kit/fs"
 	"github.com/kujtimiihoxha/kit/parser"
+	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestNewGenerateTransport(t *testing.T) {
@@ -769,6 +771,140 @@ func Test_generateHTTPTransport_Generate(t *testing.T) {
 	}
 }
 
+func Test_generateHTTPTransport_routes(t *testing.T) {
+	setDefaults()
+	get := createTestMethod(
+		"GetUser",
+		[]parser.NamedTypeValue{
+			parser.NewNameType("id", "int"),
+			parser.NewNameType("verbose", "bool"),
+			parser.NewNameType("token", "string"),
+		},
+		[]parser.NamedTypeValue{
+			parser.NewNameType("err", "error"),
+		},
+	)
+	get.Comment = "@http GET /users/{id}\n@http-header token X-Auth-Token\n"
+	create := createTestMethod(
+		"CreateUser",
+		[]parser.NamedTypeValue{
+			parser.NewNameType("name", "string"),
+		},
+		[]parser.NamedTypeValue{
+			parser.NewNameType("err", "error"),
+		},
+	)
+	create.Comment = "CreateUser creates a user.\n@http POST /users\n@http-status 201\n"
+	svc := parser.NewInterface("HttpRouteSvcService", []parser.Method{get, create})
+	err := newGenerateHTTPTransport("http_route_svc", true, svc, nil).Generate()
+	Convey("Test if the annotated routes are generated", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := fs.Get().ReadFile("http_route_svc/pkg/http/handler.go")
+		So(f, ShouldContainSubstring, `m.Methods("GET").Path("/users/{id}")`)
+		So(f, ShouldContainSubstring, `if v := vars["id"]; v != "" {`)
+		So(f, ShouldContainSubstring, "x, err := strconv.ParseInt(v, 10, 0)")
+		So(f, ShouldContainSubstring, "req.Id = int(x)")
+		So(f, ShouldContainSubstring, `if v := q.Get("verbose"); v != "" {`)
+		So(f, ShouldContainSubstring, `req.Token = r.Header.Get("X-Auth-Token")`)
+		So(f, ShouldContainSubstring, `m.Methods("POST").Path("/users")`)
+		So(f, ShouldContainSubstring, "w.WriteHeader(http1.StatusCreated)")
+	})
+	Convey("Test if path variables need the gorilla mux", t, func() {
+		err := newGenerateHTTPTransport("http_route_mux_svc", false, svc, nil).Generate()
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func Test_newHTTPRoute(t *testing.T) {
+	method := func(comment string, params ...parser.NamedTypeValue) parser.Method {
+		m := createTestMethod("GetUser", params, nil)
+		m.Comment = comment
+		return m
+	}
+	tests := []struct {
+		name    string
+		method  parser.Method
+		want    httpRoute
+		wantErr bool
+	}{
+		{
+			name:   "Test default route",
+			method: method("GetUser returns a user.\n", parser.NewNameType("id", "int")),
+			want:   httpRoute{Method: "POST", Path: "/get-user", Status: 200, Body: true},
+		},
+		{
+			name: "Test annotated route",
+			method: method(
+				"@http get /users/{user_id}\n@http-query limit max\n@http-header token\n",
+				parser.NewNameType("userID", "int64"),
+				parser.NewNameType("limit", "int"),
+				parser.NewNameType("token", "string"),
+				parser.NewNameType("q", "string"),
+			),
+			want: httpRoute{
+				Method: "GET",
+				Path:   "/users/{user_id}",
+				Status: 200,
+				Params: []httpParam{
+					{Name: "UserID", Type: "int64", In: "path", Key: "user_id"},
+					{Name: "Limit", Type: "int", In: "query", Key: "max"},
+					{Name: "Token", Type: "string", In: "header", Key: "Token"},
+					{Name: "Q", Type: "string", In: "query", Key: "q"},
+				},
+				Annotated: true,
+			},
+		},
+		{
+			name:    "Test unknown path variable",
+			method:  method("@http GET /users/{id}\n", parser.NewNameType("name", "string")),
+			wantErr: true,
+		},
+		{
+			name:   "Test repeated query key",
+			method: method("@http GET /users\n", parser.NewNameType("ids", "[]int")),
+			want: httpRoute{
+				Method:    "GET",
+				Path:      "/users",
+				Status:    200,
+				Params:    []httpParam{{Name: "Ids", Type: "[]int", In: "query", Key: "ids"}},
+				Annotated: true,
+			},
+		},
+		{
+			name:    "Test unsupported query type",
+			method:  method("@http GET /users\n", parser.NewNameType("users", "[]User")),
+			wantErr: true,
+		},
+		{
+			name:    "Test unsupported header type",
+			method:  method("@http GET /users\n@http-header ids\n", parser.NewNameType("ids", "[]int")),
+			wantErr: true,
+		},
+		{
+			name:    "Test unsupported method",
+			method:  method("@http FETCH /users\n"),
+			wantErr: true,
+		},
+		{
+			name:    "Test invalid status",
+			method:  method("@http POST /users\n@http-status 404\n"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newHTTPRoute(tt.method)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newHTTPRoute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newHTTPRoute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_newGenerateHTTPTransportBase(t *testing.T) {
 	type args struct {
 		name             string
@@ -839,6 +975,7 @@ func Test_newGenerateGRPCTransportProto(t *testing.T) {
 		name             string
 		serviceInterface parser.Interface
 		methods          []string
+		structs          []parser.Struct
 	}
 	tests := []struct {
 		name string
@@ -849,7 +986,7 @@ func Test_newGenerateGRPCTransportProto(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := newGenerateGRPCTransportProto(tt.args.name, tt.args.serviceInterface, tt.args.methods); !reflect.DeepEqual(got, tt.want) {
+			if got := newGenerateGRPCTransportProto(tt.args.name, tt.args.serviceInterface, tt.args.methods, tt.args.structs); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("newGenerateGRPCTransportProto() = %v, want %v", got, tt.want)
 			}
 		})
@@ -1018,6 +1155,100 @@ func Test_generateGRPCTransportProto_getServiceRPC(t *testing.T) {
 	}
 }
 
+func Test_generateGRPCTransportProto_fields(t *testing.T) {
+	setDefaults()
+	svc := parser.NewInterface("ProtoSvcService", []parser.Method{
+		createTestMethod(
+			"Foo",
+			[]parser.NamedTypeValue{
+				parser.NewNameType("userID", "int"),
+				parser.NewNameType("tags", "[]string"),
+				parser.NewNameType("user", "*User"),
+			},
+			[]parser.NamedTypeValue{
+				parser.NewNameType("at", "time.Time"),
+				parser.NewNameType("counts", "map[string]int32"),
+				parser.NewNameType("err", "error"),
+			},
+		),
+	})
+	structs := []parser.Struct{
+		parser.NewStruct("User", []parser.NamedTypeValue{
+			parser.NewNameType("Name", "string"),
+			parser.NewNameType("secret", "string"),
+		}),
+	}
+	err := newGenerateGRPCTransportProto("proto_svc", svc, nil, structs).Generate()
+	Convey("Test if the proto messages are generated with fields", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := fs.Get().ReadFile("proto_svc/pkg/grpc/pb/proto_svc.proto")
+		// The formatter aligns the fields.
+		f = strings.Join(strings.Fields(f), " ")
+		So(f, ShouldContainSubstring, `import "google/protobuf/timestamp.proto";`)
+		So(f, ShouldContainSubstring, "int64 user_id = 1;")
+		So(f, ShouldContainSubstring, "repeated string tags = 2;")
+		So(f, ShouldContainSubstring, "User user = 3;")
+		So(f, ShouldContainSubstring, "google.protobuf.Timestamp at = 1;")
+		So(f, ShouldContainSubstring, "map <string,int32> counts = 2;")
+		So(f, ShouldContainSubstring, "string err = 3;")
+		So(f, ShouldContainSubstring, "string name = 1;")
+		So(f, ShouldNotContainSubstring, "secret")
+		Convey("Test if the field numbers are kept when the proto is regenerated", func() {
+			svc.Methods[0].Parameters = []parser.NamedTypeValue{
+				parser.NewNameType("name", "string"),
+				parser.NewNameType("tags", "[]string"),
+			}
+			err = newGenerateGRPCTransportProto("proto_svc", svc, nil, structs).Generate()
+			So(err, ShouldBeNil)
+			f, _ := fs.Get().ReadFile("proto_svc/pkg/grpc/pb/proto_svc.proto")
+			f = strings.Join(strings.Fields(f), " ")
+			So(f, ShouldContainSubstring, "repeated string tags = 2;")
+			So(f, ShouldContainSubstring, "string name = 4;")
+			So(strings.Count(f, `import "google/protobuf/timestamp.proto";`), ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_protoType(t *testing.T) {
+	tests := []struct {
+		name         string
+		tp           string
+		wantTyp      string
+		wantKey      string
+		wantRepeated bool
+	}{
+		{"String", "string", "string", "", false},
+		{"Int", "int", "int64", "", false},
+		{"Float", "float32", "float", "", false},
+		{"Bytes", "[]byte", "bytes", "", false},
+		{"Error", "error", "string", "", false},
+		{"Pointer", "*bool", "bool", "", false},
+		{"Slice", "[]uint16", "uint32", "", true},
+		{"Variadic", "...string", "string", "", true},
+		{"Map", "map[int]string", "string", "int64", false},
+		{"Time", "time.Time", "google.protobuf.Timestamp", "", false},
+		{"Duration", "time.Duration", "int64", "", false},
+		{"Duration slice", "[]time.Duration", "int64", "", true},
+		{"Struct", "[]*User", "User", "", true},
+		{"Nested slices", "[][]string", "bytes", "", false},
+		{"Float map key", "map[float64]string", "bytes", "", false},
+		{"Unknown", "io.Reader", "bytes", "", false},
+		{"Unknown slice", "[]io.Reader", "bytes", "", false},
+		{"Unknown map value", "map[string]io.Reader", "bytes", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, key, repeated := protoType(tt.tp, []parser.Struct{parser.NewStruct("User", nil)})
+			if typ != tt.wantTyp || key != tt.wantKey || repeated != tt.wantRepeated {
+				t.Errorf(
+					"protoType() = %v, %v, %v, want %v, %v, %v",
+					typ, key, repeated, tt.wantTyp, tt.wantKey, tt.wantRepeated,
+				)
+			}
+		})
+	}
+}
+
 func Test_newGenerateGRPCTransportBase(t *testing.T) {
 	type args struct {
 		name             string
@@ -1087,6 +1318,7 @@ func Test_newGenerateGRPCTransport(t *testing.T) {
 		name             string
 		serviceInterface parser.Interface
 		methods          []string
+		structs          []parser.Struct
 	}
 	tests := []struct {
 		name string
@@ -1097,7 +1329,7 @@ func Test_newGenerateGRPCTransport(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := newGenerateGRPCTransport(tt.args.name, tt.args.serviceInterface, tt.args.methods); !reflect.DeepEqual(got, tt.want) {
+			if got := newGenerateGRPCTransport(tt.args.name, tt.args.serviceInterface, tt.args.methods, tt.args.structs); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("newGenerateGRPCTransport() = %v, want %v", got, tt.want)
 			}
 		})
@@ -1142,3 +1374,287 @@ func Test_generateGRPCTransport_Generate(t *testing.T) {
 		})
 	}
 }
+
+func Test_generateGRPCTransport_conversions(t *testing.T) {
+	setDefaults()
+	svc := parser.NewInterface("GrpcConvSvcService", []parser.Method{
+		createTestMethod(
+			"Foo",
+			[]parser.NamedTypeValue{
+				parser.NewNameType("s", "string"),
+				parser.NewNameType("n", "int"),
+				parser.NewNameType("users", "[]User"),
+				parser.NewNameType("d", "time.Duration"),
+			},
+			[]parser.NamedTypeValue{
+				parser.NewNameType("at", "*time.Time"),
+				parser.NewNameType("took", "[]time.Duration"),
+				parser.NewNameType("err", "error"),
+			},
+		),
+	})
+	structs := []parser.Struct{
+		parser.NewStruct("User", []parser.NamedTypeValue{
+			parser.NewNameType("Name", "string"),
+		}),
+	}
+	err := newGenerateGRPCTransport("grpc_conv_svc", svc, nil, structs).Generate()
+	Convey("Test if the gRPC encoders and decoders convert the messages", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := fs.Get().ReadFile("grpc_conv_svc/pkg/grpc/handler.go")
+		So(f, ShouldNotContainSubstring, "not impelemented")
+		So(f, ShouldContainSubstring, "req := r.(*pb.FooRequest)")
+		So(f, ShouldContainSubstring, "N: int(req.N),")
+		So(f, ShouldContainSubstring, "if request.Users[i], err = decodeUser(v); err != nil {")
+		So(f, ShouldContainSubstring, "rep.Err = resp.Err.Error()")
+		So(f, ShouldContainSubstring, "Seconds: resp.At.Unix(),")
+		So(f, ShouldContainSubstring, "D: time.Duration(req.D),")
+		So(f, ShouldContainSubstring, "rep.Took[i] = int64(v)")
+		So(f, ShouldContainSubstring, "func encodeUser(in service.User) (*pb.User, error) {")
+		So(f, ShouldContainSubstring, "func decodeUser(in *pb.User) (service.User, error) {")
+		Convey("Test if the struct functions are not generated twice", func() {
+			err = newGenerateGRPCTransport("grpc_conv_svc", svc, nil, structs).Generate()
+			So(err, ShouldBeNil)
+			f, _ := fs.Get().ReadFile("grpc_conv_svc/pkg/grpc/handler.go")
+			So(strings.Count(f, "func encodeUser("), ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_generateGRPCTransport_streaming(t *testing.T) {
+	setDefaults()
+	svc := parser.NewInterface("GrpcStreamSvcService", []parser.Method{
+		createTestMethod(
+			"Watch",
+			[]parser.NamedTypeValue{
+				parser.NewNameType("q", "string"),
+			},
+			[]parser.NamedTypeValue{
+				parser.NewNameType("events", "<-chan Event"),
+				parser.NewNameType("err", "error"),
+			},
+		),
+		createTestMethod(
+			"Upload",
+			[]parser.NamedTypeValue{
+				parser.NewNameType("in", "<-chan []byte"),
+			},
+			[]parser.NamedTypeValue{
+				parser.NewNameType("n", "int"),
+			},
+		),
+		createTestMethod(
+			"Chat",
+			[]parser.NamedTypeValue{
+				parser.NewNameType("in", "<-chan string"),
+			},
+			[]parser.NamedTypeValue{
+				parser.NewNameType("out", "<-chan string"),
+			},
+		),
+	})
+	structs := []parser.Struct{
+		parser.NewStruct("Event", []parser.NamedTypeValue{
+			parser.NewNameType("Name", "string"),
+		}),
+	}
+	err := newGenerateGRPCTransportProto("grpc_stream_svc", svc, nil, structs).Generate()
+	Convey("Test if the channels are generated as streaming RPCs", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := fs.Get().ReadFile("grpc_stream_svc/pkg/grpc/pb/grpc_stream_svc.proto")
+		f = strings.Join(strings.Fields(f), " ")
+		So(f, ShouldContainSubstring, "rpc Watch ( WatchRequest ) returns (stream WatchReply );")
+		So(f, ShouldContainSubstring, "rpc Upload (stream UploadRequest) returns ( UploadReply);")
+		So(f, ShouldContainSubstring, "rpc Chat (stream ChatRequest ) returns (stream ChatReply );")
+		So(f, ShouldContainSubstring, "Event events = 1;")
+		So(f, ShouldContainSubstring, "bytes in = 1;")
+	})
+	err = newGenerateGRPCTransport("grpc_stream_svc", svc, nil, structs).Generate()
+	Convey("Test if the streaming methods are served through the stream", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := fs.Get().ReadFile("grpc_stream_svc/pkg/grpc/handler.go")
+		So(f, ShouldNotContainSubstring, "makeWatchHandler")
+		So(f, ShouldContainSubstring, "func (g *grpcServer) Watch(req *pb.WatchRequest, stream pb.GrpcStreamSvc_WatchServer) error {")
+		So(f, ShouldContainSubstring, "func (g *grpcServer) Upload(stream pb.GrpcStreamSvc_UploadServer) error {")
+		So(f, ShouldContainSubstring, "func (g *grpcServer) Chat(stream pb.GrpcStreamSvc_ChatServer) error {")
+		So(f, ShouldContainSubstring, "if rep.Events, err = encodeEvent(v); err != nil {")
+		So(f, ShouldContainSubstring, "return stream.SendAndClose(rep.(*pb.UploadReply))")
+		So(f, ShouldContainSubstring, "in := make(chan string)")
+	})
+}
+
+func Test_protoGoName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"s", "S"},
+		{"user_id", "UserId"},
+		{"_name", "XName"},
+		{"field_2", "Field_2"},
+		{"a2b", "A2B"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := protoGoName(tt.name); got != tt.want {
+				t.Errorf("protoGoName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_newThriftField(t *testing.T) {
+	tests := []struct {
+		name string
+		arg  parser.NamedTypeValue
+		want thriftField
+	}{
+		{
+			name: "Test base type",
+			arg:  parser.NewNameType("name", "string"),
+			want: thriftField{Name: "Name", Type: "string", IDLType: "string", GoName: "Name", GoType: "string"},
+		},
+		{
+			name: "Test converted base type",
+			arg:  parser.NewNameType("count", "int"),
+			want: thriftField{Name: "Count", Type: "int", IDLType: "i64", GoName: "Count", GoType: "int64"},
+		},
+		{
+			name: "Test initialism",
+			arg:  parser.NewNameType("id", "string"),
+			want: thriftField{Name: "Id", Type: "string", IDLType: "string", GoName: "ID", GoType: "string"},
+		},
+		{
+			name: "Test error",
+			arg:  parser.NewNameType("err", "error"),
+			want: thriftField{Name: "Err", Type: "error", IDLType: "string", GoName: "Err", GoType: "string", Error: true},
+		},
+		{
+			name: "Test list",
+			arg:  parser.NewNameType("names", "[]string"),
+			want: thriftField{Name: "Names", Type: "[]string", IDLType: "list<string>", GoName: "Names", GoType: "[]string"},
+		},
+		{
+			name: "Test map",
+			arg:  parser.NewNameType("tags", "map[string]string"),
+			want: thriftField{Name: "Tags", Type: "map[string]string", IDLType: "map<string,string>", GoName: "Tags", GoType: "map[string]string"},
+		},
+		{
+			name: "Test unsupported type is sent as JSON",
+			arg:  parser.NewNameType("user", "io.User"),
+			want: thriftField{Name: "User", Type: "io.User", IDLType: "binary", GoName: "User", GoType: "[]byte", JSON: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newThriftField(tt.arg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newThriftField() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateNATSTransport_Generate(t *testing.T) {
+	setDefaults()
+	svc := getTestServiceInterface("nats_svc")
+	err := newGenerateNATSTransport("nats_svc", svc).Generate()
+	Convey("Test if the nats transport is generated without errors", t, func() {
+		So(err, ShouldBeNil)
+		err = newGenerateNATSTransportBase("nats_svc", svc, svc.Methods).Generate()
+		So(err, ShouldBeNil)
+		Convey("Test if the subscribers are created on the method subjects", func() {
+			f, _ := fs.Get().ReadFile("nats_svc/pkg/nats/handler.go")
+			So(f, ShouldContainSubstring, `subscribers["nats_svc.foo"] = nats.NewSubscriber(`)
+			So(f, ShouldContainSubstring, "func decodeFooRequest(_ context.Context, msg *natsgo.Msg)")
+			So(f, ShouldContainSubstring, "func encodeFooResponse(ctx context.Context, reply string, nc *natsgo.Conn, response interface{}) error")
+			So(f, ShouldContainSubstring, "func ErrorDecoder(msg *natsgo.Msg) error")
+		})
+		Convey("Test if the handler subscribes all the methods", func() {
+			f, _ := fs.Get().ReadFile("nats_svc/pkg/nats/handler_gen.go")
+			So(f, ShouldContainSubstring, `makeFooHandler(subscribers, endpoints, options["Foo"])`)
+			So(f, ShouldContainSubstring, "func Subscribe(nc *natsgo.Conn, queue string")
+		})
+	})
+}
+
+func Test_natsSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		method  string
+		want    string
+	}{
+		{"Test simple names", "users", "Get", "users.get"},
+		{"Test camel case names", "UserProfile", "GetByID", "user_profile.get_by_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := natsSubject(tt.service, tt.method); got != tt.want {
+				t.Errorf("natsSubject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateAMQPTransport_Generate(t *testing.T) {
+	setDefaults()
+	svc := getTestServiceInterface("amqp_svc")
+	err := newGenerateAMQPTransport("amqp_svc", svc).Generate()
+	Convey("Test if the amqp transport is generated without errors", t, func() {
+		So(err, ShouldBeNil)
+		err = newGenerateAMQPTransportBase("amqp_svc", svc, svc.Methods).Generate()
+		So(err, ShouldBeNil)
+		Convey("Test if the subscribers are created on the method queues", func() {
+			f, _ := fs.Get().ReadFile("amqp_svc/pkg/amqp/handler.go")
+			So(f, ShouldContainSubstring, `subscribers["amqp_svc.foo"] = amqp.NewSubscriber(`)
+			So(f, ShouldContainSubstring, "func encodeFooResponse(_ context.Context, p *amqp091go.Publishing, response interface{}) (err error)")
+			So(f, ShouldContainSubstring, "func ErrorEncoder(_ context.Context, err error, d *amqp091go.Delivery, ch amqp.Channel, p *amqp091go.Publishing)")
+		})
+		Convey("Test if the queues are declared through the Channel interface", func() {
+			f, _ := fs.Get().ReadFile("amqp_svc/pkg/amqp/handler_gen.go")
+			So(f, ShouldContainSubstring, "type Channel interface {")
+			So(f, ShouldContainSubstring, "func Subscribe(ch Channel, subscribers map[string]*amqp.Subscriber) error")
+			So(f, ShouldContainSubstring, `ch.ExchangeDeclare("amqp_svc", "direct", true, false, false, false, nil)`)
+		})
+	})
+}
+
+func Test_generateJSONRPCTransport_Generate(t *testing.T) {
+	setDefaults()
+	svc := getTestServiceInterface("jsonrpc_svc")
+	err := newGenerateJSONRPCTransport("jsonrpc_svc", svc).Generate()
+	Convey("Test if the json-rpc transport is generated without errors", t, func() {
+		So(err, ShouldBeNil)
+		err = newGenerateJSONRPCTransportBase("jsonrpc_svc", svc, svc.Methods).Generate()
+		So(err, ShouldBeNil)
+		Convey("Test if the codecs are added for the methods", func() {
+			f, _ := fs.Get().ReadFile("jsonrpc_svc/pkg/jsonrpc/handler.go")
+			So(f, ShouldContainSubstring, `ecm["foo"] = jsonrpc.EndpointCodec{`)
+			So(f, ShouldContainSubstring, "func decodeFooRequest(_ context.Context, params json.RawMessage) (interface{}, error)")
+			So(f, ShouldContainSubstring, "func encodeFooResponse(_ context.Context, response interface{}) (json.RawMessage, error)")
+		})
+		Convey("Test if the handler builds the codec map", func() {
+			f, _ := fs.Get().ReadFile("jsonrpc_svc/pkg/jsonrpc/handler_gen.go")
+			So(f, ShouldContainSubstring, "makeFooCodec(ecm, endpoints)")
+			So(f, ShouldContainSubstring, "return jsonrpc.NewServer(ecm, options...)")
+		})
+	})
+}
+
+func Test_jsonRPCMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		want   string
+	}{
+		{"Test simple name", "Get", "get"},
+		{"Test camel case name", "GetUserByID", "getUserByID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jsonRPCMethod(tt.method); got != tt.want {
+				t.Errorf("jsonRPCMethod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
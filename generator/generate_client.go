@@ -2,8 +2,12 @@ package generator
 
 import (
 	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
 	"path"
-
+	"strconv"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
@@ -27,16 +31,21 @@ type GenerateClient struct {
 	serviceFilePath  string
 	serviceFile      *parser.File
 	serviceInterface parser.Interface
+	methods          []string
 }
 
 // NewGenerateClient returns a client generator.
-func NewGenerateClient(name string, transport string) Gen {
+//
+// If methods are given only the code of those methods is generated, it is merged
+// into the client file if the file already exists.
+func NewGenerateClient(name string, transport string, methods []string) Gen {
 	i := &GenerateClient{
 		name:            name,
 		interfaceName:   utils.ToCamelCase(name + "Service"),
 		destPath:        fmt.Sprintf(viper.GetString("gk_client_cmd_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceDestPath: fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
 		transport:       transport,
+		methods:         methods,
 	}
 	i.serviceFilePath = path.Join(i.serviceDestPath, viper.GetString("gk_service_file_name"))
 	i.filePath = path.Join(i.destPath, viper.GetString("gk_service_file_name"))
@@ -66,24 +75,52 @@ func (g *GenerateClient) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	g.serviceFile, err = parser.NewFileParser().Parse([]byte(svcSrc))
+	g.serviceFile, err = parseServiceFile(g.fs, svcSrc, g.serviceFilePath)
+	if err != nil {
+		return err
+	}
 	if !g.serviceFound() {
 		return
 	}
 	g.removeBadMethods()
+	g.removeUnwantedMethods()
 	if len(g.serviceInterface.Methods) == 0 {
 		logrus.Error("The service has no suitable methods please implement the interface methods")
 		return
 	}
 	switch g.transport {
 	case "http":
-		cg := newGenerateHTTPClient(g.name, g.serviceInterface, g.serviceFile)
+		cg := newGenerateHTTPClient(g.name, g.serviceInterface, g.serviceFile, g.methods)
 		err = cg.Generate()
 		if err != nil {
 			return err
 		}
 	case "grpc":
-		cg := newGenerateGRPCClient(g.name, g.serviceInterface, g.serviceFile)
+		cg := newGenerateGRPCClient(g.name, g.serviceInterface, g.serviceFile, g.methods)
+		err = cg.Generate()
+		if err != nil {
+			return err
+		}
+	case "thrift":
+		cg := newGenerateThriftClient(g.name, g.serviceInterface, g.serviceFile, g.methods)
+		err = cg.Generate()
+		if err != nil {
+			return err
+		}
+	case "nats":
+		cg := newGenerateNATSClient(g.name, g.serviceInterface, g.serviceFile, g.methods)
+		err = cg.Generate()
+		if err != nil {
+			return err
+		}
+	case "amqp":
+		cg := newGenerateAMQPClient(g.name, g.serviceInterface, g.serviceFile, g.methods)
+		err = cg.Generate()
+		if err != nil {
+			return err
+		}
+	case "jsonrpc":
+		cg := newGenerateJSONRPCClient(g.name, g.serviceInterface, g.serviceFile, g.methods)
 		err = cg.Generate()
 		if err != nil {
 			return err
@@ -130,6 +167,22 @@ func (g *GenerateClient) removeBadMethods() {
 	g.serviceInterface.Methods = keepMethods
 }
 
+func (g *GenerateClient) removeUnwantedMethods() {
+	if len(g.methods) == 0 {
+		return
+	}
+	keepMethods := []parser.Method{}
+	for _, v := range g.serviceInterface.Methods {
+		for _, m := range g.methods {
+			if v.Name == m {
+				keepMethods = append(keepMethods, v)
+				break
+			}
+		}
+	}
+	g.serviceInterface.Methods = keepMethods
+}
+
 type generateHTTPClient struct {
 	BaseGenerator
 	name             string
@@ -138,15 +191,18 @@ type generateHTTPClient struct {
 	filePath         string
 	serviceInterface parser.Interface
 	serviceFile      *parser.File
+	methods          []string
+	routes           []httpRoute
 }
 
-func newGenerateHTTPClient(name string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+func newGenerateHTTPClient(name string, serviceInterface parser.Interface, serviceFile *parser.File, methods []string) Gen {
 	i := &generateHTTPClient{
 		name:             name,
 		interfaceName:    utils.ToCamelCase(name + "Service"),
 		destPath:         fmt.Sprintf(viper.GetString("gk_http_client_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceInterface: serviceInterface,
 		serviceFile:      serviceFile,
+		methods:          methods,
 	}
 	i.filePath = path.Join(i.destPath, viper.GetString("gk_http_client_file_name"))
 	i.srcFile = jen.NewFilePath(i.destPath)
@@ -174,6 +230,15 @@ func (g *generateHTTPClient) Generate() (err error) {
 	handles := []jen.Code{}
 	respS := jen.Dict{}
 	for _, m := range g.serviceInterface.Methods {
+		route, err := newHTTPRoute(m)
+		if err != nil {
+			return err
+		}
+		g.routes = append(g.routes, route)
+		encoder := "encodeHTTPGenericRequest"
+		if len(route.Params) > 0 {
+			encoder = fmt.Sprintf("encode%sRequest", m.Name)
+		}
 		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
 		handles = append(
 			handles,
@@ -185,13 +250,11 @@ func (g *generateHTTPClient) Generate() (err error) {
 					"github.com/go-kit/kit/transport/http",
 					"NewClient",
 				).Call(
-					jen.Lit("POST"),
+					jen.Lit(route.Method),
 					jen.Id("copyURL").Call(
-						jen.Id("u"), jen.Lit(
-							"/"+strings.Replace(utils.ToLowerSnakeCase(m.Name), "_", "-", -1),
-						),
+						jen.Id("u"), jen.Lit(clientPath(route)),
 					),
-					jen.Id("encodeHTTPGenericRequest"),
+					jen.Id(encoder),
 					jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
 					jen.Id(fmt.Sprintf("options[\"%s\"]...", m.Name)),
 				).Dot("Endpoint").Call(),
@@ -256,12 +319,14 @@ func (g *generateHTTPClient) Generate() (err error) {
 		},
 		"",
 		jen.Id("n").Op(":=").Id("*base"),
-		jen.Id("n").Dot("Path").Op("=").Id("path"),
+		jen.Id("n").Dot("Path").Op("=").Qual("strings", "TrimSuffix").Call(
+			jen.Id("base").Dot("Path"), jen.Lit("/"),
+		).Op("+").Id("path"),
 		jen.Id("next").Op("=").Id("&n"),
 		jen.Return(),
 	)
 	g.code.NewLine()
-	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+	return g.writeClient(g.filePath, g.methods)
 }
 func (g *generateHTTPClient) generateDecodeEncodeMethods(endpointImport string) (err error) {
 	httpImport, err := utils.GetHTTPTransportImportPath(g.name)
@@ -298,14 +363,37 @@ func (g *generateHTTPClient) generateDecodeEncodeMethods(endpointImport string)
 		jen.Return(jen.Nil()),
 	)
 	g.code.NewLine()
-	for _, m := range g.serviceInterface.Methods {
+	for i, m := range g.serviceInterface.Methods {
+		route := g.routes[i]
+		if len(route.Params) > 0 {
+			g.generateRouteEncoder(m, route, endpointImport)
+		}
 		g.code.appendMultilineComment([]string{
 			fmt.Sprintf("decode%sResponse is a transport/http.DecodeResponseFunc that decodes", m.Name),
 			"a JSON-encoded concat response from the HTTP response body. If the response",
-			"as a non-200 status code, we will interpret that as an error and attempt to",
-			" decode the specific error message from the response body.",
+			fmt.Sprintf("as a non-%d status code, we will interpret that as an error and attempt to", route.Status),
+			"decode the specific error message from the response body.",
 		})
 		g.code.NewLine()
+		body := []jen.Code{
+			jen.If(
+				jen.Id("r").Dot("StatusCode").Op("!=").Add(route.statusCode()),
+			).Block(
+				jen.Return(jen.Nil(), jen.Qual(httpImport, "ErrorDecoder").Call(jen.Id("r"))),
+			),
+			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
+		}
+		if route.Status == 204 {
+			body = append(body, jen.Return(jen.Id("resp"), jen.Nil()))
+		} else {
+			body = append(
+				body,
+				jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(
+					jen.Id("r").Dot("Body"),
+				).Dot("Decode").Call(jen.Id("&resp")),
+				jen.Return(jen.Id("resp"), jen.Err()),
+			)
+		}
 		g.code.appendFunction(
 			fmt.Sprintf("decode%sResponse", m.Name),
 			nil,
@@ -318,22 +406,96 @@ func (g *generateHTTPClient) generateDecodeEncodeMethods(endpointImport string)
 				jen.Error(),
 			},
 			"",
-			jen.If(
-				jen.Id("r").Dot("StatusCode").Op("!=").Qual("net/http", "StatusOK"),
-			).Block(
-				jen.Return(jen.Nil(), jen.Qual(httpImport, "ErrorDecoder").Call(jen.Id("r"))),
-			),
-			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
-			jen.Err().Op(":=").Qual("encoding/json", "NewDecoder").Call(
-				jen.Id("r").Dot("Body"),
-			).Dot("Decode").Call(jen.Id("&resp")),
-			jen.Return(jen.Id("resp"), jen.Err()),
+			body...,
 		)
 		g.code.NewLine()
 	}
 	return
 }
 
+// generateRouteEncoder generates the encoder of a method with parameters that are not sent
+// in the JSON body, the encoder sets the path, the query and the headers of the request.
+func (g *generateHTTPClient) generateRouteEncoder(m parser.Method, route httpRoute, endpointImport string) {
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("encode%sRequest is a transport/http.EncodeRequestFunc that encodes a", m.Name),
+		fmt.Sprintf("%s request as %s %s.", m.Name, route.Method, route.Path),
+	})
+	g.code.NewLine()
+	body := []jen.Code{
+		jen.Id("req").Op(":=").Id("request").Dot("").Call(jen.Qual(endpointImport, m.Name+"Request")),
+	}
+	if len(httpPathVars(route.Path)) > 0 {
+		// the path is added to the path of the instance, the variables are escaped.
+		body = append(
+			body,
+			jen.List(jen.Id("u"), jen.Err()).Op(":=").Qual("net/url", "Parse").Call(
+				jen.Qual("strings", "TrimSuffix").Call(
+					jen.Id("r").Dot("URL").Dot("EscapedPath").Call(), jen.Lit("/"),
+				).Op("+").Add(route.pathValue("req")),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.List(jen.Id("r").Dot("URL").Dot("Path"), jen.Id("r").Dot("URL").Dot("RawPath")).Op("=").List(
+				jen.Id("u").Dot("Path"), jen.Id("u").Dot("RawPath"),
+			),
+		)
+	}
+	query := []jen.Code{}
+	for _, p := range route.Params {
+		switch p.In {
+		case "query":
+			if strings.HasPrefix(p.Type, "[]") {
+				v := jen.Qual("fmt", "Sprint").Call(jen.Id("v"))
+				if p.Type == "[]string" {
+					v = jen.Id("v")
+				}
+				query = append(query, jen.For(
+					jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id("req").Dot(p.Name),
+				).Block(jen.Id("q").Dot("Add").Call(jen.Lit(p.Key), v)))
+				continue
+			}
+			query = append(query, jen.Id("q").Dot("Set").Call(jen.Lit(p.Key), p.writeParam("req")))
+		case "header":
+			body = append(body, jen.Id("r").Dot("Header").Dot("Set").Call(jen.Lit(p.Key), p.writeParam("req")))
+		}
+	}
+	if len(query) > 0 {
+		body = append(body, jen.Id("q").Op(":=").Id("r").Dot("URL").Dot("Query").Call())
+		body = append(body, query...)
+		body = append(body, jen.Id("r").Dot("URL").Dot("RawQuery").Op("=").Id("q").Dot("Encode").Call())
+	}
+	ctx := jen.Id("_")
+	if route.Body {
+		ctx = jen.Id("ctx")
+		body = append(body, jen.Return(jen.Id("encodeHTTPGenericRequest").Call(
+			jen.Id("ctx"), jen.Id("r"), jen.Id("request"),
+		)))
+	} else {
+		body = append(body, jen.Return(jen.Nil()))
+	}
+	g.code.appendFunction(
+		fmt.Sprintf("encode%sRequest", m.Name),
+		nil,
+		[]jen.Code{
+			ctx.Qual("context", "Context"),
+			jen.Id("r").Id("*").Qual("net/http", "Request"),
+			jen.Id("request").Interface(),
+		},
+		[]jen.Code{},
+		"error",
+		body...,
+	)
+	g.code.NewLine()
+}
+
+// clientPath returns the path the client of the route is created with, the path of a
+// route with variables is set by its encoder.
+func clientPath(route httpRoute) string {
+	if len(httpPathVars(route.Path)) > 0 {
+		return ""
+	}
+	return route.Path
+}
+
 type generateGRPCClient struct {
 	BaseGenerator
 	name             string
@@ -342,15 +504,17 @@ type generateGRPCClient struct {
 	filePath         string
 	serviceInterface parser.Interface
 	serviceFile      *parser.File
+	methods          []string
 }
 
-func newGenerateGRPCClient(name string, serviceInterface parser.Interface, serviceFile *parser.File) Gen {
+func newGenerateGRPCClient(name string, serviceInterface parser.Interface, serviceFile *parser.File, methods []string) Gen {
 	i := &generateGRPCClient{
 		name:             name,
 		interfaceName:    utils.ToCamelCase(name + "Service"),
 		destPath:         fmt.Sprintf(viper.GetString("gk_grpc_client_path_format"), utils.ToLowerSnakeCase(name)),
 		serviceInterface: serviceInterface,
 		serviceFile:      serviceFile,
+		methods:          methods,
 	}
 	i.filePath = path.Join(i.destPath, viper.GetString("gk_grpc_client_file_name"))
 	i.srcFile = jen.NewFilePath(i.destPath)
@@ -372,18 +536,60 @@ func (g *generateGRPCClient) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	g.code.appendMultilineComment([]string{
+	doc := []string{
 		"New returns an AddService backed by a gRPC server at the other end",
 		" of the conn. The caller is responsible for constructing the conn, and",
 		"eventually closing the underlying transport. We bake-in certain middlewares,",
 		"implementing the client library pattern.",
-	})
-
+	}
+	params := []jen.Code{
+		jen.Id("conn").Id("*").Qual("google.golang.org/grpc", "ClientConn"),
+		jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/grpc", "ClientOption"),
+	}
+	for _, m := range g.serviceInterface.Methods {
+		if grpcStreaming(m) {
+			doc = append(
+				doc,
+				"",
+				"The errors of the streams that can not be returned by the methods, e.x a",
+				"value of a channel that can not be sent, are passed to the errorHandler.",
+			)
+			params = append(params, jen.Id("errorHandler").Qual("github.com/go-kit/kit/transport", "ErrorHandler"))
+			break
+		}
+	}
+	g.code.appendMultilineComment(doc)
 	g.code.NewLine()
 	handles := []jen.Code{}
 	respS := jen.Dict{}
+	conv := newGRPCConverter(pbImport, serviceImport, g.serviceFile.Structures)
+	for _, m := range g.serviceInterface.Methods {
+		if grpcStreaming(m) {
+			// Streaming methods use the gRPC client directly because go-kit
+			// does not support gRPC streams.
+			handles = append(
+				handles,
+				jen.Id("client").Op(":=").Qual(pbImport, "New"+utils.ToCamelCase(g.name)+"Client").Call(jen.Id("conn")),
+			)
+			break
+		}
+	}
 	for _, m := range g.serviceInterface.Methods {
 		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		if grpcStreaming(m) {
+			handles = append(
+				handles,
+				jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+					"github.com/go-kit/kit/endpoint",
+					"Endpoint",
+				).Line().Block(
+					jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Add(
+						g.streamEndpoint(m, conv, endpointImport, pbImport),
+					),
+				).Line(),
+			)
+			continue
+		}
 		handles = append(
 			handles,
 			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
@@ -420,10 +626,7 @@ func (g *generateGRPCClient) Generate() (err error) {
 	g.code.appendFunction(
 		"New",
 		nil,
-		[]jen.Code{
-			jen.Id("conn").Id("*").Qual("google.golang.org/grpc", "ClientConn"),
-			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/grpc", "ClientOption"),
-		},
+		params,
 		[]jen.Code{
 			jen.Qual(serviceImport, g.serviceInterface.Name),
 			jen.Error(),
@@ -431,13 +634,13 @@ func (g *generateGRPCClient) Generate() (err error) {
 		"",
 		body...,
 	)
-	err = g.generateDecodeEncodeMethods(endpointImport)
+	err = g.generateDecodeEncodeMethods(endpointImport, pbImport, conv)
 	if err != nil {
 		return err
 	}
-	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+	return g.writeClient(g.filePath, g.methods)
 }
-func (g *generateGRPCClient) generateDecodeEncodeMethods(endpointImport string) (err error) {
+func (g *generateGRPCClient) generateDecodeEncodeMethods(endpointImport, pbImport string, conv *grpcConverter) (err error) {
 	for _, m := range g.serviceInterface.Methods {
 		g.code.NewLine()
 		g.code.appendMultilineComment([]string{
@@ -445,6 +648,14 @@ func (g *generateGRPCClient) generateDecodeEncodeMethods(endpointImport string)
 			fmt.Sprintf(" user-domain %s request to a gRPC request.", m.Name),
 		})
 		g.code.NewLine()
+		body := []jen.Code{}
+		if grpcConverts(grpcFields(m.Parameters)) {
+			body = append(body, jen.Id("req").Op(":=").Id("request").Dot("").Call(jen.Qual(endpointImport, m.Name+"Request")))
+		}
+		body = append(body, conv.toPB(
+			jen.Qual(pbImport, m.Name+"Request"), "req", "r", grpcFields(m.Parameters), jen.Nil(),
+		)...)
+		body = append(body, jen.Return(jen.Id("r"), jen.Nil()))
 		g.code.appendFunction(
 			fmt.Sprintf("encode%sRequest", m.Name),
 			nil,
@@ -457,18 +668,22 @@ func (g *generateGRPCClient) generateDecodeEncodeMethods(endpointImport string)
 				jen.Error(),
 			},
 			"",
-			jen.Return(
-				jen.Nil(), jen.Qual("errors", "New").Call(
-					jen.Lit(fmt.Sprintf("'%s' Encoder is not impelemented", utils.ToCamelCase(g.name))),
-				),
-			),
+			body...,
 		)
 		g.code.NewLine()
 		g.code.appendMultilineComment([]string{
 			fmt.Sprintf("decode%sResponse is a transport/grpc.DecodeResponseFunc that converts", m.Name),
-			"a gRPC concat reply to a user-domain concat response.",
+			fmt.Sprintf("a gRPC %s reply to a user-domain %s response.", m.Name, m.Name),
 		})
 		g.code.NewLine()
+		body = []jen.Code{}
+		if grpcConverts(grpcFields(m.Results)) {
+			body = append(body, jen.Id("rep").Op(":=").Id("reply").Dot("").Call(jen.Id("*").Qual(pbImport, m.Name+"Reply")))
+		}
+		body = append(body, conv.fromPB(
+			jen.Qual(endpointImport, m.Name+"Response"), "rep", "resp", grpcFields(m.Results), jen.Nil(),
+		)...)
+		body = append(body, jen.Return(jen.Id("resp"), jen.Nil()))
 		g.code.appendFunction(
 			fmt.Sprintf("decode%sResponse", m.Name),
 			nil,
@@ -481,13 +696,1019 @@ func (g *generateGRPCClient) generateDecodeEncodeMethods(endpointImport string)
 				jen.Error(),
 			},
 			"",
-			jen.Return(
-				jen.Nil(), jen.Qual("errors", "New").Call(
-					jen.Lit(fmt.Sprintf("'%s' Decoder is not impelemented", utils.ToCamelCase(g.name))),
-				),
-			),
+			body...,
 		)
 		g.code.NewLine()
 	}
+	conv.appendStructFunctions(g.code, nil)
 	return
 }
+
+// streamEndpoint returns the endpoint of a streaming method. The first message
+// of a stream carries the values that are not streamed and each of the messages
+// that follow carries a value of the channel. The errors of the values that are
+// sent and received after the endpoint returned end the stream and are passed to
+// the error handler of the client.
+func (g *generateGRPCClient) streamEndpoint(m parser.Method, conv *grpcConverter, endpointImport, pbImport string) jen.Code {
+	reqField, reqElem, streamsRequest := grpcStream(m.Parameters)
+	repField, repElem, streamsReturns := grpcStream(m.Results)
+	handleStreamErr := jen.Id("errorHandler").Dot("Handle").Call(jen.Id("ctx"), jen.Err()).Line().Return()
+	body := []jen.Code{
+		jen.List(jen.Id("req"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sRequest", m.Name)).Call(
+			jen.Id("ctx"),
+			jen.Id("request"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+	}
+	if streamsRequest {
+		body = append(
+			body,
+			jen.List(jen.Id("stream"), jen.Err()).Op(":=").Id("client").Dot(m.Name).Call(jen.Id("ctx")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.If(
+				jen.Err().Op("=").Id("stream").Dot("Send").Call(
+					jen.Id("req").Dot("").Call(jen.Id("*").Qual(pbImport, m.Name+"Request")),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Id("in").Op(":=").Id("request").Dot("").Call(
+				jen.Qual(endpointImport, m.Name+"Request"),
+			).Dot(reqField.Name),
+		)
+	} else {
+		body = append(
+			body,
+			jen.List(jen.Id("stream"), jen.Err()).Op(":=").Id("client").Dot(m.Name).Call(
+				jen.Id("ctx"),
+				jen.Id("req").Dot("").Call(jen.Id("*").Qual(pbImport, m.Name+"Request")),
+			),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+		)
+	}
+	if streamsRequest && !streamsReturns {
+		send := []jen.Code{
+			jen.Id("req").Op(":=").Id("&").Qual(pbImport, m.Name+"Request").Values(),
+		}
+		send = append(send, conv.toPBElem(
+			jen.Id("req").Dot(reqField.PbName), jen.Id("v"), reqElem, jen.Return(jen.Nil(), jen.Err()),
+		)...)
+		send = append(send, jen.If(
+			jen.Err().Op("=").Id("stream").Dot("Send").Call(jen.Id("req")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		))
+		return jen.Func().Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("request").Interface(),
+		).Params(
+			jen.Interface(),
+			jen.Error(),
+		).Block(append(
+			body,
+			// in is set to nil when it is closed.
+			jen.For(jen.Id("in").Op("!=").Nil()).Block(
+				jen.Select().Block(
+					jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+						jen.Return(jen.Nil(), jen.Id("ctx").Dot("Err").Call()),
+					),
+					jen.Case(jen.List(jen.Id("v"), jen.Id("ok")).Op(":=").Op("<-").Id("in")).Block(
+						append([]jen.Code{
+							jen.If(jen.Op("!").Id("ok")).Block(
+								jen.Id("in").Op("=").Nil(),
+								jen.Continue(),
+							),
+						}, send...)...,
+					),
+				),
+			),
+			jen.List(jen.Id("rep"), jen.Err()).Op(":=").Id("stream").Dot("CloseAndRecv").Call(),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Return(jen.Id(fmt.Sprintf("decode%sResponse", m.Name)).Call(
+				jen.Id("ctx"),
+				jen.Id("rep"),
+			)),
+		)...)
+	}
+	if streamsRequest {
+		send := []jen.Code{
+			jen.Id("req").Op(":=").Id("&").Qual(pbImport, m.Name+"Request").Values(),
+		}
+		send = append(send, conv.toPBElem(
+			jen.Id("req").Dot(reqField.PbName), jen.Id("v"), reqElem, handleStreamErr,
+		)...)
+		send = append(send, jen.If(
+			jen.Err().Op("=").Id("stream").Dot("Send").Call(jen.Id("req")),
+			jen.Err().Op("!=").Nil(),
+		).Block(
+			handleStreamErr,
+		))
+		body = append(
+			body,
+			jen.Go().Func().Params().Block(
+				jen.Defer().Id("stream").Dot("CloseSend").Call(),
+				jen.If(jen.Id("in").Op("==").Nil()).Block(
+					jen.Return(),
+				),
+				// The sender has its own error so it does not race with the endpoint.
+				jen.Var().Err().Error(),
+				jen.For().Block(
+					jen.Select().Block(
+						jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+							jen.Return(),
+						),
+						jen.Case(jen.List(jen.Id("v"), jen.Id("ok")).Op(":=").Op("<-").Id("in")).Block(
+							append([]jen.Code{
+								jen.If(jen.Op("!").Id("ok")).Block(
+									jen.Return(),
+								),
+							}, send...)...,
+						),
+					),
+				),
+			).Call(),
+		)
+	}
+	recv := []jen.Code{
+		jen.List(jen.Id("rep"), jen.Err()).Op(":=").Id("stream").Dot("Recv").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			// the end of the stream and the canceled calls are not errors of the stream.
+			jen.If(
+				jen.Err().Op("!=").Qual("io", "EOF").Op("&&").Id("ctx").Dot("Err").Call().Op("==").Nil(),
+			).Block(
+				jen.Id("errorHandler").Dot("Handle").Call(jen.Id("ctx"), jen.Err()),
+			),
+			jen.Return(),
+		),
+		jen.Var().Id("v").Add(conv.goType(repElem)),
+	}
+	recv = append(recv, conv.fromPBElem(jen.Id("v"), jen.Id("rep").Dot(repField.PbName), repElem, handleStreamErr)...)
+	recv = append(recv, jen.Select().Block(
+		jen.Case(jen.Id("out").Op("<-").Id("v")),
+		jen.Case(jen.Op("<-").Id("ctx").Dot("Done").Call()).Block(
+			jen.Return(),
+		),
+	))
+	body = append(
+		body,
+		jen.List(jen.Id("rep"), jen.Err()).Op(":=").Id("stream").Dot("Recv").Call(),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.List(jen.Id("response"), jen.Err()).Op(":=").Id(fmt.Sprintf("decode%sResponse", m.Name)).Call(
+			jen.Id("ctx"),
+			jen.Id("rep"),
+		),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+		jen.Id("resp").Op(":=").Id("response").Dot("").Call(jen.Qual(endpointImport, m.Name+"Response")),
+		jen.Id("out").Op(":=").Make(jen.Chan().Add(conv.goType(repElem))),
+		jen.Id("resp").Dot(repField.Name).Op("=").Id("out"),
+		jen.Go().Func().Params().Block(
+			jen.Defer().Close(jen.Id("out")),
+			jen.For().Block(recv...),
+		).Call(),
+		jen.Return(jen.Id("resp"), jen.Nil()),
+	)
+	return jen.Func().Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("request").Interface(),
+	).Params(
+		jen.Interface(),
+		jen.Error(),
+	).Block(body...)
+}
+
+type generateThriftClient struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+	methods          []string
+}
+
+func newGenerateThriftClient(name string, serviceInterface parser.Interface, serviceFile *parser.File, methods []string) Gen {
+	i := &generateThriftClient{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_thrift_client_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+		methods:          methods,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_thrift_client_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+func (g *generateThriftClient) Generate() (err error) {
+	g.CreateFolderStructure(g.destPath)
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	genImport, err := utils.GetThriftGenImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.code.appendMultilineComment([]string{
+		"New returns a service backed by a thrift client. The caller is responsible",
+		"for constructing the client (e.x with the generated New<Service>ClientFactory),",
+		"and eventually closing the underlying transport.",
+	})
+	g.code.NewLine()
+	handles := []jen.Code{}
+	respS := jen.Dict{}
+	for _, m := range g.serviceInterface.Methods {
+		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		handles = append(
+			handles,
+			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+				"github.com/go-kit/kit/endpoint",
+				"Endpoint",
+			).Line().Block(
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Func().Params(
+					jen.Id("ctx").Qual("context", "Context"),
+					jen.Id("request").Interface(),
+				).Params(
+					jen.Interface(),
+					jen.Error(),
+				).Block(
+					jen.List(jen.Id("req"), jen.Err()).Op(":=").Id(fmt.Sprintf("encode%sRequest", m.Name)).Call(
+						jen.Id("ctx"),
+						jen.Id("request"),
+					),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Nil(), jen.Err()),
+					),
+					jen.List(jen.Id("reply"), jen.Err()).Op(":=").Id("client").Dot(m.Name).Call(
+						jen.Id("ctx"),
+						jen.Id("req"),
+					),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Nil(), jen.Err()),
+					),
+					jen.Return(jen.Id(fmt.Sprintf("decode%sResponse", m.Name)).Call(
+						jen.Id("ctx"),
+						jen.Id("reply"),
+					)),
+				),
+			).Line(),
+		)
+	}
+	body := append([]jen.Code{},
+		handles...,
+	)
+	body = append(
+		body,
+		jen.Return(
+			jen.Qual(endpointImport, "Endpoints").Values(
+				respS,
+			),
+			jen.Nil(),
+		),
+	)
+	g.code.appendFunction(
+		"New",
+		nil,
+		[]jen.Code{
+			jen.Id("client").Qual(genImport, utils.ToCamelCase(g.name)),
+		},
+		[]jen.Code{
+			jen.Qual(serviceImport, g.serviceInterface.Name),
+			jen.Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	g.generateDecodeEncodeMethods(endpointImport, genImport)
+	return g.writeClient(g.filePath, g.methods)
+}
+func (g *generateThriftClient) generateDecodeEncodeMethods(endpointImport, genImport string) {
+	for _, m := range g.serviceInterface.Methods {
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("encode%sRequest converts a user-domain %s request to a thrift request.", m.Name, m.Name),
+		})
+		g.code.NewLine()
+		body := []jen.Code{
+			jen.Id("req").Op(":=").Id("request").Dot("").Call(jen.Qual(endpointImport, m.Name+"Request")),
+		}
+		body = append(body, endpointToThrift(
+			jen.Qual(genImport, m.Name+"Request"), "req", "r", thriftFields(m.Parameters),
+		)...)
+		body = append(body, jen.Return(jen.Id("r"), jen.Nil()))
+		g.code.appendFunction(
+			fmt.Sprintf("encode%sRequest", m.Name),
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("request").Interface(),
+			},
+			[]jen.Code{
+				jen.Id("*").Qual(genImport, m.Name+"Request"),
+				jen.Error(),
+			},
+			"",
+			body...,
+		)
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("decode%sResponse converts a thrift reply to a user-domain %s response.", m.Name, m.Name),
+		})
+		g.code.NewLine()
+		body = thriftToEndpoint(
+			jen.Qual(endpointImport, m.Name+"Response"), "reply", "resp", thriftFields(m.Results),
+		)
+		body = append(body, jen.Return(jen.Id("resp"), jen.Nil()))
+		g.code.appendFunction(
+			fmt.Sprintf("decode%sResponse", m.Name),
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("reply").Id("*").Qual(genImport, m.Name+"Reply"),
+			},
+			[]jen.Code{
+				jen.Interface(),
+				jen.Error(),
+			},
+			"",
+			body...,
+		)
+		g.code.NewLine()
+	}
+}
+
+type generateNATSClient struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+	methods          []string
+}
+
+func newGenerateNATSClient(name string, serviceInterface parser.Interface, serviceFile *parser.File, methods []string) Gen {
+	i := &generateNATSClient{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_nats_client_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+		methods:          methods,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_nats_client_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+func (g *generateNATSClient) Generate() (err error) {
+	g.CreateFolderStructure(g.destPath)
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	natsImport, err := utils.GetNATSTransportImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	g.code.appendMultilineComment([]string{
+		"New returns a service that publishes the requests on the given NATS connection.",
+		"The caller is responsible for closing the connection.",
+	})
+	g.code.NewLine()
+	handles := []jen.Code{}
+	respS := jen.Dict{}
+	for _, m := range g.serviceInterface.Methods {
+		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		handles = append(
+			handles,
+			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+				"github.com/go-kit/kit/endpoint",
+				"Endpoint",
+			).Line().Block(
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Qual(
+					"github.com/go-kit/kit/transport/nats",
+					"NewPublisher",
+				).Call(
+					jen.Id("nc"),
+					jen.Lit(natsSubject(g.name, m.Name)),
+					jen.Qual("github.com/go-kit/kit/transport/nats", "EncodeJSONRequest"),
+					jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
+					jen.Id(fmt.Sprintf("options[\"%s\"]...", m.Name)),
+				).Dot("Endpoint").Call(),
+			).Line(),
+		)
+	}
+	body := append([]jen.Code{},
+		handles...,
+	)
+	body = append(
+		body,
+		jen.Return(
+			jen.Qual(endpointImport, "Endpoints").Values(
+				respS,
+			),
+			jen.Nil(),
+		),
+	)
+	g.code.appendFunction(
+		"New",
+		nil,
+		[]jen.Code{
+			jen.Id("nc").Id("*").Qual("github.com/nats-io/nats.go", "Conn"),
+			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/nats", "PublisherOption"),
+		},
+		[]jen.Code{
+			jen.Qual(serviceImport, g.serviceInterface.Name),
+			jen.Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	for _, m := range g.serviceInterface.Methods {
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("decode%sResponse is a transport/nats.DecodeResponseFunc that decodes", m.Name),
+			"a JSON-encoded response from the reply message. Errors published by the",
+			"service are decoded and returned as the error of the call.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			fmt.Sprintf("decode%sResponse", m.Name),
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("msg").Id("*").Qual("github.com/nats-io/nats.go", "Msg"),
+			},
+			[]jen.Code{
+				jen.Interface(),
+				jen.Error(),
+			},
+			"",
+			jen.If(
+				jen.Err().Op(":=").Qual(natsImport, "ErrorDecoder").Call(jen.Id("msg")).Id(";").Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+				jen.Id("msg").Dot("Data"),
+				jen.Id("&resp"),
+			),
+			jen.Return(jen.Id("resp"), jen.Err()),
+		)
+		g.code.NewLine()
+	}
+	return g.writeClient(g.filePath, g.methods)
+}
+
+type generateAMQPClient struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+	methods          []string
+}
+
+func newGenerateAMQPClient(name string, serviceInterface parser.Interface, serviceFile *parser.File, methods []string) Gen {
+	i := &generateAMQPClient{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_amqp_client_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+		methods:          methods,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_amqp_client_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+func (g *generateAMQPClient) Generate() (err error) {
+	g.CreateFolderStructure(g.destPath)
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	amqpImport, err := utils.GetAMQPTransportImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	exchange := utils.ToLowerSnakeCase(g.name)
+	g.code.appendMultilineComment([]string{
+		fmt.Sprintf("New returns a service that publishes the requests to the `%s` exchange of the", exchange),
+		"channel, the replies are consumed from the queue q. The caller is responsible",
+		"for declaring q (usually an exclusive queue) and closing the channel.",
+	})
+	g.code.NewLine()
+	handles := []jen.Code{}
+	respS := jen.Dict{}
+	for _, m := range g.serviceInterface.Methods {
+		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		handles = append(
+			handles,
+			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+				"github.com/go-kit/kit/endpoint",
+				"Endpoint",
+			).Line().Block(
+				jen.Id("opts").Op(":=").Append(
+					jen.Index().Qual("github.com/go-kit/kit/transport/amqp", "PublisherOption").Values(
+						jen.Qual("github.com/go-kit/kit/transport/amqp", "PublisherBefore").Call(
+							jen.Qual("github.com/go-kit/kit/transport/amqp", "SetPublishExchange").Call(
+								jen.Lit(exchange),
+							),
+							jen.Qual("github.com/go-kit/kit/transport/amqp", "SetPublishKey").Call(
+								jen.Lit(amqpQueue(g.name, m.Name)),
+							),
+						),
+					),
+					jen.Id(fmt.Sprintf("options[\"%s\"]...", m.Name)),
+				),
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Qual(
+					"github.com/go-kit/kit/transport/amqp",
+					"NewPublisher",
+				).Call(
+					jen.Id("ch"),
+					jen.Id("q"),
+					jen.Id("encodeRequest"),
+					jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
+					jen.Id("opts..."),
+				).Dot("Endpoint").Call(),
+			).Line(),
+		)
+	}
+	body := append([]jen.Code{},
+		handles...,
+	)
+	body = append(
+		body,
+		jen.Return(
+			jen.Qual(endpointImport, "Endpoints").Values(
+				respS,
+			),
+			jen.Nil(),
+		),
+	)
+	g.code.appendFunction(
+		"New",
+		nil,
+		[]jen.Code{
+			jen.Id("ch").Qual("github.com/go-kit/kit/transport/amqp", "Channel"),
+			jen.Id("q").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Queue"),
+			jen.Id("options").Map(jen.String()).Index().Qual("github.com/go-kit/kit/transport/amqp", "PublisherOption"),
+		},
+		[]jen.Code{
+			jen.Qual(serviceImport, g.serviceInterface.Name),
+			jen.Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	g.code.appendMultilineComment([]string{
+		"encodeRequest is a transport/amqp.EncodeRequestFunc that JSON-encodes",
+		"the request to the body of the publishing.",
+	})
+	g.code.NewLine()
+	g.code.appendFunction(
+		"encodeRequest",
+		nil,
+		[]jen.Code{
+			jen.Id("_").Qual("context", "Context"),
+			jen.Id("p").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Publishing"),
+			jen.Id("request").Interface(),
+		},
+		[]jen.Code{
+			jen.Error(),
+		},
+		"",
+		jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/json", "Marshal").Call(jen.Id("request")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Err()),
+		),
+		jen.Id("p").Dot("Body").Op("=").Id("b"),
+		jen.Return(jen.Nil()),
+	)
+	g.code.NewLine()
+	for _, m := range g.serviceInterface.Methods {
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("decode%sResponse is a transport/amqp.DecodeResponseFunc that decodes", m.Name),
+			"a JSON-encoded response from the reply delivery. Errors published by the",
+			"service are decoded and returned as the error of the call.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			fmt.Sprintf("decode%sResponse", m.Name),
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("d").Id("*").Qual("github.com/rabbitmq/amqp091-go", "Delivery"),
+			},
+			[]jen.Code{
+				jen.Interface(),
+				jen.Error(),
+			},
+			"",
+			jen.If(
+				jen.Err().Op(":=").Qual(amqpImport, "ErrorDecoder").Call(jen.Id("d")).Id(";").Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Nil(), jen.Err()),
+			),
+			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+				jen.Id("d").Dot("Body"),
+				jen.Id("&resp"),
+			),
+			jen.Return(jen.Id("resp"), jen.Err()),
+		)
+		g.code.NewLine()
+	}
+	return g.writeClient(g.filePath, g.methods)
+}
+
+type generateJSONRPCClient struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	filePath         string
+	serviceInterface parser.Interface
+	serviceFile      *parser.File
+	methods          []string
+}
+
+func newGenerateJSONRPCClient(name string, serviceInterface parser.Interface, serviceFile *parser.File, methods []string) Gen {
+	i := &generateJSONRPCClient{
+		name:             name,
+		interfaceName:    utils.ToCamelCase(name + "Service"),
+		destPath:         fmt.Sprintf(viper.GetString("gk_jsonrpc_client_path_format"), utils.ToLowerSnakeCase(name)),
+		serviceInterface: serviceInterface,
+		serviceFile:      serviceFile,
+		methods:          methods,
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_jsonrpc_client_file_name"))
+	i.srcFile = jen.NewFilePath(i.destPath)
+	i.InitPg()
+	i.fs = fs.Get()
+	return i
+}
+func (g *generateJSONRPCClient) Generate() (err error) {
+	g.CreateFolderStructure(g.destPath)
+	endpointImport, err := utils.GetEndpointImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	serviceImport, err := utils.GetServiceImportPath(g.name)
+	if err != nil {
+		return err
+	}
+	jsonRPCPkg := "github.com/go-kit/kit/transport/http/jsonrpc"
+	g.code.appendMultilineComment([]string{
+		"New returns a service backed by a JSON-RPC server living at the remote",
+		"instance. We expect instance to come from a service discovery system, so",
+		"likely of the form \"host:port\".",
+	})
+	g.code.NewLine()
+	handles := []jen.Code{}
+	respS := jen.Dict{}
+	for _, m := range g.serviceInterface.Methods {
+		respS[jen.Id(m.Name+"Endpoint")] = jen.Id(utils.ToLowerFirstCamelCase(m.Name) + "Endpoint")
+		handles = append(
+			handles,
+			jen.Var().Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Qual(
+				"github.com/go-kit/kit/endpoint",
+				"Endpoint",
+			).Line().Block(
+				jen.Id("opts").Op(":=").Append(
+					jen.Index().Qual(jsonRPCPkg, "ClientOption").Values(
+						jen.Qual(jsonRPCPkg, "ClientResponseDecoder").Call(
+							jen.Id(fmt.Sprintf("decode%sResponse", m.Name)),
+						),
+					),
+					jen.Id(fmt.Sprintf("options[\"%s\"]...", m.Name)),
+				),
+				jen.Id(utils.ToLowerFirstCamelCase(m.Name)+"Endpoint").Op("=").Qual(
+					jsonRPCPkg,
+					"NewClient",
+				).Call(
+					jen.Id("u"),
+					jen.Lit(jsonRPCMethod(m.Name)),
+					jen.Id("opts..."),
+				).Dot("Endpoint").Call(),
+			).Line(),
+		)
+	}
+	body := append([]jen.Code{
+		jen.If(
+			jen.Id("!").Qual("strings", "HasPrefix").Call(
+				jen.Id("instance"),
+				jen.Lit("http"),
+			),
+		).Block(
+			jen.Id("instance").Op("=").Lit("http://").Op("+").Id("instance"),
+		),
+		jen.List(
+			jen.Id("u"),
+			jen.Id("err"),
+		).Op(":=").Qual("net/url", "Parse").Call(jen.Id("instance")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Return(jen.Nil(), jen.Err()),
+		),
+	},
+		handles...,
+	)
+	body = append(
+		body,
+		jen.Return(
+			jen.Qual(endpointImport, "Endpoints").Values(
+				respS,
+			),
+			jen.Nil(),
+		),
+	)
+	g.code.appendFunction(
+		"New",
+		nil,
+		[]jen.Code{
+			jen.Id("instance").String(),
+			jen.Id("options").Map(jen.String()).Index().Qual(jsonRPCPkg, "ClientOption"),
+		},
+		[]jen.Code{
+			jen.Qual(serviceImport, g.serviceInterface.Name),
+			jen.Error(),
+		},
+		"",
+		body...,
+	)
+	g.code.NewLine()
+	for _, m := range g.serviceInterface.Methods {
+		g.code.NewLine()
+		g.code.appendMultilineComment([]string{
+			fmt.Sprintf("decode%sResponse is a transport/http/jsonrpc.DecodeResponseFunc that decodes", m.Name),
+			"the JSON-RPC result to the endpoint response, JSON-RPC errors are returned",
+			"as the error of the call.",
+		})
+		g.code.NewLine()
+		g.code.appendFunction(
+			fmt.Sprintf("decode%sResponse", m.Name),
+			nil,
+			[]jen.Code{
+				jen.Id("_").Qual("context", "Context"),
+				jen.Id("res").Qual(jsonRPCPkg, "Response"),
+			},
+			[]jen.Code{
+				jen.Interface(),
+				jen.Error(),
+			},
+			"",
+			jen.If(jen.Id("res").Dot("Error").Op("!=").Nil()).Block(
+				jen.Return(jen.Nil(), jen.Id("res").Dot("Error")),
+			),
+			jen.Var().Id("resp").Qual(endpointImport, m.Name+"Response"),
+			jen.Err().Op(":=").Qual("encoding/json", "Unmarshal").Call(
+				jen.Id("res").Dot("Result"),
+				jen.Id("&resp"),
+			),
+			jen.Return(jen.Id("resp"), jen.Err()),
+		)
+		g.code.NewLine()
+	}
+	return g.writeClient(g.filePath, g.methods)
+}
+
+// writeClient writes the generated client file. If only some methods are generated and
+// the client file already exists their code is merged into it, the endpoints of the
+// methods are added to the `New` function and the other declarations are added with
+// MergeSource.
+func (b *BaseGenerator) writeClient(pth string, methods []string) error {
+	if len(methods) == 0 {
+		return b.fs.WriteFile(pth, b.srcFile.GoString(), false)
+	}
+	if e, err := b.fs.Exists(pth); err != nil {
+		return err
+	} else if !e {
+		return b.fs.WriteFile(pth, b.srcFile.GoString(), false)
+	}
+	src, err := b.fs.ReadFile(pth)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, pth, src, goparser.ParseComments)
+	if err != nil {
+		return err
+	}
+	// the packages the file imports keep their names in the generated code.
+	for _, s := range f.Imports {
+		name := ""
+		if s.Name != nil {
+			name = s.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		p, _ := strconv.Unquote(s.Path.Value)
+		if name != "" {
+			b.srcFile.ImportAlias(p, name)
+		} else {
+			b.srcFile.ImportName(p, importName("", s.Path.Value))
+		}
+	}
+	gen := b.srcFile.GoString()
+	gset := token.NewFileSet()
+	gf, err := goparser.ParseFile(gset, "", gen, goparser.ParseComments)
+	if err != nil {
+		return err
+	}
+	declared := map[string]bool{}
+	var fnNew *ast.FuncDecl
+	for _, d := range f.Decls {
+		for _, n := range declNames(d) {
+			declared[n] = true
+		}
+		if d, ok := d.(*ast.FuncDecl); ok && d.Recv == nil && d.Name.Name == "New" {
+			fnNew = d
+		}
+	}
+	if fnNew == nil || fnNew.Body == nil {
+		return fmt.Errorf("could not find the function `New` in %s, use -f to generate the client again", pth)
+	}
+	code := ""
+	blocks := ""
+	elts := []string{}
+	for _, d := range gf.Decls {
+		d, ok := d.(*ast.FuncDecl)
+		if !ok || d.Recv != nil || d.Name.Name != "New" {
+			continue
+		}
+		if gset.Position(d.Type.Params.Closing).Offset-gset.Position(d.Type.Params.Opening).Offset !=
+			fset.Position(fnNew.Type.Params.Closing).Offset-fset.Position(fnNew.Type.Params.Opening).Offset {
+			logrus.Warnf("The parameters of `New` in %s changed, use -f to generate the client again", pth)
+		}
+		vars := map[string]bool{}
+		for _, m := range methods {
+			vars[utils.ToLowerFirstCamelCase(m)+"Endpoint"] = true
+			elts = append(elts, fmt.Sprintf("%sEndpoint: %sEndpoint", m, utils.ToLowerFirstCamelCase(m)))
+		}
+		for i, st := range d.Body.List {
+			if !declaresVar(st, vars) {
+				continue
+			}
+			end := st.End()
+			if i+1 < len(d.Body.List) {
+				if bl, ok := d.Body.List[i+1].(*ast.BlockStmt); ok {
+					end = bl.End()
+				}
+			}
+			start := gset.Position(st.Pos()).Offset
+			start = strings.LastIndex(gen[:start], "\n") + 1
+			blocks += gen[start:gset.Position(end).Offset] + "\n\n"
+		}
+	}
+	for _, d := range gf.Decls {
+		names := declNames(d)
+		if len(names) == 0 || names[0] == "New" || declared[names[0]] {
+			continue
+		}
+		start := d.Pos()
+		if d, ok := d.(*ast.FuncDecl); ok && d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+		if d, ok := d.(*ast.GenDecl); ok && d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+		code += gen[gset.Position(start).Offset:gset.Position(d.End()).Offset] + "\n\n"
+	}
+	merged, err := addEndpoints(fset, src, fnNew, blocks, elts)
+	if err != nil {
+		return err
+	}
+	imp := []parser.NamedTypeValue{}
+	for _, s := range gf.Imports {
+		v := parser.NamedTypeValue{Type: s.Path.Value}
+		if s.Name != nil {
+			v.Name = s.Name.Name
+		}
+		imp = append(imp, v)
+	}
+	merged, err = b.MergeSource(pth, merged, code, imp)
+	if err != nil {
+		return err
+	}
+	return b.fs.WriteFile(pth, merged, true)
+}
+
+// addEndpoints inserts the declarations of the endpoints `blocks` before the return
+// statement of `New` and adds the elements `elts` to the endpoints it returns.
+func addEndpoints(fset *token.FileSet, src string, fnNew *ast.FuncDecl, blocks string, elts []string) (string, error) {
+	offset := func(p token.Pos) int {
+		return fset.Position(p).Offset
+	}
+	var ret *ast.ReturnStmt
+	if n := len(fnNew.Body.List); n > 0 {
+		ret, _ = fnNew.Body.List[n-1].(*ast.ReturnStmt)
+	}
+	if ret == nil || len(ret.Results) == 0 {
+		return "", fmt.Errorf("could not find the endpoints returned by `New`, use -f to generate the client again")
+	}
+	lit, ok := ret.Results[0].(*ast.CompositeLit)
+	if !ok {
+		return "", fmt.Errorf("could not find the endpoints returned by `New`, use -f to generate the client again")
+	}
+	lbrace, rbrace := offset(lit.Lbrace), offset(lit.Rbrace)
+	inner := src[lbrace+1 : rbrace]
+	if !strings.Contains(inner, "\n") {
+		// the endpoints are written one per line like the generated literals with
+		// more than one element.
+		inner = "\n"
+		for _, e := range lit.Elts {
+			inner += src[offset(e.Pos()):offset(e.End())] + ",\n"
+		}
+	}
+	inner = strings.TrimRight(inner, " \t")
+	for _, e := range elts {
+		inner += e + ",\n"
+	}
+	// the literal is formatted alone so the elements are aligned.
+	pkg := "package p\n\nvar _ = T"
+	s, err := format.Source([]byte(pkg + "{" + inner + "}\n"))
+	if err != nil {
+		return "", err
+	}
+	line := src[strings.LastIndex(src[:lbrace], "\n")+1 : lbrace]
+	indent := line[:len(line)-len(strings.TrimLeft(line, "\t"))]
+	formatted := strings.TrimSpace(strings.TrimPrefix(string(s), pkg))
+	formatted = strings.Replace(formatted, "\n", "\n"+indent, -1)
+	// the endpoints are declared before the comments of the return statement.
+	retLine := strings.LastIndex(src[:offset(ret.Pos())], "\n") + 1
+	for retLine > offset(fnNew.Body.Lbrace)+1 {
+		prev := strings.LastIndex(src[:retLine-1], "\n") + 1
+		if !strings.HasPrefix(strings.TrimLeft(src[prev:retLine], " \t"), "//") {
+			break
+		}
+		retLine = prev
+	}
+	return src[:retLine] + blocks + src[retLine:lbrace] + formatted + src[rbrace+1:], nil
+}
+
+// declNames returns the names a top level declaration declares, the methods are not
+// returned.
+func declNames(d ast.Decl) []string {
+	names := []string{}
+	switch d := d.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, s := range d.Specs {
+			switch s := s.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+	}
+	return names
+}
@@ -1 +1,89 @@
 package generator
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerateClient_HTTPRoutes(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("client_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// ClientSvcService describes the service.
+type ClientSvcService interface {
+	// @http GET /users/{id}
+	GetUser(ctx context.Context, id string) (name string, err error)
+}
+`, true)
+	Convey("Test if the http client escapes the path variables and keeps the path of the instance", t, func() {
+		So(NewGenerateService("client_svc", "http", false, true, false, nil).Generate(), ShouldBeNil)
+		So(NewGenerateClient("client_svc", "http", nil).Generate(), ShouldBeNil)
+		src, err := fs.Get().ReadFile("client_svc/client/http/http.go")
+		So(err, ShouldBeNil)
+		So(src, ShouldContainSubstring, `copyURL(u, "")`)
+		So(src, ShouldContainSubstring, `url.Parse(strings.TrimSuffix(r.URL.EscapedPath(), "/") + "/users/" + url.PathEscape(req.Id))`)
+		So(src, ShouldContainSubstring, "r.URL.Path, r.URL.RawPath = u.Path, u.RawPath")
+		So(src, ShouldContainSubstring, `n.Path = strings.TrimSuffix(base.Path, "/") + path`)
+		fset := token.NewFileSet()
+		f, err := goparser.ParseFile(fset, "http.go", src, 0)
+		So(err, ShouldBeNil)
+		So(undeclaredNames(fset, []*ast.File{f}), ShouldBeEmpty)
+	})
+}
+
+func TestGenerateClient_AMQP(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("amqp_client_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// AmqpClientSvcService describes the service.
+type AmqpClientSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`, true)
+	Convey("Test if the amqp client encodes the requests with amqp091-go", t, func() {
+		So(NewGenerateService("amqp_client_svc", "amqp", false, true, false, nil).Generate(), ShouldBeNil)
+		So(NewGenerateClient("amqp_client_svc", "amqp", nil).Generate(), ShouldBeNil)
+		src, err := fs.Get().ReadFile("amqp_client_svc/client/amqp/amqp.go")
+		So(err, ShouldBeNil)
+		So(src, ShouldContainSubstring, `amqp091go "github.com/rabbitmq/amqp091-go"`)
+		So(src, ShouldContainSubstring, "amqp.NewPublisher(ch, q, encodeRequest, decodeFooResponse, opts...)")
+		So(src, ShouldContainSubstring, "func encodeRequest(_ context.Context, p *amqp091go.Publishing, request interface{}) error")
+		So(src, ShouldNotContainSubstring, "streadway")
+	})
+}
+
+func TestGenerateClient_GRPCStreams(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("grpc_client_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// GrpcClientSvcService describes the service.
+type GrpcClientSvcService interface {
+	Upload(ctx context.Context, in <-chan []byte) (n int, err error)
+	Chat(ctx context.Context, in <-chan string) (out <-chan string, err error)
+}
+`, true)
+	Convey("Test if the stream clients stop with the context and report the errors", t, func() {
+		So(NewGenerateService("grpc_client_svc", "grpc", false, false, false, nil).Generate(), ShouldBeNil)
+		So(NewGenerateClient("grpc_client_svc", "grpc", nil).Generate(), ShouldBeNil)
+		src, err := fs.Get().ReadFile("grpc_client_svc/client/grpc/grpc.go")
+		So(err, ShouldBeNil)
+		So(src, ShouldContainSubstring, "options map[string][]grpc1.ClientOption, errorHandler transport.ErrorHandler)")
+		So(src, ShouldContainSubstring, "for in != nil {")
+		So(src, ShouldContainSubstring, "return nil, ctx.Err()")
+		So(src, ShouldContainSubstring, "if err = stream.Send(req); err != nil {\n\t\t\t\t\t\t\terrorHandler.Handle(ctx, err)\n\t\t\t\t\t\t\treturn")
+		So(src, ShouldContainSubstring, "if err != io.EOF && ctx.Err() == nil {\n\t\t\t\t\t\t\terrorHandler.Handle(ctx, err)")
+		So(strings.Count(src, "case <-ctx.Done():"), ShouldEqual, 3)
+	})
+}
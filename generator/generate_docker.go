@@ -83,7 +83,15 @@ func (g *GenerateDocker) Generate() (err error) {
 			fmt.Sprintf(viper.GetString("gk_grpc_path_format"), utils.ToLowerSnakeCase(v)),
 			viper.GetString("gk_grpc_file_name"),
 		)
-		err = g.generateDockerFile(v, svcFilePath, httpFilePath, grpcFilePath)
+		thriftFilePath := path.Join(
+			fmt.Sprintf(viper.GetString("gk_thrift_path_format"), utils.ToLowerSnakeCase(v)),
+			viper.GetString("gk_thrift_file_name"),
+		)
+		jsonRPCFilePath := path.Join(
+			fmt.Sprintf(viper.GetString("gk_jsonrpc_path_format"), utils.ToLowerSnakeCase(v)),
+			viper.GetString("gk_jsonrpc_file_name"),
+		)
+		err = g.generateDockerFile(v, svcFilePath, httpFilePath, grpcFilePath, thriftFilePath, jsonRPCFilePath)
 		if err != nil {
 			return err
 		}
@@ -94,7 +102,7 @@ func (g *GenerateDocker) Generate() (err error) {
 	}
 	return g.fs.WriteFile("docker-compose.yml", string(d), true)
 }
-func (g *GenerateDocker) generateDockerFile(name, svcFilePath, httpFilePath, grpcFilePath string) (err error) {
+func (g *GenerateDocker) generateDockerFile(name, svcFilePath, httpFilePath, grpcFilePath, thriftFilePath, jsonRPCFilePath string) (err error) {
 	pth, err := utils.GetDockerFileProjectPath()
 	if err != nil {
 		return err
@@ -103,7 +111,7 @@ func (g *GenerateDocker) generateDockerFile(name, svcFilePath, httpFilePath, grp
 		return err
 	} else if b {
 		pth = "/go/src/" + pth
-		return g.addToDockerCompose(name, pth, httpFilePath, grpcFilePath)
+		return g.addToDockerCompose(name, pth, httpFilePath, grpcFilePath, thriftFilePath, jsonRPCFilePath)
 	}
 	if b, err := g.fs.Exists("docker-compose.yml"); err != nil {
 		return err
@@ -156,7 +164,7 @@ ENTRYPOINT  watcher -run %s/%s/cmd -watch %s/%s
 `
 	}
 	fpath := "/go/src/" + pth
-	err = g.addToDockerCompose(name, fpath, httpFilePath, grpcFilePath)
+	err = g.addToDockerCompose(name, fpath, httpFilePath, grpcFilePath, thriftFilePath, jsonRPCFilePath)
 	if err != nil {
 		return err
 	}
@@ -169,18 +177,16 @@ ENTRYPOINT  watcher -run %s/%s/cmd -watch %s/%s
 	return g.fs.WriteFile(path.Join(name, "Dockerfile"), dockerFile, true)
 }
 
-func (g *GenerateDocker) addToDockerCompose(name, pth, httpFilePath, grpcFilePath string) (err error) {
-	hasHTTP := false
-	hasGRPC := false
-	if b, err := g.fs.Exists(httpFilePath); err != nil {
-		return err
-	} else if b {
-		hasHTTP = true
-	}
-	if b, err := g.fs.Exists(grpcFilePath); err != nil {
-		return err
-	} else if b {
-		hasGRPC = true
+func (g *GenerateDocker) addToDockerCompose(name, pth, httpFilePath, grpcFilePath, thriftFilePath, jsonRPCFilePath string) (err error) {
+	// The transports that listen on a port, with the port they listen on in the container.
+	transports := []struct {
+		filePath string
+		port     string
+	}{
+		{httpFilePath, "8081"},
+		{grpcFilePath, "8082"},
+		{thriftFilePath, "8083"},
+		{jsonRPCFilePath, "8084"},
 	}
 	usedPorts := []string{}
 	for _, v := range g.dockerCompose.Services {
@@ -210,50 +216,32 @@ func (g *GenerateDocker) addToDockerCompose(name, pth, httpFilePath, grpcFilePat
 				".:" + pth,
 			},
 		}
-		if hasHTTP {
-			httpExpose := 8800
-			for {
-				ex := false
-				for _, v := range usedPorts {
-					if v == fmt.Sprintf("%d", httpExpose) {
-						ex = true
-						break
-					}
-				}
-				if ex {
-					httpExpose++
-				} else {
-					break
-				}
-			}
-			g.dockerCompose.Services[name].(*DockerService).Ports = []string{
-				fmt.Sprintf("%d", httpExpose) + ":8081",
+		for _, t := range transports {
+			if b, err := g.fs.Exists(t.filePath); err != nil {
+				return err
+			} else if !b {
+				continue
 			}
-			usedPorts = append(usedPorts, fmt.Sprintf("%d", httpExpose))
-		}
-		if hasGRPC {
-			grpcExpose := 8800
+			expose := 8800
 			for {
 				ex := false
 				for _, v := range usedPorts {
-					if v == fmt.Sprintf("%d", grpcExpose) {
+					if v == fmt.Sprintf("%d", expose) {
 						ex = true
 						break
 					}
 				}
 				if ex {
-					grpcExpose++
+					expose++
 				} else {
 					break
 				}
 			}
-			if g.dockerCompose.Services[name].(*DockerService).Ports == nil {
-				g.dockerCompose.Services[name].(*DockerService).Ports = []string{}
-			}
 			g.dockerCompose.Services[name].(*DockerService).Ports = append(
 				g.dockerCompose.Services[name].(*DockerService).Ports,
-				fmt.Sprintf("%d", grpcExpose)+":8082",
+				fmt.Sprintf("%d", expose)+":"+t.port,
 			)
+			usedPorts = append(usedPorts, fmt.Sprintf("%d", expose))
 		}
 	}
 	return
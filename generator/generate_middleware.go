@@ -57,7 +57,10 @@ func (g *GenerateMiddleware) Generate() (err error) {
 	if err != nil {
 		return err
 	}
-	g.file, err = parser.NewFileParser().Parse([]byte(svcSrc))
+	g.file, err = parseServiceFile(g.fs, svcSrc, g.filePath)
+	if err != nil {
+		return err
+	}
 	if !g.serviceFound() {
 		return
 	}
@@ -136,7 +139,6 @@ func (g *GenerateMiddleware) generateServiceMiddleware() (err error) {
 	if err != nil {
 		return err
 	}
-	src += "\n" + g.serviceGenerator.code.Raw().GoString()
 	tmpSrc := g.serviceGenerator.srcFile.GoString()
 	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
 	if err != nil {
@@ -147,13 +149,7 @@ func (g *GenerateMiddleware) generateServiceMiddleware() (err error) {
 	if err != nil {
 		return err
 	}
-	if len(imp) > 0 {
-		src, err = g.serviceGenerator.AddImportsToFile(imp, src)
-		if err != nil {
-			return err
-		}
-	}
-	s, err := utils.GoImportsSource(g.serviceGenerator.destPath, src)
+	s, err := g.serviceGenerator.MergeSource(g.serviceGenerator.destPath, src, g.serviceGenerator.code.Raw().GoString(), imp)
 	if err != nil {
 		return err
 	}
@@ -225,7 +221,6 @@ func (g *GenerateMiddleware) generateEndpointMiddleware() (err error) {
 		return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), true)
 	}
 
-	epSrc += "\n" + g.code.Raw().GoString()
 	tmpSrc := g.srcFile.GoString()
 	f, err := parser.NewFileParser().Parse([]byte(tmpSrc))
 	if err != nil {
@@ -236,13 +231,7 @@ func (g *GenerateMiddleware) generateEndpointMiddleware() (err error) {
 	if err != nil {
 		return err
 	}
-	if len(imp) > 0 {
-		epSrc, err = g.AddImportsToFile(imp, epSrc)
-		if err != nil {
-			return err
-		}
-	}
-	s, err := utils.GoImportsSource(g.destPath, epSrc)
+	s, err := g.MergeSource(g.destPath, epSrc, g.code.Raw().GoString(), imp)
 	if err != nil {
 		return err
 	}
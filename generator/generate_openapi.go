@@ -0,0 +1,365 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// GenerateOpenAPI implements Gen and is used to generate the OpenAPI 3 document
+// of the http transport of a service.
+type GenerateOpenAPI struct {
+	BaseGenerator
+	name             string
+	interfaceName    string
+	destPath         string
+	filePath         string
+	serviceFilePath  string
+	endpointFilePath string
+	serviceFile      *parser.File
+	endpointFile     *parser.File
+	serviceInterface parser.Interface
+	document         *OpenAPIDocument
+}
+
+// OpenAPIDocument represents the openapi.yaml.
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `yaml:"openapi"`
+	Info       OpenAPIInfo                             `yaml:"info"`
+	Paths      map[string]map[string]*OpenAPIOperation `yaml:"paths"`
+	Components OpenAPIComponents                       `yaml:"components"`
+}
+
+// OpenAPIInfo represents the info of the document.
+type OpenAPIInfo struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description,omitempty"`
+	Version     string `yaml:"version"`
+}
+
+// OpenAPIOperation represents one route of the http transport.
+type OpenAPIOperation struct {
+	OperationID string                      `yaml:"operationId"`
+	Description string                      `yaml:"description,omitempty"`
+	Parameters  []OpenAPIParameter          `yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIBody                `yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `yaml:"responses"`
+}
+
+// OpenAPIParameter represents a parameter that is read from the path, the query or the headers.
+type OpenAPIParameter struct {
+	Name     string         `yaml:"name"`
+	In       string         `yaml:"in"`
+	Required bool           `yaml:"required,omitempty"`
+	Schema   *OpenAPISchema `yaml:"schema"`
+}
+
+// OpenAPIBody represents the JSON body of a request.
+type OpenAPIBody struct {
+	Required bool                         `yaml:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `yaml:"content"`
+}
+
+// OpenAPIResponse represents a response of a route.
+type OpenAPIResponse struct {
+	Description string                       `yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `yaml:"content,omitempty"`
+}
+
+// OpenAPIMediaType represents the schema of a body.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `yaml:"schema"`
+}
+
+// OpenAPIComponents holds the schemas of the structs of the service package.
+type OpenAPIComponents struct {
+	Schemas map[string]*OpenAPISchema `yaml:"schemas"`
+}
+
+// OpenAPISchema represents the JSON schema of a go type.
+type OpenAPISchema struct {
+	Ref                  string                    `yaml:"$ref,omitempty"`
+	Type                 string                    `yaml:"type,omitempty"`
+	Format               string                    `yaml:"format,omitempty"`
+	Nullable             bool                      `yaml:"nullable,omitempty"`
+	Items                *OpenAPISchema            `yaml:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `yaml:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `yaml:"additionalProperties,omitempty"`
+}
+
+// openAPIBaseTypes maps go types to the JSON schema type and format.
+var openAPIBaseTypes = map[string][2]string{
+	"string":    {"string", ""},
+	"bool":      {"boolean", ""},
+	"int8":      {"integer", "int32"},
+	"int16":     {"integer", "int32"},
+	"int32":     {"integer", "int32"},
+	"rune":      {"integer", "int32"},
+	"int":       {"integer", "int64"},
+	"int64":     {"integer", "int64"},
+	"uint8":     {"integer", "int32"},
+	"byte":      {"integer", "int32"},
+	"uint16":    {"integer", "int32"},
+	"uint32":    {"integer", "int64"},
+	"uint":      {"integer", "int64"},
+	"uint64":    {"integer", "int64"},
+	"float32":   {"number", "float"},
+	"float64":   {"number", "double"},
+	"[]byte":    {"string", "byte"},
+	"error":     {"string", ""},
+	"time.Time": {"string", "date-time"},
+}
+
+// NewGenerateOpenAPI returns an OpenAPI generator.
+func NewGenerateOpenAPI(name string) Gen {
+	i := &GenerateOpenAPI{
+		name:          name,
+		interfaceName: utils.ToCamelCase(name + "Service"),
+		destPath:      fmt.Sprintf(viper.GetString("gk_http_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_openapi_file_name"))
+	i.serviceFilePath = path.Join(
+		fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_service_file_name"),
+	)
+	i.endpointFilePath = path.Join(
+		fmt.Sprintf(viper.GetString("gk_endpoint_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_endpoint_file_name"),
+	)
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate generates the openapi.yaml of the service.
+func (g *GenerateOpenAPI) Generate() (err error) {
+	if b, err := g.fs.Exists(g.serviceFilePath); err != nil {
+		return err
+	} else if !b {
+		logrus.Errorf("Service %s was not found", g.name)
+		return nil
+	}
+	if b, err := g.fs.Exists(g.endpointFilePath); err != nil {
+		return err
+	} else if !b {
+		logrus.Errorf("The endpoints of %s were not found, generate the service first", g.name)
+		return nil
+	}
+	src, err := g.fs.ReadFile(g.serviceFilePath)
+	if err != nil {
+		return err
+	}
+	g.serviceFile, err = parseServiceFile(g.fs, src, g.serviceFilePath)
+	if err != nil {
+		return err
+	}
+	if !g.serviceFound() {
+		return
+	}
+	src, err = g.fs.ReadFile(g.endpointFilePath)
+	if err != nil {
+		return err
+	}
+	g.endpointFile, err = parser.NewFileParser().Parse([]byte(src))
+	if err != nil {
+		return err
+	}
+	g.document = &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       g.interfaceName,
+			Description: strings.TrimSpace(g.serviceInterface.Comment),
+			Version:     "1.0.0",
+		},
+		Paths: map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]*OpenAPISchema{
+				// The payload written by the ErrorEncoder of the http transport.
+				"ErrorWrapper": {
+					Type: "object",
+					Properties: map[string]*OpenAPISchema{
+						"error": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+	for _, m := range g.serviceInterface.Methods {
+		if !g.httpMethod(m) {
+			continue
+		}
+		route, err := newHTTPRoute(m)
+		if err != nil {
+			return err
+		}
+		if g.document.Paths[route.Path] == nil {
+			g.document.Paths[route.Path] = map[string]*OpenAPIOperation{}
+		}
+		g.document.Paths[route.Path][strings.ToLower(route.Method)] = g.operation(m, route)
+	}
+	d, err := yaml.Marshal(g.document)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(g.filePath, string(d), true)
+}
+func (g *GenerateOpenAPI) serviceFound() bool {
+	for _, v := range g.serviceFile.Interfaces {
+		if v.Name == g.interfaceName {
+			g.serviceInterface = v
+			return true
+		}
+	}
+	logrus.Errorf("Could not find the service interface in `%s`", g.name)
+	return false
+}
+
+// httpMethod reports whether the method is served by the http transport.
+func (g *GenerateOpenAPI) httpMethod(m parser.Method) bool {
+	if string(m.Name[0]) == strings.ToLower(string(m.Name[0])) {
+		logrus.Warnf("The method '%s' is private and will be ignored", m.Name)
+		return false
+	}
+	if len(m.Results) == 0 {
+		logrus.Warnf("The method '%s' does not have any return value and will be ignored", m.Name)
+		return false
+	}
+	for _, p := range m.Parameters {
+		if p.Type == "context.Context" {
+			return true
+		}
+	}
+	logrus.Warnf("The method '%s' does not have a context and will be ignored", m.Name)
+	return false
+}
+
+// operation returns the operation of the route of the method.
+func (g *GenerateOpenAPI) operation(m parser.Method, route httpRoute) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		OperationID: m.Name,
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+	op.Description = strings.Join(methodDoc(m), "\n")
+	bound := map[string]bool{}
+	for _, p := range route.Params {
+		bound[p.Name] = true
+		op.Parameters = append(op.Parameters, OpenAPIParameter{
+			Name:     p.Key,
+			In:       p.In,
+			Required: p.In == "path",
+			Schema:   g.schema(p.Type),
+		})
+	}
+	if route.Body {
+		op.RequestBody = &OpenAPIBody{
+			Required: true,
+			Content: map[string]*OpenAPIMediaType{
+				"application/json": {Schema: g.structSchema(m.Name+"Request", bound)},
+			},
+		}
+	}
+	response := &OpenAPIResponse{Description: http.StatusText(route.Status)}
+	if route.Status != 204 {
+		response.Content = map[string]*OpenAPIMediaType{
+			"application/json": {Schema: g.structSchema(m.Name+"Response", nil)},
+		}
+	}
+	op.Responses[fmt.Sprint(route.Status)] = response
+	for _, p := range m.Results {
+		if p.Type == "error" {
+			op.Responses["default"] = &OpenAPIResponse{
+				Description: "The error of the service, the status code is set by err2code.",
+				Content: map[string]*OpenAPIMediaType{
+					"application/json": {Schema: &OpenAPISchema{Ref: "#/components/schemas/ErrorWrapper"}},
+				},
+			}
+			break
+		}
+	}
+	return op
+}
+
+// structSchema returns the schema of the endpoint struct, the errors are not part of
+// the schema because they are written by the ErrorEncoder.
+func (g *GenerateOpenAPI) structSchema(name string, exclude map[string]bool) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+	for _, st := range g.endpointFile.Structures {
+		if st.Name != name {
+			continue
+		}
+		for _, v := range st.Vars {
+			if exclude[v.Name] || v.Type == "error" || strings.Contains(v.Type, "chan ") {
+				continue
+			}
+			if n, ok := openAPIFieldName(v); ok {
+				s.Properties[n] = g.schema(v.Type)
+			}
+		}
+	}
+	return s
+}
+
+// schema returns the schema of the type, the structs of the service package are added
+// to the components of the document.
+func (g *GenerateOpenAPI) schema(tp string) *OpenAPISchema {
+	tp = strings.Replace(tp, "...", "[]", 1)
+	if t, ok := openAPIBaseTypes[tp]; ok {
+		return &OpenAPISchema{Type: t[0], Format: t[1]}
+	}
+	switch {
+	case strings.HasPrefix(tp, "*"):
+		s := g.schema(tp[1:])
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case strings.HasPrefix(tp, "[]"):
+		return &OpenAPISchema{Type: "array", Items: g.schema(tp[2:])}
+	case strings.HasPrefix(tp, "map[string]"):
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(tp[len("map[string]"):])}
+	}
+	// the types of the endpoint structs are qualified with the service package.
+	tp = strings.TrimPrefix(tp, "service.")
+	for _, st := range g.serviceFile.Structures {
+		if st.Name != tp {
+			continue
+		}
+		if _, ok := g.document.Components.Schemas[tp]; !ok {
+			s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+			// Added before the fields so recursive structs end.
+			g.document.Components.Schemas[tp] = s
+			for _, v := range st.Vars {
+				if n, ok := openAPIFieldName(v); ok {
+					s.Properties[n] = g.schema(v.Type)
+				}
+			}
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + tp}
+	}
+	// Any value.
+	return &OpenAPISchema{}
+}
+
+// openAPIFieldName returns the JSON name of the struct field, the same way encoding/json does.
+func openAPIFieldName(v parser.NamedTypeValue) (string, bool) {
+	if v.Name[:1] != strings.ToUpper(v.Name[:1]) {
+		return "", false
+	}
+	n := strings.Split(reflect.StructTag(v.Tag).Get("json"), ",")[0]
+	if n == "-" {
+		return "", false
+	}
+	if n == "" {
+		n = v.Name
+	}
+	return n, true
+}
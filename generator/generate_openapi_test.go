@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGenerateOpenAPI_Generate(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("openapi_svc/pkg/service/service.go", `package service
+
+import "context"
+
+type User struct {
+	Name    string   `+"`json:\"name\"`"+`
+	Friends []*User  `+"`json:\"friends\"`"+`
+	secret  string
+}
+
+// OpenapiSvcService describes the service.
+type OpenapiSvcService interface {
+	// GetUser returns a user.
+	// @http GET /users/{id}
+	GetUser(ctx context.Context, id int) (user User, err error)
+	// @http GET /users
+	ListUsers(ctx context.Context) (users []User, err error)
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`, true)
+	fs.Get().WriteFile("openapi_svc/pkg/endpoint/endpoint.go", `package endpoint
+
+type GetUserRequest struct {
+	Id int `+"`json:\"id\"`"+`
+}
+type GetUserResponse struct {
+	User service.User `+"`json:\"user\"`"+`
+	Err  error        `+"`json:\"err\"`"+`
+}
+type ListUsersRequest struct{}
+type ListUsersResponse struct {
+	Users []service.User `+"`json:\"users\"`"+`
+	Err   error          `+"`json:\"err\"`"+`
+}
+type FooRequest struct {
+	S string `+"`json:\"s\"`"+`
+}
+type FooResponse struct {
+	R   string `+"`json:\"r\"`"+`
+	Err error  `+"`json:\"err\"`"+`
+}
+`, true)
+	err := NewGenerateOpenAPI("openapi_svc").Generate()
+	Convey("Test if the OpenAPI document is generated", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := fs.Get().ReadFile("openapi_svc/pkg/http/openapi.yaml")
+		So(f, ShouldContainSubstring, "openapi: 3.0.3")
+		So(f, ShouldContainSubstring, "  /users/{id}:\n    get:\n      operationId: GetUser\n      description: GetUser returns a user.")
+		So(f, ShouldContainSubstring, "      - name: id\n        in: path\n        required: true")
+		So(f, ShouldContainSubstring, "  /foo:\n    post:")
+		So(f, ShouldContainSubstring, "$ref: '#/components/schemas/User'")
+		So(f, ShouldContainSubstring, "$ref: '#/components/schemas/ErrorWrapper'")
+		So(f, ShouldContainSubstring, "        friends:\n          type: array\n          items:\n            $ref: '#/components/schemas/User'")
+		So(f, ShouldContainSubstring, "                  users:\n                    type: array\n                    items:\n                      $ref: '#/components/schemas/User'")
+		So(f, ShouldNotContainSubstring, "secret")
+		So(f, ShouldNotContainSubstring, "err:")
+	})
+}
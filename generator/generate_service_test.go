@@ -1 +1,154 @@
 package generator
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_methodDoc(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    []string
+	}{
+		{"No comment", "", []string{}},
+		{"One line", "Foo does foo.\n", []string{"Foo does foo."}},
+		{"Annotations", "Foo does foo.\n@http GET /foo\n@http-status 201\n", []string{"Foo does foo."}},
+		{"Annotations first", "@http GET /foo\n\nFoo does foo.\n", []string{"Foo does foo."}},
+		{"Paragraphs", "Foo does foo.\n\nIt is fast.\n", []string{"Foo does foo.", "", "It is fast."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := methodDoc(parser.Method{Comment: tt.comment}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("methodDoc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateService_MethodDoc(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("doc_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// DocSvcService greets people.
+type DocSvcService interface {
+	// Foo greets the user.
+	// @http GET /foo
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`, true)
+	Convey("Test if the doc comments of the methods are carried into the generated code", t, func() {
+		So(NewGenerateService("doc_svc", "http", false, true, false, nil).Generate(), ShouldBeNil)
+		So(NewGenerateTransport("doc_svc", false, "grpc", nil).Generate(), ShouldBeNil)
+		for pth, want := range map[string]string{
+			"doc_svc/pkg/service/service.go":    "// Foo greets the user.\nfunc (b *basicDocSvcService) Foo(",
+			"doc_svc/pkg/endpoint/endpoint.go":  "// FooRequest collects the request parameters for the Foo method.\n//\n// Foo greets the user.\ntype FooRequest",
+			"doc_svc/pkg/http/handler.go":       "// makeFooHandler creates the handler logic\n//\n// Foo greets the user.\nfunc makeFooHandler(",
+			"doc_svc/pkg/grpc/pb/doc_svc.proto": "// DocSvcService greets people.\nservice DocSvc {\n // Foo greets the user.\n rpc Foo",
+		} {
+			src, err := fs.Get().ReadFile(pth)
+			So(err, ShouldBeNil)
+			So(src, ShouldContainSubstring, want)
+			if pth != "doc_svc/pkg/service/service.go" {
+				So(src, ShouldNotContainSubstring, "@http")
+			}
+		}
+		src, _ := fs.Get().ReadFile("doc_svc/pkg/endpoint/endpoint.go")
+		So(src, ShouldContainSubstring, "// Foo implements Service. Primarily useful in a client.\n//\n// Foo greets the user.\nfunc (e Endpoints) Foo(")
+	})
+}
+
+func TestGenerateCmd_TransportFlags(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("flags_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// FlagsSvcService describes the service.
+type FlagsSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`, true)
+	Convey("Test if the flags of the other transports do not import their packages", t, func() {
+		So(NewGenerateService("flags_svc", "http", false, false, false, nil).Generate(), ShouldBeNil)
+		src, err := fs.Get().ReadFile("flags_svc/cmd/service/service.go")
+		So(err, ShouldBeNil)
+		So(src, ShouldContainSubstring, `fs.String("nats-url", "nats://127.0.0.1:4222", "NATS server url")`)
+		So(src, ShouldNotContainSubstring, "github.com/nats-io/nats.go")
+	})
+}
+
+func TestGenerateCmd_AddTransport(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("cmd_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// CmdSvcService describes the service.
+type CmdSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`, true)
+	Convey("Test if the service main compiles after a transport is added to it", t, func() {
+		So(NewGenerateService("cmd_svc", "http", true, false, true, nil).Generate(), ShouldBeNil)
+		So(NewGenerateService("cmd_svc", "jsonrpc", true, false, true, nil).Generate(), ShouldBeNil)
+		fset := token.NewFileSet()
+		files := []*ast.File{}
+		for _, pth := range []string{"cmd_svc/cmd/service/service.go", "cmd_svc/cmd/service/service_gen.go"} {
+			src, err := fs.Get().ReadFile(pth)
+			So(err, ShouldBeNil)
+			f, err := goparser.ParseFile(fset, pth, src, 0)
+			So(err, ShouldBeNil)
+			files = append(files, f)
+		}
+		So(undeclaredNames(fset, files), ShouldBeEmpty)
+		src, _ := fs.Get().ReadFile("cmd_svc/cmd/service/service.go")
+		So(src, ShouldContainSubstring, "func initJSONRPCHandler(endpoints endpoint.Endpoints, g *group.Group) {")
+	})
+}
+
+// undeclaredNames type checks the files and returns the errors of the names that are
+// not declared, the imported packages are empty so the selectors on them are ignored.
+func undeclaredNames(fset *token.FileSet, files []*ast.File) []string {
+	errs := []string{}
+	conf := types.Config{
+		Importer: importerFunc(func(pth string) (*types.Package, error) {
+			name := path.Base(pth)
+			if i := strings.IndexAny(name, ".-"); i != -1 {
+				name = name[:i]
+			}
+			pkg := types.NewPackage(pth, name)
+			pkg.MarkComplete()
+			return pkg, nil
+		}),
+		Error: func(err error) {
+			println("ERR", err.Error())
+			msg := err.(types.Error).Msg
+			// the selectors on the packages are reported as `undefined: pkg.Name`.
+			undefined := strings.HasPrefix(msg, "undefined: ") && !strings.Contains(msg, ".")
+			if undefined || strings.HasPrefix(msg, "undeclared name") || strings.Contains(msg, "redeclared") {
+				errs = append(errs, err.Error())
+			}
+		},
+	}
+	conf.Check("service", fset, files, nil)
+	return errs
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
@@ -10,9 +10,6 @@ import (
 
 	"strconv"
 
-	"bytes"
-	"go/format"
-
 	"github.com/dave/jennifer/jen"
 	"github.com/kujtimiihoxha/kit/fs"
 	"github.com/kujtimiihoxha/kit/parser"
@@ -87,7 +84,7 @@ func (b *BaseGenerator) GenerateNameBySample(sample string, exclude []parser.Nam
 		if v.Name == name {
 			sn++
 			if sn > len(sample) {
-				sample = string(len(sample) - sn)
+				sample = string(rune(len(sample) - sn))
 			}
 			name = utils.ToLowerFirstCamelCase(sample)[:sn]
 		}
@@ -95,89 +92,97 @@ func (b *BaseGenerator) GenerateNameBySample(sample string, exclude []parser.Nam
 	return name
 }
 
-// EnsureThatWeUseQualifierIfNeeded is used to see if we need to import a path of a given type.
-func (b *BaseGenerator) EnsureThatWeUseQualifierIfNeeded(tp string, imp []parser.NamedTypeValue) string {
-	if bytes.HasPrefix([]byte(tp), []byte("...")) {
-		return ""
-	}
-	if t := strings.Split(tp, "."); len(t) > 0 {
-		s := t[0]
-		for _, v := range imp {
-			i, _ := strconv.Unquote(v.Type)
-			if strings.HasSuffix(i, s) || v.Name == s {
-				return i
-			}
-		}
-		return ""
+// qualifiedType returns the code of a type of the service, the qualified identifiers
+// are imported from the paths of their qualifiers in `imports` (the Qualifiers of the
+// service file). The exported identifiers that are not qualified are the types of the
+// service package `pkg`, they are not imported if `pkg` is empty e.x in the service package.
+func qualifiedType(tp string, imports []parser.NamedTypeValue, pkg string) *jen.Statement {
+	if strings.HasPrefix(tp, "...") {
+		return jen.Op("...").Add(qualifiedType(tp[3:], imports, pkg))
 	}
-	return ""
-}
-
-// AddImportsToFile adds missing imports toa file that we edit with the generator
-func (b *BaseGenerator) AddImportsToFile(imp []parser.NamedTypeValue, src string) (string, error) {
-	// Create the AST by parsing src
 	fset := token.NewFileSet()
-	f, err := ps.ParseFile(fset, "", src, 0)
+	e, err := ps.ParseExprFrom(fset, "", tp, 0)
 	if err != nil {
-		return "", err
-	}
-	found := false
-	// Add the imports
-	for i := 0; i < len(f.Decls); i++ {
-		d := f.Decls[i]
-		switch d.(type) {
-		case *ast.FuncDecl:
-			// No action
-		case *ast.GenDecl:
-			dd := d.(*ast.GenDecl)
-
-			// IMPORT Declarations
-			if dd.Tok == token.IMPORT {
-				if dd.Rparen == 0 || dd.Lparen == 0 {
-					dd.Rparen = f.Package
-					dd.Lparen = f.Package
+		return jen.Id(tp)
+	}
+	paths := map[string]string{}
+	for _, v := range imports {
+		paths[v.Name], _ = strconv.Unquote(v.Type)
+	}
+	type ref struct {
+		pos, end  token.Pos
+		pth, name string
+	}
+	refs := []ref{}
+	var find func(n ast.Node)
+	find = func(n ast.Node) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if x, ok := n.X.(*ast.Ident); ok && paths[x.Name] != "" {
+					refs = append(refs, ref{n.Pos(), n.End(), paths[x.Name], n.Sel.Name})
 				}
-				found = true
-				// Add the new import
-				for _, v := range imp {
-					iSpec := &ast.ImportSpec{
-						Name: &ast.Ident{Name: v.Name},
-						Path: &ast.BasicLit{Value: v.Type},
-					}
-					dd.Specs = append(dd.Specs, iSpec)
+				return false
+			case *ast.Field:
+				// the names of the fields and of the parameters are not types.
+				find(n.Type)
+				return false
+			case *ast.Ident:
+				if pkg != "" && ast.IsExported(n.Name) {
+					refs = append(refs, ref{n.Pos(), n.End(), pkg, n.Name})
 				}
 			}
+			return true
+		})
+	}
+	find(e)
+	if len(refs) == 0 {
+		return jen.Id(tp)
+	}
+	code := &jen.Statement{}
+	last := 0
+	for _, r := range refs {
+		if pos := fset.Position(r.pos).Offset; pos > last {
+			code.Op(tp[last:pos])
 		}
+		code.Qual(r.pth, r.name)
+		last = fset.Position(r.end).Offset
 	}
-	if !found {
-		dd := ast.GenDecl{
-			TokPos: f.Package + 1,
-			Tok:    token.IMPORT,
-			Specs:  []ast.Spec{},
-			Lparen: f.Package,
-			Rparen: f.Package,
-		}
-		lastPos := 0
-		for _, v := range imp {
-			lastPos += len(v.Name) + len(v.Type) + 1
-			iSpec := &ast.ImportSpec{
-				Name:   &ast.Ident{Name: v.Name},
-				Path:   &ast.BasicLit{Value: v.Type},
-				EndPos: token.Pos(lastPos),
-			}
-			dd.Specs = append(dd.Specs, iSpec)
+	if last < len(tp) {
+		code.Op(tp[last:])
+	}
+	return code
+}
 
+// methodDoc returns the lines of the doc comment of the method without the
+// annotations e.x `@http GET /users/{id}`.
+func methodDoc(m parser.Method) []string {
+	doc := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(m.Comment), "\n") {
+		if !strings.HasPrefix(line, "@http") && (line != "" || len(doc) > 0) {
+			doc = append(doc, line)
 		}
-		f.Decls = append([]ast.Decl{&dd}, f.Decls...)
 	}
+	// the blank lines left by the annotations at the end of the comment.
+	for len(doc) > 0 && doc[len(doc)-1] == "" {
+		doc = doc[:len(doc)-1]
+	}
+	return doc
+}
 
-	// Sort the imports
-	ast.SortImports(fset, f)
-	var buf bytes.Buffer
-	if err := format.Node(&buf, fset, f); err != nil {
-		return "", err
+// withMethodDoc appends the doc comment of the method to the comment lines of the
+// code generated for it.
+func withMethodDoc(lines []string, m parser.Method) []string {
+	if doc := methodDoc(m); len(doc) > 0 {
+		return append(append(lines, ""), doc...)
 	}
-	return fmt.Sprintf("%s", buf.Bytes()), nil
+	return lines
+}
+
+// AddImportsToFile adds missing imports toa file that we edit with the generator, the
+// imports are inserted in the source so the comments and the formatting are kept.
+func (b *BaseGenerator) AddImportsToFile(imp []parser.NamedTypeValue, src string) (string, error) {
+	return insertImports(src, imp)
 }
 
 // PartialGenerator wraps a jen statement
@@ -1,10 +1,14 @@
 package generator
 
 import (
+	"fmt"
 	"path"
+	"strings"
+	"testing"
 
 	"runtime"
 
+	"github.com/dave/jennifer/jen"
 	"github.com/kujtimiihoxha/kit/parser"
 	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/spf13/viper"
@@ -21,6 +25,15 @@ func setDefaults() {
 	viper.SetDefault("gk_client_cmd_path_format", path.Join("%s", "cmd", "client"))
 	viper.SetDefault("gk_grpc_path_format", path.Join("%s", "pkg", "grpc"))
 	viper.SetDefault("gk_grpc_pb_path_format", path.Join("%s", "pkg", "grpc", "pb"))
+	viper.SetDefault("gk_thrift_path_format", path.Join("%s", "pkg", "thrift"))
+	viper.SetDefault("gk_thrift_gen_path_format", path.Join("%s", "pkg", "thrift", "gen"))
+	viper.SetDefault("gk_thrift_client_path_format", path.Join("%s", "client", "thrift"))
+	viper.SetDefault("gk_nats_path_format", path.Join("%s", "pkg", "nats"))
+	viper.SetDefault("gk_nats_client_path_format", path.Join("%s", "client", "nats"))
+	viper.SetDefault("gk_amqp_path_format", path.Join("%s", "pkg", "amqp"))
+	viper.SetDefault("gk_amqp_client_path_format", path.Join("%s", "client", "amqp"))
+	viper.SetDefault("gk_jsonrpc_path_format", path.Join("%s", "pkg", "jsonrpc"))
+	viper.SetDefault("gk_jsonrpc_client_path_format", path.Join("%s", "client", "jsonrpc"))
 
 	viper.SetDefault("gk_service_file_name", "service.go")
 	viper.SetDefault("gk_service_middleware_file_name", "middleware.go")
@@ -36,12 +49,29 @@ func setDefaults() {
 	viper.SetDefault("gk_grpc_pb_file_name", "%s.proto")
 	viper.SetDefault("gk_grpc_base_file_name", "handler_gen.go")
 	viper.SetDefault("gk_grpc_file_name", "handler.go")
+	viper.SetDefault("gk_thrift_idl_file_name", "%s.thrift")
+	viper.SetDefault("gk_thrift_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_thrift_file_name", "handler.go")
+	viper.SetDefault("gk_thrift_client_file_name", "thrift.go")
+	viper.SetDefault("gk_nats_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_nats_file_name", "handler.go")
+	viper.SetDefault("gk_nats_client_file_name", "nats.go")
+	viper.SetDefault("gk_amqp_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_amqp_file_name", "handler.go")
+	viper.SetDefault("gk_amqp_client_file_name", "amqp.go")
+	viper.SetDefault("gk_jsonrpc_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_jsonrpc_file_name", "handler.go")
+	viper.SetDefault("gk_jsonrpc_client_file_name", "jsonrpc.go")
+	viper.SetDefault("gk_openapi_file_name", "openapi.yaml")
 	if runtime.GOOS == "windows" {
 		viper.SetDefault("gk_grpc_compile_file_name", "compile.bat")
+		viper.SetDefault("gk_thrift_compile_file_name", "compile.bat")
 	} else {
 		viper.SetDefault("gk_grpc_compile_file_name", "compile.sh")
+		viper.SetDefault("gk_thrift_compile_file_name", "compile.sh")
 	}
 	viper.SetDefault("gk_service_struct_prefix", "basic")
+	viper.SetDefault("gk_json_naming", "snake")
 	viper.Set("gk_testing", true)
 
 }
@@ -68,3 +98,44 @@ func getTestServiceInterface(name string) parser.Interface {
 		),
 	})
 }
+
+func Test_qualifiedType(t *testing.T) {
+	imports := []parser.NamedTypeValue{
+		parser.NewNameType("time", `"time"`),
+		parser.NewNameType("models", `"example.com/a/models"`),
+		parser.NewNameType("models1", `"example.com/b/models"`),
+	}
+	tests := []struct {
+		tp   string
+		pkg  string
+		want string
+	}{
+		{"string", "example.com/svc", "string"},
+		{"User", "example.com/svc", "service.User"},
+		{"User", "", "User"},
+		{"*User", "example.com/svc", "*service.User"},
+		{"...User", "example.com/svc", "...service.User"},
+		{"map[string]User", "example.com/svc", "map[string]service.User"},
+		{"<-chan *Event", "example.com/svc", "<-chan *service.Event"},
+		{"time.Time", "", "time.Time"},
+		{"[]models.User", "", "[]models.User"},
+		{"map[models.ID]models1.User", "", "map[models.ID]models1.User"},
+		{"[Size]User", "example.com/svc", "[service.Size]service.User"},
+		{"Pair[string, *Item]", "example.com/svc", "service.Pair[string, *service.Item]"},
+		{"func(u User) (models.Item, error)", "example.com/svc", "func(u service.User) (models.Item, error)"},
+		{"unknown.Type", "", "unknown.Type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tp, func(t *testing.T) {
+			pkg := tt.pkg
+			if pkg != "" {
+				pkg += "/service"
+			}
+			code := jen.Func().Id("f").Params(jen.Id("v").Add(qualifiedType(tt.tp, imports, pkg)))
+			got := strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%#v", code), "func f(v "), ")")
+			if got != tt.want {
+				t.Errorf("qualifiedType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
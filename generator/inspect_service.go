@@ -0,0 +1,356 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// methodReference matches the identifiers that kit generates once per service method,
+// besides the functions of generatedMethodFunc it matches the endpoint fields and variables.
+var methodReference = regexp.MustCompile(`^(?:Make(\w+)Endpoint|make(\w+)Handler|make(\w+)Codec|([A-Z]\w*)Endpoint)$`)
+
+// ServiceModel describes a service of the project as it is found on disk.
+type ServiceModel struct {
+	Name        string           `json:"name"`
+	Interface   string           `json:"interface"`
+	Methods     []MethodModel    `json:"methods"`
+	Transports  []string         `json:"transports"`
+	Clients     []string         `json:"clients"`
+	Middlewares MiddlewaresModel `json:"middlewares"`
+	Stale       []StaleFile      `json:"stale"`
+}
+
+// MethodModel describes a method of the service interface.
+type MethodModel struct {
+	Name       string       `json:"name"`
+	Parameters []ParamModel `json:"parameters"`
+	Results    []ParamModel `json:"results"`
+}
+
+// ParamModel is a parameter or a result of a method.
+type ParamModel struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// MiddlewaresModel lists the service and the endpoint middlewares of the service.
+type MiddlewaresModel struct {
+	Service  []MiddlewareModel `json:"service"`
+	Endpoint []MiddlewareModel `json:"endpoint"`
+}
+
+// MiddlewareModel is a middleware defined in the service, Wired is true if it is
+// used in cmd/service.
+type MiddlewareModel struct {
+	Name  string `json:"name"`
+	Wired bool   `json:"wired"`
+}
+
+// StaleFile is a generated file that does not match the service interface, Missing
+// are the methods it has no code for and Extra are the methods that were removed from
+// the interface.
+type StaleFile struct {
+	Path    string   `json:"path"`
+	Missing []string `json:"missing"`
+	Extra   []string `json:"extra"`
+}
+
+// InspectServices returns the model of the given services, if no name is given all
+// the services found in the project are inspected.
+func InspectServices(names ...string) ([]ServiceModel, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = findServices(); err != nil {
+			return nil, err
+		}
+	}
+	models := []ServiceModel{}
+	for _, name := range names {
+		m, err := inspectService(name)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+	return models, nil
+}
+
+// findServices returns the folders of the project that have a service file.
+func findServices() ([]string, error) {
+	kfs := fs.Get()
+	infos, err := afero.ReadDir(kfs.Fs, ".")
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		svcPath := path.Join(
+			fmt.Sprintf(viper.GetString("gk_service_path_format"), info.Name()),
+			viper.GetString("gk_service_file_name"),
+		)
+		if b, err := kfs.Exists(svcPath); err != nil {
+			return nil, err
+		} else if b {
+			names = append(names, info.Name())
+		}
+	}
+	return names, nil
+}
+
+func inspectService(name string) (m ServiceModel, err error) {
+	g := NewSyncService(name).(*SyncService)
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return m, err
+	} else if !b {
+		return m, fmt.Errorf("service %s was not found", name)
+	}
+	svcSrc, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return m, err
+	}
+	g.file, err = parseServiceFile(g.fs, svcSrc, g.filePath)
+	if err != nil {
+		return m, err
+	}
+	if !g.serviceFound() {
+		return m, fmt.Errorf("could not find the service interface in `%s`", name)
+	}
+	g.removeBadMethods()
+	m = ServiceModel{
+		Name:        name,
+		Interface:   g.interfaceName,
+		Methods:     []MethodModel{},
+		Transports:  []string{},
+		Clients:     []string{},
+		Middlewares: MiddlewaresModel{Service: []MiddlewareModel{}, Endpoint: []MiddlewareModel{}},
+		Stale:       []StaleFile{},
+	}
+	for _, v := range g.serviceInterface.Methods {
+		m.Methods = append(m.Methods, MethodModel{
+			Name:       v.Name,
+			Parameters: paramModels(v.Parameters),
+			Results:    paramModels(v.Results),
+		})
+	}
+	n := utils.ToLowerSnakeCase(name)
+	for _, t := range SupportedTransports {
+		handler := path.Join(
+			fmt.Sprintf(viper.GetString("gk_"+t+"_path_format"), n),
+			viper.GetString("gk_"+t+"_file_name"),
+		)
+		if b, err := g.fs.Exists(handler); err != nil {
+			return m, err
+		} else if b {
+			m.Transports = append(m.Transports, t)
+		}
+		if b, err := g.fs.Exists(fmt.Sprintf(viper.GetString("gk_"+t+"_client_path_format"), n)); err != nil {
+			return m, err
+		} else if b {
+			m.Clients = append(m.Clients, t)
+		}
+	}
+	if m.Middlewares, err = inspectMiddlewares(g.fs, n); err != nil {
+		return m, err
+	}
+	m.Stale, err = staleFiles(g)
+	return m, err
+}
+
+func paramModels(tps []parser.NamedTypeValue) []ParamModel {
+	ps := []ParamModel{}
+	for _, p := range tps {
+		ps = append(ps, ParamModel{Name: p.Name, Type: p.Type})
+	}
+	return ps
+}
+
+// inspectMiddlewares returns the middlewares defined in the service and the endpoint
+// middleware files, a middleware is wired if it is called in the files of cmd/service.
+func inspectMiddlewares(kfs *fs.KitFs, n string) (m MiddlewaresModel, err error) {
+	cmdPath := fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), n)
+	wiring := map[string]string{
+		"getServiceMiddleware":              "service",
+		"addDefaultServiceMiddleware":       "service",
+		"getEndpointMiddleware":             "endpoint",
+		"addDefaultEndpointMiddleware":      "endpoint",
+		"addEndpointMiddlewareToAllMethods": "endpoint",
+	}
+	bodies := map[string]string{}
+	for _, f := range []string{viper.GetString("gk_cmd_svc_file_name"), viper.GetString("gk_cmd_base_file_name")} {
+		file, err := parseFile(kfs, path.Join(cmdPath, f))
+		if err != nil || file == nil {
+			return m, err
+		}
+		for _, v := range file.Methods {
+			if k, ok := wiring[v.Name]; ok {
+				bodies[k] += v.Body
+			}
+		}
+	}
+	if m.Service, err = definedMiddlewares(
+		kfs,
+		path.Join(
+			fmt.Sprintf(viper.GetString("gk_service_path_format"), n),
+			viper.GetString("gk_service_middleware_file_name"),
+		),
+		"Middleware",
+		bodies["service"],
+	); err != nil {
+		return m, err
+	}
+	m.Endpoint, err = definedMiddlewares(
+		kfs,
+		path.Join(
+			fmt.Sprintf(viper.GetString("gk_endpoint_path_format"), n),
+			viper.GetString("gk_endpoint_middleware_file_name"),
+		),
+		"endpoint.Middleware",
+		bodies["endpoint"],
+	)
+	return m, err
+}
+
+// definedMiddlewares returns the functions of the file at `pth` that return a `tp`,
+// `wiring` is the code where the middlewares are wired.
+func definedMiddlewares(kfs *fs.KitFs, pth, tp, wiring string) ([]MiddlewareModel, error) {
+	mdw := []MiddlewareModel{}
+	file, err := parseFile(kfs, pth)
+	if err != nil || file == nil {
+		return mdw, err
+	}
+	for _, v := range file.Methods {
+		if v.Struct.Type != "" || len(v.Results) != 1 || v.Results[0].Type != tp {
+			continue
+		}
+		wired, _ := regexp.MatchString(`\b`+regexp.QuoteMeta(v.Name)+`\(`, wiring)
+		mdw = append(mdw, MiddlewareModel{Name: v.Name, Wired: wired})
+	}
+	return mdw, nil
+}
+
+// parseFile parses the file at `pth`, it returns nil if the file does not exist.
+func parseFile(kfs *fs.KitFs, pth string) (*parser.File, error) {
+	if b, err := kfs.Exists(pth); err != nil || !b {
+		return nil, err
+	}
+	src, err := kfs.ReadFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	return parser.NewFileParser().Parse([]byte(src))
+}
+
+// staleFiles returns the go files of the service layers and of cmd/service that
+// have code for a different set of methods than the service interface.
+func staleFiles(g *SyncService) ([]StaleFile, error) {
+	current := map[string]bool{}
+	for _, v := range g.serviceInterface.Methods {
+		current[v.Name] = true
+	}
+	n := utils.ToLowerSnakeCase(g.name)
+	dirs := append(g.layerPaths(), fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), n))
+	stale := []StaleFile{}
+	for _, dir := range dirs {
+		if b, err := g.fs.Exists(dir); err != nil {
+			return nil, err
+		} else if !b {
+			continue
+		}
+		infos, err := afero.ReadDir(g.fs.Fs, dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") || strings.HasSuffix(info.Name(), "_test.go") {
+				continue
+			}
+			pth := path.Join(dir, info.Name())
+			if pth == g.filePath {
+				continue
+			}
+			src, err := g.fs.ReadFile(pth)
+			if err != nil {
+				return nil, err
+			}
+			refs, err := methodReferences(pth, src)
+			if err != nil {
+				return nil, err
+			}
+			if len(refs) == 0 {
+				continue
+			}
+			sf := StaleFile{Path: pth, Missing: []string{}, Extra: []string{}}
+			for _, v := range g.serviceInterface.Methods {
+				if !refs[v.Name] {
+					sf.Missing = append(sf.Missing, v.Name)
+				}
+			}
+			for k := range refs {
+				if !current[k] {
+					sf.Extra = append(sf.Extra, k)
+				}
+			}
+			sort.Strings(sf.Extra)
+			if len(sf.Missing) > 0 || len(sf.Extra) > 0 {
+				stale = append(stale, sf)
+			}
+		}
+	}
+	return stale, nil
+}
+
+// methodReferences returns the names of the methods the go source has generated code for,
+// the per method functions and endpoints, the methods of the middleware structs and
+// the method names used as map keys in the options and middleware maps.
+func methodReferences(name, src string) (map[string]bool, error) {
+	f, err := goparser.ParseFile(token.NewFileSet(), name, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	refs := map[string]bool{}
+	key := func(e ast.Expr) {
+		if l, ok := e.(*ast.BasicLit); ok && l.Kind == token.STRING {
+			if s, err := strconv.Unquote(l.Value); err == nil && identifierRegexp.MatchString(s) {
+				refs[s] = true
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Recv != nil && strings.HasSuffix(receiverType(n.Recv), "Middleware") {
+				refs[n.Name.Name] = true
+			}
+		case *ast.Ident:
+			if m := methodReference.FindStringSubmatch(n.Name); m != nil {
+				for _, v := range m[1:] {
+					if v != "" {
+						refs[v] = true
+					}
+				}
+			}
+		case *ast.KeyValueExpr:
+			key(n.Key)
+		case *ast.IndexExpr:
+			key(n.Index)
+		}
+		return true
+	})
+	return refs, nil
+}
@@ -0,0 +1,65 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInspectServices(t *testing.T) {
+	setDefaults()
+	svc := `package service
+
+import "context"
+
+// InspectSvcService describes the service.
+type InspectSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+	Bar(ctx context.Context, n int) (i int, err error)
+}
+`
+	Convey("Test if the service model is reported", t, func() {
+		// the fixture is written again for every leaf, the stale leaf changes the interface.
+		fs.Get().WriteFile("inspect_svc/pkg/service/service.go", svc, true)
+		So(NewGenerateService("inspect_svc", "http", true, false, true, nil).Generate(), ShouldBeNil)
+		models, err := InspectServices("inspect_svc")
+		So(err, ShouldBeNil)
+		So(models, ShouldHaveLength, 1)
+		m := models[0]
+		So(m.Interface, ShouldEqual, "InspectSvcService")
+		So(m.Methods, ShouldHaveLength, 2)
+		So(m.Methods[1].Name, ShouldEqual, "Bar")
+		So(m.Methods[1].Parameters, ShouldResemble, []ParamModel{{"ctx", "context.Context"}, {"n", "int"}})
+		So(m.Transports, ShouldResemble, []string{"http"})
+		So(m.Middlewares.Service, ShouldResemble, []MiddlewareModel{{"LoggingMiddleware", true}})
+		So(m.Middlewares.Endpoint, ShouldHaveLength, 2)
+		So(m.Stale, ShouldBeEmpty)
+		Convey("Test if the files that do not match the interface are stale", func() {
+			fs.Get().WriteFile(
+				"inspect_svc/pkg/service/service.go",
+				strings.Replace(svc, "\tBar(ctx context.Context, n int) (i int, err error)\n", "\tBaz(ctx context.Context) (err error)\n", 1),
+				true,
+			)
+			models, err := InspectServices("inspect_svc")
+			So(err, ShouldBeNil)
+			So(models[0].Stale, ShouldNotBeEmpty)
+			for _, s := range models[0].Stale {
+				So(s.Missing, ShouldResemble, []string{"Baz"})
+				So(s.Extra, ShouldResemble, []string{"Bar"})
+			}
+			paths := []string{}
+			for _, s := range models[0].Stale {
+				paths = append(paths, s.Path)
+			}
+			So(paths, ShouldContain, "inspect_svc/pkg/endpoint/endpoint.go")
+			So(paths, ShouldContain, "inspect_svc/pkg/http/handler_gen.go")
+			So(paths, ShouldContain, "inspect_svc/cmd/service/service_gen.go")
+		})
+		Convey("Test if an unknown service is an error", func() {
+			_, err := InspectServices("not_a_svc")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
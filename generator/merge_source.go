@@ -0,0 +1,440 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+)
+
+// MergeSource appends the generated declarations `code` to the source of a file that
+// already exists and adds the imports they need. The source is not printed again from
+// the AST like goimports does, the new imports and declarations are inserted as text
+// so the comments and the formatting of the existing code are kept byte for byte.
+// SyncService and RenameMethod keep the files the same way, the declarations of the
+// stale methods are cut with the imports removeImports drops and the renamed names are
+// replaced as text.
+//
+// The imports in `imp` are the imports of the generated code, goimports decides which
+// of them are used and finds the ones that are missing.
+func (b *BaseGenerator) MergeSource(path, src, code string, imp []parser.NamedTypeValue) (string, error) {
+	code, err := formatDecls(code)
+	if err != nil {
+		return "", err
+	}
+	merged := src
+	if code != "" {
+		if !strings.HasSuffix(merged, "\n") {
+			merged += "\n"
+		}
+		merged += "\n" + code
+	}
+	probe, err := b.AddImportsToFile(imp, merged)
+	if err != nil {
+		return "", err
+	}
+	needed, err := neededImports(path, probe)
+	if err != nil {
+		return "", err
+	}
+	return insertImports(merged, needed)
+}
+
+// neededImports returns the imports goimports keeps or adds to the source.
+func neededImports(path, src string) ([]parser.NamedTypeValue, error) {
+	probe, err := utils.GoImportsSource(path, src)
+	if err != nil {
+		return nil, err
+	}
+	f, err := goparser.ParseFile(token.NewFileSet(), "", probe, goparser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	needed := []parser.NamedTypeValue{}
+	for _, s := range f.Imports {
+		v := parser.NamedTypeValue{Type: s.Path.Value}
+		if s.Name != nil {
+			v.Name = s.Name.Name
+		}
+		needed = append(needed, v)
+	}
+	return needed, nil
+}
+
+// renderCode renders the generated declarations that will be merged into `src` and
+// returns them with their imports. The packages `src` already imports keep the names
+// they have there and jennifer gives the other packages names that do not clash with
+// them, so the code can be merged without renaming anything.
+func renderCode(code *jen.Statement, src string) (string, []parser.NamedTypeValue, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "", src, goparser.ImportsOnly)
+	if err != nil {
+		return "", nil, err
+	}
+	// the imports the code needs with the names jennifer would give them.
+	probe := jen.NewFile("p")
+	probe.Add(code)
+	pf, err := goparser.ParseFile(fset, "", probe.GoString(), goparser.ImportsOnly)
+	if err != nil {
+		return "", nil, err
+	}
+	file := jen.NewFile("p")
+	taken := map[string]bool{}
+	existing := map[string]string{}
+	for _, s := range f.Imports {
+		name := ""
+		if s.Name != nil {
+			name = s.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		p, _ := strconv.Unquote(s.Path.Value)
+		existing[s.Path.Value] = name
+		taken[importName(name, s.Path.Value)] = true
+		if name != "" {
+			file.ImportAlias(p, name)
+		} else {
+			file.ImportName(p, importName("", s.Path.Value))
+		}
+	}
+	imp := []parser.NamedTypeValue{}
+	for _, s := range pf.Imports {
+		if name, ok := existing[s.Path.Value]; ok {
+			imp = append(imp, parser.NamedTypeValue{Name: name, Type: s.Path.Value})
+			continue
+		}
+		v := parser.NamedTypeValue{Type: s.Path.Value}
+		if s.Name != nil {
+			v.Name = s.Name.Name
+		}
+		name := importName(v.Name, v.Type)
+		unique := name
+		for i := 1; taken[unique]; i++ {
+			unique = fmt.Sprintf("%s%d", name, i)
+		}
+		taken[unique] = true
+		p, _ := strconv.Unquote(v.Type)
+		if unique != name || v.Name != "" {
+			v.Name = unique
+			file.ImportAlias(p, unique)
+		} else {
+			file.ImportName(p, unique)
+		}
+		imp = append(imp, v)
+	}
+	buf := &bytes.Buffer{}
+	if err := code.RenderWithFile(buf, file); err != nil {
+		return "", nil, err
+	}
+	return buf.String(), imp, nil
+}
+
+// formatDecls formats the generated declarations, they are formatted by jennifer one
+// by one and the names of the imports could have been changed after.
+func formatDecls(code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return "", nil
+	}
+	pkg := "package p\n\n"
+	s, err := format.Source([]byte(pkg + code))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(strings.TrimPrefix(string(s), pkg)) + "\n", nil
+}
+
+// insertImports adds the imports that `src` does not have yet to its import
+// declarations, the rest of the source is not changed.
+//
+// The imports are added in order to the group of the standard library or of the other
+// packages, if the file has no import declaration a new one is added after the
+// package clause.
+func insertImports(src string, imp []parser.NamedTypeValue) (string, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "", src, goparser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	missing := []parser.NamedTypeValue{}
+	for _, v := range imp {
+		if !hasImport(f, v) && !hasImport(&ast.File{Imports: importSpecs(missing)}, v) {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return src, nil
+	}
+	sort.SliceStable(missing, func(i, j int) bool {
+		return missing[i].Type < missing[j].Type
+	})
+	offset := func(p token.Pos) int {
+		return fset.Position(p).Offset
+	}
+	var decl *ast.GenDecl
+	for _, d := range f.Decls {
+		if d, ok := d.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			// prefer the declarations with parentheses.
+			if decl == nil || decl.Lparen == token.NoPos {
+				decl = d
+			}
+		}
+	}
+	if decl == nil {
+		lines := []string{}
+		for _, v := range missing {
+			lines = append(lines, importSpecText(v))
+		}
+		// after the line of the package clause.
+		at := offset(f.Name.End())
+		if i := strings.Index(src[at:], "\n"); i != -1 {
+			at += i
+		} else {
+			at = len(src)
+		}
+		return src[:at] + "\n\n" + importDeclText(lines) + src[at:], nil
+	}
+	if decl.Lparen == token.NoPos {
+		// a single import without parentheses e.x `import "context"`.
+		lines := []string{src[offset(decl.Specs[0].Pos()):offset(decl.Specs[0].End())]}
+		for _, v := range missing {
+			lines = append(lines, importSpecText(v))
+		}
+		sort.SliceStable(lines, func(i, j int) bool {
+			return importLinePath(lines[i]) < importLinePath(lines[j])
+		})
+		return src[:offset(decl.Pos())] + importDeclText(lines) + src[offset(decl.End()):], nil
+	}
+	if len(decl.Specs) == 0 {
+		lines := ""
+		for _, v := range missing {
+			lines += "\n\t" + importSpecText(v)
+		}
+		at := offset(decl.Lparen) + 1
+		return src[:at] + lines + "\n" + src[at:], nil
+	}
+	// the groups of imports are separated by blank lines.
+	groups := [][]*ast.ImportSpec{}
+	for i, s := range decl.Specs {
+		s := s.(*ast.ImportSpec)
+		start := s.Pos()
+		if s.Doc != nil {
+			start = s.Doc.Pos()
+		}
+		if i == 0 || fset.Position(start).Line > fset.Position(decl.Specs[i-1].End()).Line+1 {
+			groups = append(groups, []*ast.ImportSpec{})
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], s)
+	}
+	edits := map[int]string{}
+	for _, v := range missing {
+		group := groups[len(groups)-1]
+		for _, g := range groups {
+			if isStdImport(g[0].Path.Value) == isStdImport(v.Type) {
+				group = g
+			}
+		}
+		at := -1
+		for _, s := range group {
+			if s.Path.Value > v.Type {
+				start := s.Pos()
+				if s.Doc != nil {
+					start = s.Doc.Pos()
+				}
+				at = strings.LastIndex(src[:offset(start)], "\n") + 1
+				edits[at] += "\t" + importSpecText(v) + "\n"
+				break
+			}
+		}
+		if at == -1 {
+			last := offset(group[len(group)-1].End())
+			at = last + strings.Index(src[last:], "\n")
+			edits[at] += "\n\t" + importSpecText(v)
+		}
+	}
+	at := []int{}
+	for k := range edits {
+		at = append(at, k)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(at)))
+	for _, k := range at {
+		src = src[:k] + edits[k] + src[k:]
+	}
+	return src, nil
+}
+
+// removeImports removes the imports of `src` that are not in `imp` from its import
+// declarations, the rest of the source is not changed. The declarations that have no
+// import left are removed too.
+func removeImports(src string, imp []parser.NamedTypeValue) (string, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "", src, goparser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	offset := func(p token.Pos) int {
+		return fset.Position(p).Offset
+	}
+	keep := &ast.File{Imports: importSpecs(imp)}
+	cuts := [][2]int{}
+	for _, d := range f.Decls {
+		d, ok := d.(*ast.GenDecl)
+		if !ok || d.Tok != token.IMPORT {
+			continue
+		}
+		unused := [][2]int{}
+		for _, s := range d.Specs {
+			s := s.(*ast.ImportSpec)
+			v := parser.NamedTypeValue{Type: s.Path.Value}
+			if s.Name != nil {
+				v.Name = s.Name.Name
+			}
+			if hasImport(keep, v) {
+				continue
+			}
+			start, end := s.Pos(), s.End()
+			if s.Doc != nil {
+				start = s.Doc.Pos()
+			}
+			if s.Comment != nil {
+				end = s.Comment.End()
+			}
+			unused = append(unused, [2]int{offset(start), offset(end)})
+		}
+		if len(unused) == 0 {
+			continue
+		}
+		if len(unused) < len(d.Specs) {
+			cuts = append(cuts, unused...)
+			continue
+		}
+		start := d.Pos()
+		if d.Doc != nil {
+			start = d.Doc.Pos()
+		}
+		cuts = append(cuts, [2]int{offset(start), offset(d.End())})
+	}
+	return cutSource(src, cuts), nil
+}
+
+// cutSource removes the sorted ranges of offsets `cuts` from `src`, see cutLines.
+func cutSource(src string, cuts [][2]int) string {
+	buf := new(bytes.Buffer)
+	last := 0
+	for _, c := range cuts {
+		start, end := cutLines(src, c[0], c[1])
+		if start < last {
+			start = last
+		}
+		if end <= start {
+			continue
+		}
+		buf.WriteString(src[last:start])
+		last = end
+	}
+	buf.WriteString(src[last:])
+	return buf.String()
+}
+
+// cutLines widens the range of offsets that is removed from `src` to its whole lines if
+// nothing else is written on them, and to the blank line before it if the range is
+// followed by a blank line or the end of a block, so no blank lines are left behind.
+func cutLines(src string, start, end int) (int, int) {
+	s, e := start, end
+	for s > 0 && (src[s-1] == ' ' || src[s-1] == '\t') {
+		s--
+	}
+	for e < len(src) && (src[e] == ' ' || src[e] == '\t') {
+		e++
+	}
+	if (s > 0 && src[s-1] != '\n') || (e < len(src) && src[e] != '\n') {
+		return start, end
+	}
+	if e < len(src) {
+		e++
+	}
+	if s > 1 && src[s-2] == '\n' {
+		rest := strings.TrimLeft(src[e:], " \t")
+		if rest == "" || rest[0] == '\n' || rest[0] == ')' || rest[0] == '}' {
+			s--
+		}
+	}
+	return s, e
+}
+
+// hasImport reports whether the file imports the package of `v` with the same name,
+// the name of an import without a name is the last element of its path.
+func hasImport(f *ast.File, v parser.NamedTypeValue) bool {
+	for _, s := range f.Imports {
+		name := ""
+		if s.Name != nil {
+			name = s.Name.Name
+		}
+		if s.Path.Value == v.Type && importName(name, v.Type) == importName(v.Name, v.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func importName(name, pth string) string {
+	if name != "" {
+		return name
+	}
+	p, _ := strconv.Unquote(pth)
+	return path.Base(p)
+}
+
+func importSpecs(imp []parser.NamedTypeValue) []*ast.ImportSpec {
+	specs := []*ast.ImportSpec{}
+	for _, v := range imp {
+		s := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: v.Type}}
+		if v.Name != "" {
+			s.Name = ast.NewIdent(v.Name)
+		}
+		specs = append(specs, s)
+	}
+	return specs
+}
+
+func importSpecText(v parser.NamedTypeValue) string {
+	if v.Name == "" {
+		return v.Type
+	}
+	return v.Name + " " + v.Type
+}
+
+func importDeclText(lines []string) string {
+	if len(lines) == 1 {
+		return "import " + lines[0]
+	}
+	return "import (\n\t" + strings.Join(lines, "\n\t") + "\n)"
+}
+
+// importLinePath returns the quoted path of an import spec line.
+func importLinePath(line string) string {
+	if i := strings.IndexAny(line, "\"`"); i != -1 {
+		return line[i:]
+	}
+	return line
+}
+
+// isStdImport reports whether the quoted path is a package of the standard library,
+// their first element has no dot.
+func isStdImport(pth string) bool {
+	p, err := strconv.Unquote(pth)
+	if err != nil {
+		return false
+	}
+	return !strings.Contains(strings.Split(p, "/")[0], ".")
+}
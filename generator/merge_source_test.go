@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_insertImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		imp  []parser.NamedTypeValue
+		want string
+	}{
+		{
+			"No imports",
+			"package p // the package\n\nvar v = 1\n",
+			[]parser.NamedTypeValue{{Type: `"fmt"`}},
+			"package p // the package\n\nimport \"fmt\"\n\nvar v = 1\n",
+		},
+		{
+			"Single import",
+			"package p\n\n// the imports\nimport \"fmt\"\n",
+			[]parser.NamedTypeValue{{Type: `"context"`}, {Name: "log", Type: `"github.com/go-kit/kit/log"`}},
+			"package p\n\n// the imports\nimport (\n\t\"context\"\n\t\"fmt\"\n\tlog \"github.com/go-kit/kit/log\"\n)\n",
+		},
+		{
+			"Groups",
+			"package p\n\nimport (\n\t\"context\"\n\t// fmt is used\n\t\"fmt\"\n\n\t\"github.com/go-kit/kit/log\"\n)\n",
+			[]parser.NamedTypeValue{
+				{Type: `"errors"`},
+				{Type: `"strings"`},
+				{Name: "endpoint", Type: `"github.com/go-kit/kit/endpoint"`},
+				{Name: "log", Type: `"github.com/go-kit/kit/log"`},
+			},
+			"package p\n\nimport (\n\t\"context\"\n\t\"errors\"\n\t// fmt is used\n\t\"fmt\"\n\t\"strings\"\n\n" +
+				"\tendpoint \"github.com/go-kit/kit/endpoint\"\n\t\"github.com/go-kit/kit/log\"\n)\n",
+		},
+		{
+			"Other name",
+			"package p\n\nimport (\n\t\"net/http\"\n)\n",
+			[]parser.NamedTypeValue{{Name: "http1", Type: `"net/http"`}},
+			"package p\n\nimport (\n\t\"net/http\"\n\thttp1 \"net/http\"\n)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := insertImports(tt.src, tt.imp)
+			if err != nil {
+				t.Errorf("insertImports() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("insertImports() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_removeImports(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		imp  []parser.NamedTypeValue
+		want string
+	}{
+		{
+			"Single import",
+			"package p\n\n// the imports\nimport \"fmt\"\n\nvar v = 1\n",
+			nil,
+			"package p\n\nvar v = 1\n",
+		},
+		{
+			"Groups",
+			"package p\n\nimport (\n\t\"context\"\n\t// fmt is used\n\t\"fmt\" // print\n\n\tlog \"github.com/go-kit/kit/log\"\n)\n",
+			[]parser.NamedTypeValue{{Type: `"context"`}},
+			"package p\n\nimport (\n\t\"context\"\n)\n",
+		},
+		{
+			"Middle group",
+			"package p\n\nimport (\n\t\"context\"\n\n\t\"github.com/go-kit/kit/log\"\n\n\t\"example.com/p/pkg\"\n)\n",
+			[]parser.NamedTypeValue{{Type: `"context"`}, {Type: `"example.com/p/pkg"`}},
+			"package p\n\nimport (\n\t\"context\"\n\n\t\"example.com/p/pkg\"\n)\n",
+		},
+		{
+			"Other name",
+			"package p\n\nimport (\n\t\"net/http\"\n\thttp1 \"net/http\"\n)\n",
+			[]parser.NamedTypeValue{{Type: `"net/http"`}},
+			"package p\n\nimport (\n\t\"net/http\"\n)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := removeImports(tt.src, tt.imp)
+			if err != nil {
+				t.Errorf("removeImports() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("removeImports() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSource_KeepsUserCode(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("merge_svc/pkg/service/service.go", `package service
+
+import "context"
+
+// MergeSvcService describes the service.
+type MergeSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+}
+`, true)
+	err := NewGenerateService("merge_svc", "http", true, false, true, nil).Generate()
+	Convey("Test if the comments and the formatting of the user code are kept", t, func() {
+		So(err, ShouldBeNil)
+		user := map[string]string{}
+		for _, pth := range []string{
+			"merge_svc/pkg/service/middleware.go",
+			"merge_svc/pkg/endpoint/endpoint.go",
+			"merge_svc/pkg/http/handler.go",
+		} {
+			src, err := fs.Get().ReadFile(pth)
+			So(err, ShouldBeNil)
+			// a comment and code that is not formatted the way gofmt would.
+			src += "\n// keep this comment\nvar  keep   =  1 // and this one\n"
+			fs.Get().WriteFile(pth, src, true)
+			// the imports can change, the code after them should not.
+			if i := strings.Index(src, "\n)\n"); i != -1 {
+				src = src[i+3:]
+			}
+			user[pth] = src[strings.Index(src, "\n\n"):]
+		}
+		svc, _ := fs.Get().ReadFile("merge_svc/pkg/service/service.go")
+		fs.Get().WriteFile("merge_svc/pkg/service/service.go", strings.Replace(
+			svc,
+			"\tFoo(ctx context.Context, s string) (r string, err error)\n",
+			"\tFoo(ctx context.Context, s string) (r string, err error)\n\tBar(ctx context.Context, n int) (i int, err error)\n",
+			1,
+		), true)
+		So(NewGenerateService("merge_svc", "http", true, false, true, nil).Generate(), ShouldBeNil)
+		for pth, code := range user {
+			src, err := fs.Get().ReadFile(pth)
+			So(err, ShouldBeNil)
+			So(src, ShouldContainSubstring, code)
+			So(src, ShouldContainSubstring, "Bar")
+		}
+	})
+}
@@ -3,11 +3,16 @@ package generator
 import (
 	"fmt"
 	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
 	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
@@ -15,20 +20,30 @@ import (
 type NewService struct {
 	BaseGenerator
 	name          string
+	module        string
 	interfaceName string
 	destPath      string
 	filePath      string
+	spec          ServiceSpec
 }
 
 // NewNewService returns a initialized and ready generator.
 //
 // The name parameter is the name of the service that will be created
-// this name should be without the `Service` suffix
-func NewNewService(name string) Gen {
+// this name should be without the `Service` suffix.
+//
+// The module parameter is the module path used if a go.mod needs to be created,
+// if it is empty the service name is used.
+//
+// The spec parameter holds the methods of the interface, if it has none the
+// interface is created empty.
+func NewNewService(name string, module string, spec ServiceSpec) Gen {
 	gs := &NewService{
 		name:          name,
+		module:        module,
 		interfaceName: utils.ToCamelCase(name + "Service"),
 		destPath:      fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+		spec:          spec,
 	}
 	gs.filePath = path.Join(gs.destPath, viper.GetString("gk_service_file_name"))
 	gs.srcFile = jen.NewFilePath(strings.Replace(gs.destPath, "\\", "/", -1))
@@ -39,17 +54,106 @@ func NewNewService(name string) Gen {
 
 // Generate will run the generator.
 func (g *NewService) Generate() error {
+	if err := g.spec.Validate(); err != nil {
+		return err
+	}
 	g.CreateFolderStructure(g.destPath)
-	comments := []string{
-		"Add your methods here",
-		"e.x: Foo(ctx context.Context,s string)(rs string, err error)",
+	methods := []jen.Code{}
+	for _, m := range g.spec.Methods {
+		code := &jen.Statement{}
+		for _, line := range strings.Split(strings.TrimSpace(m.Doc), "\n") {
+			if line != "" {
+				code.Comment(line).Line()
+			}
+		}
+		methods = append(methods, code.Op(strings.TrimSpace(m.Signature)))
+	}
+	if len(methods) == 0 {
+		comments := []string{
+			"Add your methods here",
+			"e.x: Foo(ctx context.Context,s string)(rs string, err error)",
+		}
+		partial := NewPartialGenerator(nil)
+		partial.appendMultilineComment(comments)
+		methods = append(methods, partial.Raw())
+	}
+	if g.spec.Doc != "" {
+		g.code.appendMultilineComment(strings.Split(strings.TrimSpace(g.spec.Doc), "\n"))
+		g.code.NewLine()
+	} else {
+		g.code.Raw().Commentf("%s describes the service.", g.interfaceName).Line()
 	}
-	partial := NewPartialGenerator(nil)
-	partial.appendMultilineComment(comments)
-	g.code.Raw().Commentf("%s describes the service.", g.interfaceName).Line()
 	g.code.appendInterface(
 		g.interfaceName,
-		[]jen.Code{partial.Raw()},
+		methods,
 	)
-	return g.fs.WriteFile(g.filePath, g.srcFile.GoString(), false)
+	src := g.srcFile.GoString()
+	if len(g.spec.Methods) > 0 {
+		// the types of the methods are plain text, the imports of the packages they
+		// use are added from the spec and by goimports.
+		imp := []parser.NamedTypeValue{}
+		for _, v := range g.spec.Imports {
+			i, _ := specImport(v)
+			imp = append(imp, i)
+		}
+		var err error
+		if src, err = g.AddImportsToFile(imp, src); err != nil {
+			return err
+		}
+		if src, err = utils.GoImportsSource(g.destPath, src); err != nil {
+			return err
+		}
+	}
+	err := g.fs.WriteFile(g.filePath, src, false)
+	if err != nil {
+		return err
+	}
+	return g.generateModFile()
+}
+
+// generateModFile creates a go.mod in the service folder if the service
+// is not already part of a module.
+func (g *NewService) generateModFile() error {
+	svcDir := utils.ToLowerSnakeCase(g.name)
+	modFilePath := path.Join(svcDir, "go.mod")
+	if b, err := g.fs.Exists(modFilePath); err != nil {
+		return err
+	} else if b {
+		return nil
+	}
+	projectDir, err := utils.GetProjectDir()
+	if err != nil {
+		return err
+	}
+	modDir, _, err := utils.FindModFile(filepath.Join(projectDir, svcDir))
+	if err != nil {
+		return err
+	}
+	if modDir != "" {
+		logrus.Debugf("Service `%s` is part of the module in `%s`", g.name, modDir)
+		return nil
+	}
+	module := g.module
+	if module == "" {
+		module = svcDir
+	}
+	logrus.Infof("Creating go.mod for module `%s`", module)
+	mod := fmt.Sprintf("module %s\n\ngo %s\n\nrequire github.com/go-kit/kit %s\n", module, goVersion(), GoKitVersion)
+	// the service can be generated in the same run.
+	utils.StageModFile(filepath.Join(projectDir, svcDir), []byte(mod))
+	return g.fs.WriteFile(modFilePath, mod, false)
+}
+
+// GoKitVersion is the version of go-kit the generated code is written for, it is required
+// in the go.mod of the new services so the versions of the transport libraries match it.
+const GoKitVersion = "v0.13.0"
+
+var goVersionRegexp = regexp.MustCompile(`^go(\d+\.\d+)`)
+
+// goVersion returns the language version of the go toolchain kit was built with.
+func goVersion() string {
+	if m := goVersionRegexp.FindStringSubmatch(runtime.Version()); m != nil {
+		return m[1]
+	}
+	return "1.12"
 }
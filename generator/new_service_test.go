@@ -8,7 +8,7 @@ import (
 
 func TestNewNewService(t *testing.T) {
 	setDefaults()
-	g := NewNewService("test").(*NewService)
+	g := NewNewService("test", "", ServiceSpec{}).(*NewService)
 	err := g.Generate()
 	Convey("Test if generator generates the service without errors", t, func() {
 		So(err, ShouldBeNil)
@@ -22,3 +22,38 @@ func TestNewNewService(t *testing.T) {
 		})
 	})
 }
+
+func TestNewNewService_Methods(t *testing.T) {
+	setDefaults()
+	g := NewNewService("spec_svc", "", ServiceSpec{
+		Doc: "SpecSvcService greets people.",
+		Methods: []MethodSpec{
+			{Signature: "Foo(ctx context.Context, s string) (string, error)", Doc: "Foo greets the user.\n@http GET /foo"},
+			{Signature: "Wait(ctx context.Context, d time.Duration) (err error)"},
+		},
+	}).(*NewService)
+	err := g.Generate()
+	Convey("Test if the interface is generated with the methods", t, func() {
+		So(err, ShouldBeNil)
+		f, _ := g.fs.ReadFile("spec_svc/pkg/service/service.go")
+		So(f, ShouldContainSubstring, "import (\n\t\"context\"\n\t\"time\"\n)")
+		So(f, ShouldContainSubstring, `// SpecSvcService greets people.
+type SpecSvcService interface {
+	// Foo greets the user.
+	// @http GET /foo
+	Foo(ctx context.Context, s string) (string, error)
+	Wait(ctx context.Context, d time.Duration) (err error)
+}`)
+		Convey("Test if the service can be generated from it", func() {
+			// the GET route needs the gorilla mux.
+			So(NewGenerateService("spec_svc", "http", false, false, false, nil).Generate(), ShouldNotBeNil)
+			So(NewGenerateService("spec_svc", "http", false, true, false, nil).Generate(), ShouldBeNil)
+			f, _ := g.fs.ReadFile("spec_svc/pkg/endpoint/endpoint.go")
+			So(f, ShouldContainSubstring, "func MakeWaitEndpoint(")
+		})
+	})
+	Convey("Test if the invalid methods are reported", t, func() {
+		err := NewNewService("spec_svc", "", ServiceSpec{Methods: []MethodSpec{{Signature: "Foo("}}}).Generate()
+		So(err, ShouldNotBeNil)
+	})
+}
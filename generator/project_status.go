@@ -0,0 +1,48 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/spf13/viper"
+)
+
+// StateOrphaned is the state of the generated files of a service that no longer has a
+// service file.
+const StateOrphaned = "orphaned"
+
+// ProjectStatus returns the files kit generated in the project and their state,
+// `generated`, `modified` if they were edited after, `deleted` or `orphaned` if the
+// service they were generated for was removed.
+func ProjectStatus() ([]fs.ManifestFile, error) {
+	kfs := fs.Get()
+	files, err := kfs.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	services := map[string]bool{}
+	for i, f := range files {
+		name := strings.Split(f.Path, "/")[0]
+		// the files of the project e.x docker-compose.yml belong to no service.
+		if f.State == fs.StateDeleted || name == f.Path {
+			continue
+		}
+		found, ok := services[name]
+		if !ok {
+			svcPath := path.Join(
+				fmt.Sprintf(viper.GetString("gk_service_path_format"), name),
+				viper.GetString("gk_service_file_name"),
+			)
+			if found, err = kfs.Exists(svcPath); err != nil {
+				return nil, err
+			}
+			services[name] = found
+		}
+		if !found {
+			files[i].State = StateOrphaned
+		}
+	}
+	return files, nil
+}
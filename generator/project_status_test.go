@@ -0,0 +1,28 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProjectStatus(t *testing.T) {
+	setDefaults()
+	fs.Get().WriteFile("status_svc/pkg/service/service.go", "package service\n", true)
+	fs.Get().WriteFile("status_svc/pkg/endpoint/endpoint_gen.go", "package endpoint\n", true)
+	fs.Get().WriteFile("gone_svc/pkg/endpoint/endpoint_gen.go", "package endpoint\n", true)
+	err := fs.Get().Commit()
+	Convey("Test if the files of the removed services are orphaned", t, func() {
+		So(err, ShouldBeNil)
+		files, err := ProjectStatus()
+		So(err, ShouldBeNil)
+		states := map[string]string{}
+		for _, f := range files {
+			states[f.Path] = f.State
+		}
+		So(states["status_svc/pkg/service/service.go"], ShouldEqual, fs.StateGenerated)
+		So(states["status_svc/pkg/endpoint/endpoint_gen.go"], ShouldEqual, fs.StateGenerated)
+		So(states["gone_svc/pkg/endpoint/endpoint_gen.go"], ShouldEqual, StateOrphaned)
+	})
+}
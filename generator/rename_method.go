@@ -0,0 +1,314 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// identifierRegexp matches exported go identifiers.
+var identifierRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9_]*$`)
+
+// RenameMethod implements Gen and is used to rename a method of the service in
+// all the generated layers.
+type RenameMethod struct {
+	BaseGenerator
+	name          string
+	oldName       string
+	newName       string
+	interfaceName string
+	filePath      string
+	// idents are the identifiers derived from the method name, they are renamed everywhere.
+	idents map[string]string
+	// members are the method name and the unexported field of the method, they are only
+	// renamed where they name a method or a field.
+	members map[string]string
+	// literals are the strings derived from the method name e.x the http path.
+	literals map[string]string
+	// words are the names renamed in the comments.
+	words *regexp.Regexp
+	// replacements maps the words to their new names.
+	replacements map[string]string
+}
+
+// NewRenameMethod returns a initialized and ready generator.
+func NewRenameMethod(name, oldName, newName string) Gen {
+	i := &RenameMethod{
+		name:          name,
+		oldName:       oldName,
+		newName:       newName,
+		interfaceName: utils.ToCamelCase(name + "Service"),
+	}
+	i.filePath = path.Join(
+		fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+		viper.GetString("gk_service_file_name"),
+	)
+	i.idents = map[string]string{}
+	for _, f := range []string{
+		"%sRequest", "%sResponse", "%sReply", "%sEndpoint", "Make%sEndpoint", "make%sHandler", "make%sCodec",
+		"decode%sRequest", "encode%sResponse", "encode%sRequest", "decode%sResponse",
+	} {
+		i.idents[fmt.Sprintf(f, oldName)] = fmt.Sprintf(f, newName)
+	}
+	i.idents[utils.ToLowerFirstCamelCase(oldName)+"Endpoint"] = utils.ToLowerFirstCamelCase(newName) + "Endpoint"
+	i.members = map[string]string{
+		oldName:                              newName,
+		utils.ToLowerFirstCamelCase(oldName): utils.ToLowerFirstCamelCase(newName),
+	}
+	i.literals = map[string]string{
+		oldName:                              newName,
+		utils.ToLowerFirstCamelCase(oldName): utils.ToLowerFirstCamelCase(newName),
+		httpPath(oldName):                    httpPath(newName),
+		natsSubject(name, oldName):           natsSubject(name, newName),
+	}
+	i.replacements = map[string]string{
+		utils.ToLowerSnakeCase(oldName): utils.ToLowerSnakeCase(newName),
+	}
+	for k, v := range i.idents {
+		i.replacements[k] = v
+	}
+	for k, v := range i.members {
+		i.replacements[k] = v
+	}
+	words := []string{}
+	for k := range i.replacements {
+		words = append(words, regexp.QuoteMeta(k))
+	}
+	// the longest names first so the method name does not match a part of them.
+	sort.Slice(words, func(a, b int) bool {
+		return len(words[a]) > len(words[b])
+	})
+	i.words = regexp.MustCompile(`\b(` + strings.Join(words, "|") + `)\b`)
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate renames the method in the go files of the service, the proto file and
+// regenerates the files of the transports that are compiled from the service.
+func (g *RenameMethod) Generate() (err error) {
+	if !identifierRegexp.MatchString(g.newName) {
+		return fmt.Errorf("`%s` is not an exported method name", g.newName)
+	}
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		return fmt.Errorf("service %s was not found", g.name)
+	}
+	svcSrc, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	file, err := parseServiceFile(g.fs, svcSrc, g.filePath)
+	if err != nil {
+		return err
+	}
+	var iface *parser.Interface
+	for n, v := range file.Interfaces {
+		if v.Name == g.interfaceName {
+			iface = &file.Interfaces[n]
+		}
+	}
+	if iface == nil {
+		return fmt.Errorf("could not find the service interface in `%s`", g.name)
+	}
+	found := false
+	for _, m := range iface.Methods {
+		if m.Name == g.newName {
+			return fmt.Errorf("the service already has a method named `%s`", g.newName)
+		}
+		found = found || m.Name == g.oldName
+	}
+	if !found {
+		return fmt.Errorf("the service has no method named `%s`", g.oldName)
+	}
+	n := utils.ToLowerSnakeCase(g.name)
+	skip := map[string]bool{
+		fmt.Sprintf(viper.GetString("gk_grpc_pb_path_format"), n):    true,
+		fmt.Sprintf(viper.GetString("gk_thrift_gen_path_format"), n): true,
+	}
+	err = afero.Walk(g.fs.Fs, n, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if skip[pth] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(pth, ".go") {
+			return nil
+		}
+		return g.renameFile(pth)
+	})
+	if err != nil {
+		return err
+	}
+	if err = g.renameProto(); err != nil {
+		return err
+	}
+	return g.regenerate()
+}
+
+func (g *RenameMethod) renameFile(pth string) error {
+	src, err := g.fs.ReadFile(pth)
+	if err != nil {
+		return err
+	}
+	renamed, err := g.renameSource(pth, src)
+	if err != nil {
+		return err
+	}
+	if renamed == src {
+		return nil
+	}
+	return g.fs.WriteFile(pth, renamed, true)
+}
+
+// renameSource renames the method in the given go source. Only the identifiers, the
+// literals and the comments are replaced, the rest of the source is kept byte for byte.
+func (g *RenameMethod) renameSource(name, src string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, name, src, goparser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	type edit struct {
+		pos  token.Pos
+		end  token.Pos
+		text string
+	}
+	edits := []edit{}
+	member := func(id *ast.Ident) {
+		if v, ok := g.members[id.Name]; ok {
+			edits = append(edits, edit{id.Pos(), id.End(), v})
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			if v, ok := g.idents[n.Name]; ok {
+				edits = append(edits, edit{n.Pos(), n.End(), v})
+			}
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				member(n.Name)
+			}
+		case *ast.Field:
+			// interface methods and struct fields.
+			for _, id := range n.Names {
+				member(id)
+			}
+		case *ast.SelectorExpr:
+			member(n.Sel)
+		case *ast.KeyValueExpr:
+			if id, ok := n.Key.(*ast.Ident); ok {
+				member(id)
+			}
+		case *ast.BasicLit:
+			if n.Kind != token.STRING {
+				return true
+			}
+			if s, err := strconv.Unquote(n.Value); err == nil {
+				if v, ok := g.literals[s]; ok {
+					edits = append(edits, edit{n.Pos(), n.End(), strconv.Quote(v)})
+				}
+			}
+		}
+		return true
+	})
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if t := g.renameWords(c.Text); t != c.Text {
+				edits = append(edits, edit{c.Pos(), c.End(), t})
+			}
+		}
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].pos < edits[j].pos
+	})
+	out := ""
+	last := 0
+	for _, e := range edits {
+		start := fset.Position(e.pos).Offset
+		if start < last {
+			// an identifier can be matched twice e.x a field that is also an ident.
+			continue
+		}
+		out += src[last:start] + e.text
+		last = fset.Position(e.end).Offset
+	}
+	out += src[last:]
+	return out, nil
+}
+
+func (g *RenameMethod) renameWords(s string) string {
+	return g.words.ReplaceAllStringFunc(s, func(w string) string {
+		return g.replacements[w]
+	})
+}
+
+// renameProto renames the rpc and the messages of the method in the proto file,
+// the fields keep their numbers so the clients that use the old stubs still work.
+func (g *RenameMethod) renameProto() error {
+	n := utils.ToLowerSnakeCase(g.name)
+	pbFilePath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_grpc_pb_path_format"), n),
+		fmt.Sprintf(viper.GetString("gk_grpc_pb_file_name"), n),
+	)
+	if b, err := g.fs.Exists(pbFilePath); err != nil || !b {
+		return err
+	}
+	src, err := g.fs.ReadFile(pbFilePath)
+	if err != nil {
+		return err
+	}
+	return g.fs.WriteFile(pbFilePath, g.renameWords(src), true)
+}
+
+// regenerate regenerates the gRPC and thrift transports so the compiled stubs and the
+// thrift IDL use the new name, and the OpenAPI document if it exists.
+func (g *RenameMethod) regenerate() error {
+	n := utils.ToLowerSnakeCase(g.name)
+	for _, t := range []string{"grpc", "thrift"} {
+		handler := path.Join(
+			fmt.Sprintf(viper.GetString("gk_"+t+"_path_format"), n),
+			viper.GetString("gk_"+t+"_file_name"),
+		)
+		if b, err := g.fs.Exists(handler); err != nil {
+			return err
+		} else if !b {
+			continue
+		}
+		if err := NewGenerateTransport(g.name, false, t, nil).Generate(); err != nil {
+			return err
+		}
+	}
+	openapi := path.Join(
+		fmt.Sprintf(viper.GetString("gk_http_path_format"), n),
+		viper.GetString("gk_openapi_file_name"),
+	)
+	if b, err := g.fs.Exists(openapi); err != nil {
+		return err
+	} else if b {
+		return NewGenerateOpenAPI(g.name).Generate()
+	}
+	return nil
+}
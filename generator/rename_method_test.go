@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRenameMethod_Generate(t *testing.T) {
+	setDefaults()
+	svc := `package service
+
+import "context"
+
+// RenameSvcService describes the service.
+type RenameSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+	GetUser(ctx context.Context, n int) (i int, err error)
+}
+`
+	fs.Get().WriteFile("rename_svc/pkg/service/service.go", svc, true)
+	err := NewGenerateService("rename_svc", "http", true, false, true, nil).Generate()
+	Convey("Test if the method is renamed in every layer", t, func() {
+		So(err, ShouldBeNil)
+		s, _ := fs.Get().ReadFile("rename_svc/pkg/service/service.go")
+		fs.Get().WriteFile(
+			"rename_svc/pkg/service/service.go",
+			s+"\nfunc lookup(n int) int {\n\treturn n\n}\n",
+			true,
+		)
+		So(NewRenameMethod("rename_svc", "GetUser", "FetchUser").Generate(), ShouldBeNil)
+		for _, f := range []string{
+			"rename_svc/pkg/service/service.go",
+			"rename_svc/pkg/service/middleware.go",
+			"rename_svc/pkg/endpoint/endpoint.go",
+			"rename_svc/pkg/endpoint/endpoint_gen.go",
+			"rename_svc/pkg/http/handler.go",
+			"rename_svc/pkg/http/handler_gen.go",
+			"rename_svc/cmd/service/service_gen.go",
+		} {
+			src, _ := fs.Get().ReadFile(f)
+			So(src, ShouldNotContainSubstring, "GetUser")
+			So(src, ShouldContainSubstring, "FetchUser")
+		}
+		src, _ := fs.Get().ReadFile("rename_svc/pkg/endpoint/endpoint.go")
+		So(src, ShouldContainSubstring, "func MakeFetchUserEndpoint(")
+		So(src, ShouldContainSubstring, "func MakeFooEndpoint(")
+		src, _ = fs.Get().ReadFile("rename_svc/pkg/http/handler.go")
+		So(src, ShouldContainSubstring, `"/fetch-user"`)
+		src, _ = fs.Get().ReadFile("rename_svc/pkg/service/service.go")
+		So(src, ShouldContainSubstring, "func lookup(n int) int {")
+		Convey("Test if the new name is validated", func() {
+			So(NewRenameMethod("rename_svc", "Foo", "FetchUser").Generate(), ShouldNotBeNil)
+			So(NewRenameMethod("rename_svc", "Foo", "bar").Generate(), ShouldNotBeNil)
+			So(NewRenameMethod("rename_svc", "GetUser", "Bar").Generate(), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRenameMethod_renameSource(t *testing.T) {
+	g := NewRenameMethod("hello", "Foo", "Bar").(*RenameMethod)
+	src := `package grpc
+
+// makeFooHandler creates the handler of Foo.
+func makeFooHandler(endpoints endpoint.Endpoints) grpc.Handler {
+	return grpc.NewServer(endpoints.FooEndpoint, decodeFooRequest, encodeFooResponse)
+}
+
+func (g *grpcServer) Foo(ctx context.Context, req *pb.FooRequest) (*pb.FooReply, error) {
+	Foo := "Foo"
+	// the source is not formatted again.
+	_, rep, err :=  g.foo.ServeGRPC(ctx, req)
+	return rep.(*pb.FooReply), err
+}
+`
+	want := `package grpc
+
+// makeBarHandler creates the handler of Bar.
+func makeBarHandler(endpoints endpoint.Endpoints) grpc.Handler {
+	return grpc.NewServer(endpoints.BarEndpoint, decodeBarRequest, encodeBarResponse)
+}
+
+func (g *grpcServer) Bar(ctx context.Context, req *pb.BarRequest) (*pb.BarReply, error) {
+	Foo := "Bar"
+	// the source is not formatted again.
+	_, rep, err :=  g.bar.ServeGRPC(ctx, req)
+	return rep.(*pb.BarReply), err
+}
+`
+	got, err := g.renameSource("handler.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("renameSource() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,488 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/spf13/afero"
+)
+
+// goListDirs caches the folders `go list` found, the empty string is a package that
+// could not be found.
+var goListDirs = map[string]string{}
+
+// versionSuffix matches the major version suffix of an import path e.x `/v2` or `.v2`.
+var versionSuffix = regexp.MustCompile(`[./]v[0-9]+$`)
+
+// goPackage is a folder of go files, local packages are read from the kit file
+// system and the other packages from the os file system.
+type goPackage struct {
+	fs  afero.Fs
+	dir string
+}
+
+// goFile is a file of a type checked package, scope is the file scope go/types
+// resolved for it e.x the imports of the file.
+type goFile struct {
+	path  string
+	file  *parser.File
+	scope *types.Scope
+}
+
+// checkedPackage is a package type checked with go/types.
+type checkedPackage struct {
+	pkg   *types.Package
+	files []goFile
+}
+
+// goSource is the source of a go file.
+type goSource struct {
+	path string
+	src  string
+}
+
+// packageLoader loads the service package with its type information, it flattens the
+// embedded interfaces and qualifies the types with the packages they are declared in.
+type packageLoader struct {
+	fs   *fs.KitFs
+	osFs afero.Fs
+	fset *token.FileSet
+	// src are the sources that are used instead of the files on disk.
+	src      map[string]string
+	checked  map[goPackage]*checkedPackage
+	imported map[string]*types.Package
+	// dotImports are the packages imported with `.`, the importer declares their
+	// exported types.
+	dotImports map[string]bool
+	visiting   map[string]bool
+	// root is the service package, its types are not qualified.
+	root *types.Package
+	// qualifiers maps the import paths of the qualified types to their qualifiers.
+	qualifiers map[string]string
+}
+
+// parseServiceFile parses the service source found at `pth` with the other files of its
+// package. The embedded interfaces are flattened into the interfaces that embed them,
+// the structures of the other files are added to the structures of the file and the
+// types are qualified with the packages go/types resolves them to.
+func parseServiceFile(kfs *fs.KitFs, src, pth string) (*parser.File, error) {
+	l := &packageLoader{
+		fs:         kfs,
+		osFs:       afero.NewOsFs(),
+		fset:       token.NewFileSet(),
+		src:        map[string]string{pth: src},
+		checked:    map[goPackage]*checkedPackage{},
+		imported:   map[string]*types.Package{},
+		dotImports: map[string]bool{},
+		visiting:   map[string]bool{},
+		qualifiers: map[string]string{},
+	}
+	pkg := goPackage{fs: kfs.Fs, dir: path.Dir(pth)}
+	importPath := pkg.dir
+	if projectPath, err := utils.GetProjectImportPath(); err == nil {
+		importPath = path.Join(projectPath, pkg.dir)
+	}
+	cp, err := l.check(pkg, importPath)
+	if err != nil {
+		return nil, err
+	}
+	l.root = cp.pkg
+	// the service file is the first file of the package.
+	svc := cp.files[0]
+	// the qualifiers of the service file are kept so the types read the same.
+	for _, name := range svc.scope.Names() {
+		if pn, ok := svc.scope.Lookup(name).(*types.PkgName); ok {
+			l.qualifiers[pn.Imported().Path()] = name
+		}
+	}
+	file := svc.file
+	for i, v := range file.Interfaces {
+		if file.Interfaces[i].Methods, err = l.methods(v, svc, cp); err != nil {
+			return nil, err
+		}
+	}
+	structures := []parser.Struct{}
+	for _, f := range cp.files {
+		for _, s := range f.file.Structures {
+			structures = append(structures, l.resolveStruct(s, f.scope))
+		}
+	}
+	file.Structures = structures
+	file.Qualifiers = []parser.NamedTypeValue{}
+	for p, q := range l.qualifiers {
+		file.Qualifiers = append(file.Qualifiers, parser.NewNameType(q, strconv.Quote(p)))
+	}
+	sort.Slice(file.Qualifiers, func(i, j int) bool {
+		return file.Qualifiers[i].Name < file.Qualifiers[j].Name
+	})
+	return file, nil
+}
+
+// unimportedQualifiers returns the qualifiers of the file that it does not import
+// e.x the qualifiers of the types of dot imports.
+func unimportedQualifiers(file *parser.File) []parser.NamedTypeValue {
+	imp := []parser.NamedTypeValue{}
+	for _, q := range file.Qualifiers {
+		found := false
+		for _, v := range file.Imports {
+			p, _ := strconv.Unquote(v.Type)
+			if v.Type == q.Type && (v.Name == q.Name || v.Name == "" && path.Base(p) == q.Name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			imp = append(imp, q)
+		}
+	}
+	return imp
+}
+
+// methods returns the method set of `iface` declared in the file `f` of `cp`.
+func (l *packageLoader) methods(iface parser.Interface, f goFile, cp *checkedPackage) ([]parser.Method, error) {
+	key := cp.pkg.Path() + "." + iface.Name
+	if l.visiting[key] {
+		return nil, fmt.Errorf("interface %s embeds itself", iface.Name)
+	}
+	l.visiting[key] = true
+	defer delete(l.visiting, key)
+	methods := []parser.Method{}
+	found := map[string]bool{}
+	for _, m := range iface.Methods {
+		methods = append(methods, l.resolveMethod(m, f.scope))
+		found[m.Name] = true
+	}
+	for _, e := range iface.Embedded {
+		emb, embFile, embPkg, err := l.lookup(e, f, cp)
+		if err != nil {
+			return nil, err
+		}
+		if emb == nil {
+			// e.x the interfaces of packages that can not be found, kit can not generate
+			// code for the methods it does not know.
+			continue
+		}
+		embMethods, err := l.methods(*emb, embFile, embPkg)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range embMethods {
+			if !found[m.Name] {
+				methods = append(methods, m)
+				found[m.Name] = true
+			}
+		}
+	}
+	return methods, nil
+}
+
+// lookup finds the declaration of the embedded interface `tp` of the file `f`, it
+// returns nil if the interface could not be found.
+func (l *packageLoader) lookup(tp string, f goFile, cp *checkedPackage) (*parser.Interface, goFile, *checkedPackage, error) {
+	if i := strings.Index(tp, "."); i != -1 {
+		pn, ok := f.scope.Lookup(tp[:i]).(*types.PkgName)
+		if !ok {
+			return nil, goFile{}, nil, nil
+		}
+		return l.find(pn.Imported().Path(), tp[i+1:])
+	}
+	_, obj := f.scope.LookupParent(tp, token.NoPos)
+	if obj == nil || obj.Pkg() == nil {
+		return nil, goFile{}, nil, nil
+	}
+	if obj.Pkg() != cp.pkg {
+		// dot imports.
+		return l.find(obj.Pkg().Path(), tp)
+	}
+	for _, v := range cp.files {
+		if iface := findInterface(v.file, tp); iface != nil {
+			return iface, v, cp, nil
+		}
+	}
+	return nil, goFile{}, nil, nil
+}
+
+// find finds the interface `name` in the package with the given import path.
+func (l *packageLoader) find(importPath, name string) (*parser.Interface, goFile, *checkedPackage, error) {
+	pkg := l.packageOf(importPath)
+	if pkg.fs == nil {
+		return nil, goFile{}, nil, nil
+	}
+	cp, err := l.check(pkg, importPath)
+	if err != nil {
+		return nil, goFile{}, nil, err
+	}
+	for _, v := range cp.files {
+		if iface := findInterface(v.file, name); iface != nil {
+			return iface, v, cp, nil
+		}
+	}
+	return nil, goFile{}, nil, nil
+}
+
+func findInterface(file *parser.File, name string) *parser.Interface {
+	for i, v := range file.Interfaces {
+		if v.Name == name {
+			return &file.Interfaces[i]
+		}
+	}
+	return nil
+}
+
+// check parses the files of the package and type checks them.
+func (l *packageLoader) check(pkg goPackage, importPath string) (*checkedPackage, error) {
+	if cp, ok := l.checked[pkg]; ok {
+		return cp, nil
+	}
+	srcs, err := l.readPackage(pkg)
+	if err != nil {
+		return nil, err
+	}
+	files := []goFile{}
+	astFiles := []*ast.File{}
+	for _, s := range srcs {
+		f, err := parser.NewFileParser().Parse([]byte(s.src))
+		if err != nil {
+			return nil, err
+		}
+		af, err := goparser.ParseFile(l.fset, s.path, s.src, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, imp := range af.Imports {
+			if imp.Name != nil && imp.Name.Name == "." {
+				if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+					l.dotImports[p] = true
+				}
+			}
+		}
+		files = append(files, goFile{path: s.path, file: f})
+		astFiles = append(astFiles, af)
+	}
+	info := &types.Info{Scopes: map[ast.Node]*types.Scope{}}
+	conf := types.Config{
+		Importer:         l,
+		IgnoreFuncBodies: true,
+		// kit only needs the declarations, the errors e.x of the packages that are
+		// not found do not stop it from resolving the rest of the types.
+		Error: func(error) {},
+	}
+	p, _ := conf.Check(importPath, l.fset, astFiles, info)
+	for i, af := range astFiles {
+		files[i].scope = info.Scopes[af]
+		if files[i].scope == nil {
+			// files of an other package are not checked.
+			files[i].scope = types.NewScope(p.Scope(), token.NoPos, token.NoPos, "")
+		}
+	}
+	cp := &checkedPackage{pkg: p, files: files}
+	l.checked[pkg] = cp
+	return cp, nil
+}
+
+// Import implements types.Importer, the imported packages are not type checked only
+// their names and, for dot imports, their exported types are declared.
+func (l *packageLoader) Import(importPath string) (*types.Package, error) {
+	if p, ok := l.imported[importPath]; ok {
+		return p, nil
+	}
+	name := path.Base(versionSuffix.ReplaceAllString(importPath, ""))
+	srcs := []goSource{}
+	if l.dotImports[importPath] || !isStandardPackage(importPath) {
+		if pkg := l.packageOf(importPath); pkg.fs != nil {
+			srcs, _ = l.readPackage(pkg)
+		}
+	}
+	decls := []*ast.File{}
+	for _, s := range srcs {
+		mode := goparser.PackageClauseOnly
+		if l.dotImports[importPath] {
+			mode = 0
+		}
+		if f, err := goparser.ParseFile(token.NewFileSet(), s.path, s.src, mode); err == nil {
+			name = f.Name.Name
+			decls = append(decls, f)
+		}
+	}
+	p := types.NewPackage(importPath, name)
+	for _, f := range decls {
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				if ts := s.(*ast.TypeSpec); ts.Name.IsExported() {
+					tn := types.NewTypeName(token.NoPos, p, ts.Name.Name, nil)
+					types.NewNamed(tn, types.NewStruct(nil, nil), nil)
+					p.Scope().Insert(tn)
+				}
+			}
+		}
+	}
+	p.MarkComplete()
+	l.imported[importPath] = p
+	return p, nil
+}
+
+// isStandardPackage returns true if the import path is a package of the standard library,
+// their names are the last element of the path.
+func isStandardPackage(importPath string) bool {
+	return !strings.Contains(strings.Split(importPath, "/")[0], ".")
+}
+
+// packageOf returns the package with the given import path, packages of the project
+// are read from the kit file system and the other packages are found with `go list`.
+// The fs of the package is nil if it could not be found.
+func (l *packageLoader) packageOf(importPath string) goPackage {
+	projectPath, err := utils.GetProjectImportPath()
+	if err == nil && (importPath == projectPath || strings.HasPrefix(importPath, projectPath+"/")) {
+		return goPackage{fs: l.fs.Fs, dir: strings.TrimPrefix(strings.TrimPrefix(importPath, projectPath), "/")}
+	}
+	if dir := goListDir(importPath); dir != "" {
+		return goPackage{fs: l.osFs, dir: dir}
+	}
+	return goPackage{}
+}
+
+// readPackage returns the sources of the go files of the package, test files are
+// skipped. The sources given to the loader come first.
+func (l *packageLoader) readPackage(pkg goPackage) ([]goSource, error) {
+	srcs := []goSource{}
+	for p, s := range l.src {
+		if pkg.fs == l.fs.Fs && path.Dir(p) == pkg.dir {
+			srcs = append(srcs, goSource{path: p, src: s})
+		}
+	}
+	if b, err := afero.DirExists(pkg.fs, pkg.dir); err != nil || !b {
+		return srcs, err
+	}
+	infos, err := afero.ReadDir(pkg.fs, pkg.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") || strings.HasSuffix(info.Name(), "_test.go") {
+			continue
+		}
+		p := path.Join(pkg.dir, info.Name())
+		if _, ok := l.src[p]; ok && pkg.fs == l.fs.Fs {
+			continue
+		}
+		src, err := afero.ReadFile(pkg.fs, p)
+		if err != nil {
+			return nil, err
+		}
+		srcs = append(srcs, goSource{path: p, src: string(src)})
+	}
+	return srcs, nil
+}
+
+// goListDir returns the folder of the package with the given import path.
+func goListDir(importPath string) string {
+	if dir, ok := goListDirs[importPath]; ok {
+		return dir
+	}
+	cmd := exec.Command("go", "list", "-find", "-f", "{{.Dir}}", importPath)
+	if dir, err := utils.GetProjectDir(); err == nil {
+		cmd.Dir = dir
+	}
+	out, _ := cmd.Output()
+	goListDirs[importPath] = strings.TrimSpace(string(out))
+	return goListDirs[importPath]
+}
+
+// resolveMethod qualifies the types of the parameters and results of the method.
+func (l *packageLoader) resolveMethod(m parser.Method, scope *types.Scope) parser.Method {
+	q := m
+	q.Parameters = l.resolveTypes(m.Parameters, scope)
+	q.Results = l.resolveTypes(m.Results, scope)
+	return q
+}
+
+// resolveStruct qualifies the types of the fields of the structure.
+func (l *packageLoader) resolveStruct(s parser.Struct, scope *types.Scope) parser.Struct {
+	q := s
+	q.Vars = l.resolveTypes(s.Vars, scope)
+	return q
+}
+
+func (l *packageLoader) resolveTypes(vs []parser.NamedTypeValue, scope *types.Scope) []parser.NamedTypeValue {
+	q := make([]parser.NamedTypeValue, len(vs))
+	for i, v := range vs {
+		v.Type = l.resolveType(v.Type, scope)
+		q[i] = v
+	}
+	return q
+}
+
+// resolveType qualifies the identifiers of the type that are declared outside the
+// service package e.x the types of dot imports or of the package of an embedded
+// interface, the existing qualifiers are replaced with the qualifiers of their packages.
+func (l *packageLoader) resolveType(tp string, scope *types.Scope) string {
+	if strings.HasPrefix(tp, "...") {
+		return "..." + l.resolveType(tp[3:], scope)
+	}
+	e, err := goparser.ParseExpr(tp)
+	if err != nil {
+		return tp
+	}
+	l.qualifyIdents(e, scope)
+	return parser.TypeString(e)
+}
+
+func (l *packageLoader) qualifyIdents(e ast.Node, scope *types.Scope) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok {
+				if pn, ok := scope.Lookup(x.Name).(*types.PkgName); ok {
+					x.Name = l.qualifier(pn.Imported())
+				}
+			}
+			return false
+		case *ast.Field:
+			l.qualifyIdents(n.Type, scope)
+			return false
+		case *ast.Ident:
+			_, obj := scope.LookupParent(n.Name, token.NoPos)
+			switch obj.(type) {
+			case *types.TypeName, *types.Const:
+				if obj.Pkg() != nil && obj.Pkg().Path() != l.root.Path() {
+					n.Name = l.qualifier(obj.Pkg()) + "." + n.Name
+				}
+			}
+		}
+		return true
+	})
+}
+
+// qualifier returns the qualifier of the package, packages with the same name get a
+// number suffix.
+func (l *packageLoader) qualifier(p *types.Package) string {
+	if q, ok := l.qualifiers[p.Path()]; ok {
+		return q
+	}
+	used := map[string]bool{}
+	for _, q := range l.qualifiers {
+		used[q] = true
+	}
+	q := p.Name()
+	for i := 1; used[q]; i++ {
+		q = fmt.Sprintf("%s%d", p.Name(), i)
+	}
+	l.qualifiers[p.Path()] = q
+	return q
+}
@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseServiceFile(t *testing.T) {
+	setDefaults()
+	projectPath, _ := utils.GetProjectImportPath()
+	fs.Get().WriteFile("emb_svc/pkg/api/api.go", `package api
+
+import "context"
+
+type User struct{}
+
+// WriteAPI writes users.
+type WriteAPI interface {
+	Put(ctx context.Context, u User, us []*User) error
+}
+`, true)
+	fs.Get().WriteFile("emb_svc/pkg/service/read.go", `package service
+
+import "context"
+
+// ReadAPI reads items.
+type ReadAPI interface {
+	PingAPI
+	Get(ctx context.Context, id string) (Item, error)
+}
+`, true)
+	svc := `package service
+
+import (
+	"context"
+
+	"` + projectPath + `/emb_svc/pkg/api"
+)
+
+type PingAPI interface {
+	Ping(ctx context.Context) error
+}
+
+// EmbSvcService describes the service.
+type EmbSvcService interface {
+	ReadAPI
+	api.WriteAPI
+	Foo(ctx context.Context) error
+}
+`
+	fs.Get().WriteFile("emb_svc/pkg/service/service.go", svc, true)
+	Convey("Test if the embedded interfaces are flattened into the method set", t, func() {
+		f, err := parseServiceFile(fs.Get(), svc, "emb_svc/pkg/service/service.go")
+		So(err, ShouldBeNil)
+		iface := findInterface(f, "EmbSvcService")
+		So(iface, ShouldNotBeNil)
+		names := []string{}
+		for _, m := range iface.Methods {
+			names = append(names, m.Name)
+		}
+		So(names, ShouldResemble, []string{"Foo", "Get", "Ping", "Put"})
+		So(iface.Methods[3].Parameters[1].Type, ShouldEqual, "api.User")
+		So(iface.Methods[3].Parameters[2].Type, ShouldEqual, "[]*api.User")
+		So(iface.Methods[1].Results[0].Type, ShouldEqual, "Item")
+		Convey("Test if an interface that embeds itself is an error", func() {
+			_, err := parseServiceFile(fs.Get(), `package service
+
+type A interface {
+	B
+}
+
+type B interface {
+	A
+}
+`, "emb_svc/pkg/service/service.go")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestParseServiceFileQualifiers(t *testing.T) {
+	setDefaults()
+	projectPath, _ := utils.GetProjectImportPath()
+	fs.Get().WriteFile("qual_svc/pkg/models/models.go", "package models\n\ntype User struct{}\n", true)
+	fs.Get().WriteFile("qual_svc/pkg/oldmodels/models.go", "package oldmodels\n\ntype User struct{}\n", true)
+	fs.Get().WriteFile("qual_svc/pkg/types/types.go", "package types\n\ntype Token string\n", true)
+	fs.Get().WriteFile("qual_svc/pkg/service/sibling.go", `package service
+
+import . "`+projectPath+`/qual_svc/pkg/types"
+
+type Sibling struct {
+	Token Token
+}
+`, true)
+	svc := `package service
+
+import (
+	"context"
+
+	m "` + projectPath + `/qual_svc/pkg/models"
+	"` + projectPath + `/qual_svc/pkg/oldmodels"
+	. "` + projectPath + `/qual_svc/pkg/types"
+)
+
+// QualSvcService describes the service.
+type QualSvcService interface {
+	Foo(ctx context.Context, u m.User, o oldmodels.User, t Token, s Sibling) (us []m.User, err error)
+}
+`
+	fs.Get().WriteFile("qual_svc/pkg/service/service.go", svc, true)
+	Convey("Test if the types are qualified with the packages they are declared in", t, func() {
+		f, err := parseServiceFile(fs.Get(), svc, "qual_svc/pkg/service/service.go")
+		So(err, ShouldBeNil)
+		m := findInterface(f, "QualSvcService").Methods[0]
+		types := []string{}
+		for _, p := range append(m.Parameters, m.Results...) {
+			types = append(types, p.Type)
+		}
+		So(types, ShouldResemble, []string{"context.Context", "m.User", "oldmodels.User", "types.Token", "Sibling", "[]m.User", "error"})
+		So(f.Qualifiers, ShouldContain, parser.NewNameType("m", `"`+projectPath+`/qual_svc/pkg/models"`))
+		So(f.Qualifiers, ShouldContain, parser.NewNameType("oldmodels", `"`+projectPath+`/qual_svc/pkg/oldmodels"`))
+		So(f.Qualifiers, ShouldContain, parser.NewNameType("types", `"`+projectPath+`/qual_svc/pkg/types"`))
+		So(f.Structures, ShouldHaveLength, 1)
+		So(f.Structures[0].Vars[0].Type, ShouldEqual, "types.Token")
+		Convey("Test if the endpoints import the packages of the types", func() {
+			err := NewGenerateService("qual_svc", "http", false, false, false, nil).Generate()
+			So(err, ShouldBeNil)
+			src, err := fs.Get().ReadFile("qual_svc/pkg/endpoint/endpoint.go")
+			So(err, ShouldBeNil)
+			So(src, ShouldContainSubstring, `"`+projectPath+`/qual_svc/pkg/oldmodels"`)
+			So(src, ShouldContainSubstring, `"`+projectPath+`/qual_svc/pkg/types"`)
+			So(src, ShouldContainSubstring, "O oldmodels.User")
+			So(src, ShouldContainSubstring, "T types.Token")
+			So(src, ShouldContainSubstring, "S service.Sibling")
+			src, err = fs.Get().ReadFile("qual_svc/pkg/service/service.go")
+			So(err, ShouldBeNil)
+			So(src, ShouldContainSubstring, `types "`+projectPath+`/qual_svc/pkg/types"`)
+			So(src, ShouldContainSubstring, "u m.User, o oldmodels.User, t types.Token, s Sibling")
+		})
+	})
+}
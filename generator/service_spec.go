@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/kujtimiihoxha/kit/parser"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ServiceSpec describes the interface of a new service, it is read from the spec file
+// of `kit new service --from` or built from the methods given with `-m`.
+//
+// e.x
+//
+//	name: hello
+//	doc: HelloService greets people.
+//	imports:
+//	  - github.com/me/hello/pkg/models
+//	methods:
+//	  - Foo(ctx context.Context, s string) (string, error)
+//	  - signature: Bar(ctx context.Context, u models.User) (err error)
+//	    doc: |
+//	      Bar updates the user.
+//	      @http PUT /users
+//	transports: [http, grpc]
+type ServiceSpec struct {
+	Name string `yaml:"name"`
+	// Doc is the doc comment of the interface, by default `<Name>Service describes the service.`
+	Doc string `yaml:"doc"`
+	// Imports are the paths of the packages the methods use, the packages of the standard
+	// library are found without them. A path can be preceded by the name of the import.
+	Imports    []string     `yaml:"imports"`
+	Methods    []MethodSpec `yaml:"methods"`
+	Transports []string     `yaml:"transports"`
+}
+
+// MethodSpec is a method of the service interface, in the spec file it is either the
+// signature of the method or a map with the signature and the doc comment.
+type MethodSpec struct {
+	Signature string `yaml:"signature"`
+	Doc       string `yaml:"doc"`
+}
+
+// UnmarshalYAML reads the method from a signature or from a map.
+func (m *MethodSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&m.Signature); err == nil {
+		return nil
+	}
+	type method MethodSpec
+	return unmarshal((*method)(m))
+}
+
+// ReadServiceSpec reads the spec file at `pth`.
+func ReadServiceSpec(pth string) (s ServiceSpec, err error) {
+	b, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return s, err
+	}
+	if err := yaml.UnmarshalStrict(b, &s); err != nil {
+		return s, fmt.Errorf("%s: %s", pth, err)
+	}
+	if err := s.Validate(); err != nil {
+		return s, fmt.Errorf("%s: %s", pth, err)
+	}
+	return s, nil
+}
+
+// Validate checks that the signatures of the methods are valid go method declarations
+// and that the imports are valid paths.
+func (s ServiceSpec) Validate() error {
+	names := map[string]bool{}
+	for _, m := range s.Methods {
+		name, err := methodName(m.Signature)
+		if err != nil {
+			return err
+		}
+		if names[name] {
+			return fmt.Errorf("the method `%s` is declared more than once", name)
+		}
+		names[name] = true
+	}
+	for _, v := range s.Imports {
+		if _, err := specImport(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// methodName parses the signature of a method and returns its name.
+func methodName(sig string) (string, error) {
+	f, err := goparser.ParseFile(
+		token.NewFileSet(), "", "package p\n\ntype s interface {\n"+sig+"\n}\n", 0,
+	)
+	if err != nil {
+		return "", fmt.Errorf("the method `%s` is not a valid method signature", sig)
+	}
+	methods := f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.InterfaceType).Methods.List
+	if len(methods) != 1 || len(methods[0].Names) != 1 {
+		return "", fmt.Errorf("the method `%s` is not a valid method signature", sig)
+	}
+	return methods[0].Names[0].Name, nil
+}
+
+// specImport returns the import of a spec import path e.x `m github.com/me/models`.
+func specImport(v string) (parser.NamedTypeValue, error) {
+	fields := strings.Fields(v)
+	switch len(fields) {
+	case 1:
+		return parser.NamedTypeValue{Type: strconv.Quote(fields[0])}, nil
+	case 2:
+		return parser.NamedTypeValue{Name: fields[0], Type: strconv.Quote(fields[1])}, nil
+	}
+	return parser.NamedTypeValue{}, errors.New("the import `" + v + "` is not a valid import")
+}
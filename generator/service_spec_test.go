@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_methodName(t *testing.T) {
+	tests := []struct {
+		name    string
+		sig     string
+		want    string
+		wantErr bool
+	}{
+		{"Method", "Foo(ctx context.Context, s string) (string, error)", "Foo", false},
+		{"Not closed", "Foo(ctx context.Context", "", true},
+		{"Two methods", "Foo(); Bar()", "", true},
+		{"Embedded interface", "io.Reader", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := methodName(tt.sig)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("methodName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("methodName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadServiceSpec(t *testing.T) {
+	f, _ := ioutil.TempFile("", "spec*.yaml")
+	defer os.Remove(f.Name())
+	f.WriteString(`name: hello
+doc: HelloService greets people.
+imports:
+  - m github.com/me/models
+methods:
+  - Foo(ctx context.Context, s string) (string, error)
+  - signature: Bar(ctx context.Context, u m.User) (err error)
+    doc: Bar stores the user.
+transports: [http, grpc]
+`)
+	f.Close()
+	Convey("Test if the methods are read from signatures and maps", t, func() {
+		s, err := ReadServiceSpec(f.Name())
+		So(err, ShouldBeNil)
+		So(s, ShouldResemble, ServiceSpec{
+			Name:    "hello",
+			Doc:     "HelloService greets people.",
+			Imports: []string{"m github.com/me/models"},
+			Methods: []MethodSpec{
+				{Signature: "Foo(ctx context.Context, s string) (string, error)"},
+				{Signature: "Bar(ctx context.Context, u m.User) (err error)", Doc: "Bar stores the user."},
+			},
+			Transports: []string{"http", "grpc"},
+		})
+	})
+	Convey("Test if the unknown fields are reported", t, func() {
+		ioutil.WriteFile(f.Name(), []byte("method: []\n"), 0644)
+		_, err := ReadServiceSpec(f.Name())
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,511 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/emicklei/proto"
+	"github.com/emicklei/proto-contrib/pkg/protofmt"
+	"github.com/kujtimiihoxha/kit/fs"
+	"github.com/kujtimiihoxha/kit/parser"
+	"github.com/kujtimiihoxha/kit/utils"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// generatedMethodFunc matches the functions that kit generates once per service method,
+// the first group is the name of the method.
+var generatedMethodFunc = regexp.MustCompile(`^(?:Make(\w+)Endpoint|make(\w+)Handler|make(\w+)Codec)$`)
+
+// SyncService implements Gen and is used to remove the generated code of the methods
+// that are no longer part of the service interface.
+type SyncService struct {
+	BaseGenerator
+	name              string
+	interfaceName     string
+	serviceStructName string
+	destPath          string
+	filePath          string
+	file              *parser.File
+	serviceInterface  parser.Interface
+}
+
+// NewSyncService returns a initialized and ready generator.
+func NewSyncService(name string) Gen {
+	i := &SyncService{
+		name:          name,
+		interfaceName: utils.ToCamelCase(name + "Service"),
+		destPath:      fmt.Sprintf(viper.GetString("gk_service_path_format"), utils.ToLowerSnakeCase(name)),
+	}
+	i.filePath = path.Join(i.destPath, viper.GetString("gk_service_file_name"))
+	i.serviceStructName = utils.ToLowerFirstCamelCase(viper.GetString("gk_service_struct_prefix") + "-" + i.interfaceName)
+	i.fs = fs.Get()
+	return i
+}
+
+// Generate removes the stale methods from every layer and regenerates the files
+// that kit owns so they only reference the methods of the service.
+func (g *SyncService) Generate() (err error) {
+	if b, err := g.fs.Exists(g.filePath); err != nil {
+		return err
+	} else if !b {
+		return fmt.Errorf("service %s was not found", g.name)
+	}
+	svcSrc, err := g.fs.ReadFile(g.filePath)
+	if err != nil {
+		return err
+	}
+	g.file, err = parseServiceFile(g.fs, svcSrc, g.filePath)
+	if err != nil {
+		return err
+	}
+	if !g.serviceFound() {
+		return fmt.Errorf("could not find the service interface in `%s`", g.name)
+	}
+	g.removeBadMethods()
+	stale, err := g.staleMethods()
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		logrus.Info("The generated code is in sync with the service")
+		return nil
+	}
+	if len(g.serviceInterface.Methods) == 0 {
+		return errors.New("the service has no suitable methods please implement the interface methods")
+	}
+	logrus.Infof("Removing the methods: %s", strings.Join(stale, ", "))
+	for _, dir := range g.layerPaths() {
+		if err = g.pruneDir(dir, stale); err != nil {
+			return err
+		}
+	}
+	if err = g.pruneProto(stale); err != nil {
+		return err
+	}
+	return g.regenerate()
+}
+
+func (g *SyncService) serviceFound() bool {
+	for _, v := range g.file.Interfaces {
+		if v.Name == g.interfaceName {
+			g.serviceInterface = v
+			return true
+		}
+	}
+	return false
+}
+
+// removeBadMethods removes the methods that kit does not generate code for, the
+// transport generators warn about them when the files are regenerated.
+func (g *SyncService) removeBadMethods() {
+	keepMethods := []parser.Method{}
+	for _, v := range g.serviceInterface.Methods {
+		if unsupportedMethod(v) == "" {
+			keepMethods = append(keepMethods, v)
+		}
+	}
+	g.serviceInterface.Methods = keepMethods
+}
+
+// layerPaths returns the folders of the service, the endpoints, the transports and the clients.
+func (g *SyncService) layerPaths() []string {
+	n := utils.ToLowerSnakeCase(g.name)
+	dirs := []string{
+		g.destPath,
+		fmt.Sprintf(viper.GetString("gk_endpoint_path_format"), n),
+	}
+	for _, t := range SupportedTransports {
+		dirs = append(
+			dirs,
+			fmt.Sprintf(viper.GetString("gk_"+t+"_path_format"), n),
+			fmt.Sprintf(viper.GetString("gk_"+t+"_client_path_format"), n),
+		)
+	}
+	return dirs
+}
+
+// staleMethods returns the methods that have generated endpoints or handlers
+// but are no longer part of the service interface.
+func (g *SyncService) staleMethods() ([]string, error) {
+	current := map[string]bool{}
+	for _, m := range g.serviceInterface.Methods {
+		current[m.Name] = true
+	}
+	found := map[string]bool{}
+	for _, dir := range g.layerPaths() {
+		files, err := g.goFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			src, err := g.fs.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			file, err := goparser.ParseFile(token.NewFileSet(), f, src, 0)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range file.Decls {
+				fn, ok := d.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil {
+					continue
+				}
+				m := generatedMethodFunc.FindStringSubmatch(fn.Name.Name)
+				if m == nil {
+					continue
+				}
+				for _, name := range m[1:] {
+					if name != "" && !current[name] {
+						found[name] = true
+					}
+				}
+			}
+		}
+	}
+	stale := []string{}
+	for k := range found {
+		stale = append(stale, k)
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// goFiles returns the go files of `dir` that are not regenerated on every run.
+func (g *SyncService) goFiles(dir string) ([]string, error) {
+	if b, err := g.fs.Exists(dir); err != nil || !b {
+		return nil, err
+	}
+	infos, err := afero.ReadDir(g.fs.Fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") ||
+			strings.HasSuffix(info.Name(), "_gen.go") || strings.HasSuffix(info.Name(), "_test.go") {
+			continue
+		}
+		files = append(files, path.Join(dir, info.Name()))
+	}
+	return files, nil
+}
+
+func (g *SyncService) pruneDir(dir string, stale []string) error {
+	files, err := g.goFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		src, err := g.fs.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		pruned, err := g.pruneSource(f, src, stale)
+		if err != nil {
+			return err
+		}
+		if pruned == src {
+			continue
+		}
+		needed, err := neededImports(f, pruned)
+		if err != nil {
+			return err
+		}
+		if pruned, err = removeImports(pruned, needed); err != nil {
+			return err
+		}
+		if err = g.fs.WriteFile(f, pruned, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneSource removes the declarations generated for the stale methods from `src`, the
+// implementations of the methods in the service struct are kept as they are not generated.
+func (g *SyncService) pruneSource(name, src string, stale []string) (string, error) {
+	names := map[string]bool{}
+	types := map[string]bool{}
+	methods := map[string]bool{}
+	// the endpoint variables and fields of the clients.
+	vars := map[string]bool{}
+	fields := map[string]bool{}
+	for _, m := range stale {
+		for _, f := range []string{
+			"Make%sEndpoint", "make%sHandler", "make%sCodec",
+			"decode%sRequest", "encode%sResponse", "encode%sRequest", "decode%sResponse",
+		} {
+			names[fmt.Sprintf(f, m)] = true
+		}
+		types[m+"Request"] = true
+		types[m+"Response"] = true
+		methods[m] = true
+		fields[m+"Endpoint"] = true
+		vars[utils.ToLowerFirstCamelCase(m)+"Endpoint"] = true
+	}
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, name, src, goparser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	offset := func(p token.Pos) int {
+		return fset.Position(p).Offset
+	}
+	cuts := [][2]int{}
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			remove := false
+			if d.Recv == nil {
+				remove = names[d.Name.Name]
+			} else if recv := receiverType(d.Recv); types[recv] {
+				remove = true
+			} else if methods[d.Name.Name] {
+				if recv == g.serviceStructName {
+					logrus.Warnf("The implementation of `%s` in `%s` is kept, remove it if it is not needed", d.Name.Name, name)
+				} else {
+					remove = true
+				}
+			}
+			if remove {
+				start := d.Pos()
+				if d.Doc != nil {
+					start = d.Doc.Pos()
+				}
+				cuts = append(cuts, [2]int{offset(start), offset(d.End())})
+				continue
+			}
+			if d.Body == nil {
+				continue
+			}
+			ast.Inspect(d.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.BlockStmt:
+					for i, st := range n.List {
+						if !declaresVar(st, vars) {
+							continue
+						}
+						end := st.End()
+						if i+1 < len(n.List) {
+							if b, ok := n.List[i+1].(*ast.BlockStmt); ok {
+								end = b.End()
+							}
+						}
+						cuts = append(cuts, [2]int{offset(st.Pos()), offset(end)})
+					}
+				case *ast.CompositeLit:
+					for _, e := range n.Elts {
+						if kv, ok := e.(*ast.KeyValueExpr); ok {
+							if id, ok := kv.Key.(*ast.Ident); ok && fields[id.Name] {
+								cuts = append(cuts, elementCut(src, offset(kv.Pos()), offset(kv.End())))
+							}
+						}
+					}
+				}
+				return true
+			})
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range d.Specs {
+				ts := s.(*ast.TypeSpec)
+				if !types[ts.Name.Name] {
+					continue
+				}
+				if len(d.Specs) == 1 {
+					start := d.Pos()
+					if d.Doc != nil {
+						start = d.Doc.Pos()
+					}
+					cuts = append(cuts, [2]int{offset(start), offset(d.End())})
+				} else {
+					start := ts.Pos()
+					if ts.Doc != nil {
+						start = ts.Doc.Pos()
+					}
+					cuts = append(cuts, [2]int{offset(start), offset(ts.End())})
+				}
+			}
+		}
+	}
+	if len(cuts) == 0 {
+		return src, nil
+	}
+	sort.Slice(cuts, func(i, j int) bool {
+		return cuts[i][0] < cuts[j][0]
+	})
+	return cutSource(src, cuts), nil
+}
+
+// elementCut returns the range of offsets to remove for the element of a composite
+// literal that starts at `start` and ends at `end`, with its comma and the spaces after
+// it or the comma before it if it is the last element.
+func elementCut(src string, start, end int) [2]int {
+	rest := strings.TrimLeft(src[end:], " \t")
+	if strings.HasPrefix(rest, ",") {
+		end = len(src) - len(rest) + 1
+		if !strings.HasPrefix(strings.TrimLeft(src[end:], " \t"), "\n") {
+			end = len(src) - len(strings.TrimLeft(src[end:], " \t"))
+		}
+		return [2]int{start, end}
+	}
+	before := strings.TrimRight(src[:start], " \t")
+	if strings.HasSuffix(before, ",") {
+		start = len(before) - 1
+	}
+	return [2]int{start, end}
+}
+
+// declaresVar returns true if `st` declares one of `vars`.
+func declaresVar(st ast.Stmt, vars map[string]bool) bool {
+	ds, ok := st.(*ast.DeclStmt)
+	if !ok {
+		return false
+	}
+	gd, ok := ds.Decl.(*ast.GenDecl)
+	if !ok || gd.Tok != token.VAR {
+		return false
+	}
+	for _, sp := range gd.Specs {
+		if vs, ok := sp.(*ast.ValueSpec); ok {
+			for _, n := range vs.Names {
+				if vars[n.Name] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// receiverType returns the name of the receiver type without the pointer.
+func receiverType(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	tp := recv.List[0].Type
+	if s, ok := tp.(*ast.StarExpr); ok {
+		tp = s.X
+	}
+	if id, ok := tp.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// pruneProto removes the rpc and the messages of the stale methods from the proto file.
+func (g *SyncService) pruneProto(stale []string) error {
+	n := utils.ToLowerSnakeCase(g.name)
+	pbFilePath := path.Join(
+		fmt.Sprintf(viper.GetString("gk_grpc_pb_path_format"), n),
+		fmt.Sprintf(viper.GetString("gk_grpc_pb_file_name"), n),
+	)
+	if b, err := g.fs.Exists(pbFilePath); err != nil || !b {
+		return err
+	}
+	src, err := g.fs.ReadFile(pbFilePath)
+	if err != nil {
+		return err
+	}
+	definition, err := proto.NewParser(strings.NewReader(src)).Parse()
+	if err != nil {
+		return err
+	}
+	remove := map[string]bool{}
+	for _, m := range stale {
+		remove[m] = true
+		remove[m+"Request"] = true
+		remove[m+"Reply"] = true
+	}
+	elements := []proto.Visitee{}
+	for _, e := range definition.Elements {
+		switch e := e.(type) {
+		case *proto.Message:
+			if remove[e.Name] {
+				continue
+			}
+		case *proto.Service:
+			rpcs := []proto.Visitee{}
+			for _, r := range e.Elements {
+				if rpc, ok := r.(*proto.RPC); ok && remove[rpc.Name] {
+					continue
+				}
+				rpcs = append(rpcs, r)
+			}
+			e.Elements = rpcs
+		}
+		elements = append(elements, e)
+	}
+	definition.Elements = elements
+	buf := new(bytes.Buffer)
+	protofmt.NewFormatter(buf, " ").Format(definition)
+	return g.fs.WriteFile(pbFilePath, buf.String(), true)
+}
+
+// regenerate rewrites the files that kit generates from the service interface for the
+// transports that exist, the endpoint and service middleware settings are read from
+// the existing cmd files.
+func (g *SyncService) regenerate() error {
+	n := utils.ToLowerSnakeCase(g.name)
+	if err := newGenerateServiceEndpointsBase(g.name, g.serviceInterface).Generate(); err != nil {
+		return err
+	}
+	for _, t := range SupportedTransports {
+		handler := path.Join(
+			fmt.Sprintf(viper.GetString("gk_"+t+"_path_format"), n),
+			viper.GetString("gk_"+t+"_file_name"),
+		)
+		if b, err := g.fs.Exists(handler); err != nil {
+			return err
+		} else if !b {
+			continue
+		}
+		gorillaMux := false
+		if t == "http" {
+			base, _ := g.fs.ReadFile(path.Join(
+				fmt.Sprintf(viper.GetString("gk_http_path_format"), n),
+				viper.GetString("gk_http_base_file_name"),
+			))
+			gorillaMux = strings.Contains(base, `"github.com/gorilla/mux"`)
+		}
+		if err := NewGenerateTransport(g.name, gorillaMux, t, nil).Generate(); err != nil {
+			return err
+		}
+	}
+	cmdBase, _ := g.fs.ReadFile(path.Join(
+		fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), n),
+		viper.GetString("gk_cmd_base_file_name"),
+	))
+	err := newGenerateCmdBase(
+		g.name,
+		g.serviceInterface,
+		strings.Contains(cmdBase, "func addDefaultServiceMiddleware"),
+		strings.Contains(cmdBase, "func addDefaultEndpointMiddleware"),
+		nil,
+	).Generate()
+	if err != nil {
+		return err
+	}
+	openapi := path.Join(
+		fmt.Sprintf(viper.GetString("gk_http_path_format"), n),
+		viper.GetString("gk_openapi_file_name"),
+	)
+	if b, err := g.fs.Exists(openapi); err != nil {
+		return err
+	} else if b {
+		return NewGenerateOpenAPI(g.name).Generate()
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kujtimiihoxha/kit/fs"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSyncService_Generate(t *testing.T) {
+	setDefaults()
+	svc := `package service
+
+import "context"
+
+// SyncSvcService describes the service.
+type SyncSvcService interface {
+	Foo(ctx context.Context, s string) (r string, err error)
+	Bar(ctx context.Context, n int) (i int, err error)
+}
+`
+	fs.Get().WriteFile("sync_svc/pkg/service/service.go", svc, true)
+	err := NewGenerateService("sync_svc", "http", true, false, true, nil).Generate()
+	Convey("Test if the stale methods are removed from every layer", t, func() {
+		So(err, ShouldBeNil)
+		s, _ := fs.Get().ReadFile("sync_svc/pkg/service/service.go")
+		fs.Get().WriteFile(
+			"sync_svc/pkg/service/service.go",
+			strings.Replace(s, "\tBar(ctx context.Context, n int) (i int, err error)\n", "", 1),
+			true,
+		)
+		So(NewSyncService("sync_svc").Generate(), ShouldBeNil)
+		for _, f := range []string{
+			"sync_svc/pkg/service/middleware.go",
+			"sync_svc/pkg/endpoint/endpoint.go",
+			"sync_svc/pkg/endpoint/endpoint_gen.go",
+			"sync_svc/pkg/http/handler.go",
+			"sync_svc/pkg/http/handler_gen.go",
+			"sync_svc/cmd/service/service_gen.go",
+		} {
+			src, _ := fs.Get().ReadFile(f)
+			So(src, ShouldNotContainSubstring, "Bar")
+			So(src, ShouldNotEqual, "")
+		}
+		src, _ := fs.Get().ReadFile("sync_svc/pkg/endpoint/endpoint.go")
+		So(src, ShouldContainSubstring, "func MakeFooEndpoint(")
+		src, _ = fs.Get().ReadFile("sync_svc/pkg/service/service.go")
+		So(src, ShouldContainSubstring, "func (b *basicSyncSvcService) Bar(")
+		Convey("Test if syncing again does not change anything", func() {
+			So(NewSyncService("sync_svc").Generate(), ShouldBeNil)
+		})
+	})
+}
+
+func TestSyncService_pruneSource(t *testing.T) {
+	g := &SyncService{serviceStructName: "basicHelloService"}
+	src := `package http
+
+func New(u string) (service.HelloService, error) {
+	var fooEndpoint endpoint.Endpoint
+	{
+		fooEndpoint = http.NewClient("POST", u, encodeFooRequest, decodeFooResponse).Endpoint()
+	}
+
+	var barEndpoint endpoint.Endpoint
+	{
+		barEndpoint = http.NewClient("POST", u, encodeBarRequest, decodeBarResponse).Endpoint()
+	}
+
+	return endpoint1.Endpoints{
+		BarEndpoint: barEndpoint,
+		FooEndpoint: fooEndpoint,
+	}, nil
+}
+
+// decodeBarResponse decodes the Bar response.
+func decodeBarResponse(_ context.Context, r *http1.Response) (interface{}, error) {
+	return nil, nil
+}
+
+func decodeFooResponse(_ context.Context, r *http1.Response) (interface{}, error) {
+	return  nil, nil // the source is not formatted again.
+}
+`
+	want := `package http
+
+func New(u string) (service.HelloService, error) {
+	var fooEndpoint endpoint.Endpoint
+	{
+		fooEndpoint = http.NewClient("POST", u, encodeFooRequest, decodeFooResponse).Endpoint()
+	}
+
+	return endpoint1.Endpoints{
+		FooEndpoint: fooEndpoint,
+	}, nil
+}
+
+func decodeFooResponse(_ context.Context, r *http1.Response) (interface{}, error) {
+	return  nil, nil // the source is not formatted again.
+}
+`
+	Convey("Test if the client code of the stale methods is removed", t, func() {
+		s, err := g.pruneSource("http.go", src, []string{"Bar"})
+		So(err, ShouldBeNil)
+		So(s, ShouldEqual, want)
+		Convey("Test if the elements of a literal on one line are removed with their comma", func() {
+			for _, lit := range []string{
+				"endpoint1.Endpoints{BarEndpoint: barEndpoint, FooEndpoint: fooEndpoint}",
+				"endpoint1.Endpoints{FooEndpoint: fooEndpoint, BarEndpoint: barEndpoint}",
+			} {
+				s, err := g.pruneSource("http.go", "package http\n\nfunc New() interface{} {\n\treturn "+lit+"\n}\n", []string{"Bar"})
+				So(err, ShouldBeNil)
+				So(s, ShouldContainSubstring, "return endpoint1.Endpoints{FooEndpoint: fooEndpoint}\n")
+			}
+		})
+	})
+}
@@ -1,42 +1,16 @@
 package main
 
 import (
-	"os"
 	"path"
-	"path/filepath"
 	"runtime"
-	"strings"
 
 	"github.com/kujtimiihoxha/kit/cmd"
-	"github.com/kujtimiihoxha/kit/utils"
-	"github.com/sirupsen/logrus"
-	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 )
 
 func main() {
 	setDefaults()
 	viper.AutomaticEnv()
-	gosrc := strings.TrimSuffix(utils.GetGOPATH(), afero.FilePathSeparator ) + afero.FilePathSeparator + "src" + afero.FilePathSeparator
-	pwd, err := os.Getwd()
-	if err != nil {
-		logrus.Error(err)
-		return
-	}
-	gosrc, err = filepath.EvalSymlinks(gosrc)
-	if err != nil {
-		logrus.Error(err)
-		return
-	}
-	pwd, err = filepath.EvalSymlinks(pwd)
-	if err != nil {
-		logrus.Error(err)
-		return
-	}
-	if !strings.HasPrefix(pwd, gosrc) {
-		logrus.Error("The project must be in the $GOPATH/src folder for the generator to work.")
-		return
-	}
 	cmd.Execute()
 }
 
@@ -51,6 +25,15 @@ func setDefaults() {
 	viper.SetDefault("gk_client_cmd_path_format", path.Join("%s", "cmd", "client"))
 	viper.SetDefault("gk_grpc_path_format", path.Join("%s", "pkg", "grpc"))
 	viper.SetDefault("gk_grpc_pb_path_format", path.Join("%s", "pkg", "grpc", "pb"))
+	viper.SetDefault("gk_thrift_path_format", path.Join("%s", "pkg", "thrift"))
+	viper.SetDefault("gk_thrift_gen_path_format", path.Join("%s", "pkg", "thrift", "gen"))
+	viper.SetDefault("gk_thrift_client_path_format", path.Join("%s", "client", "thrift"))
+	viper.SetDefault("gk_nats_path_format", path.Join("%s", "pkg", "nats"))
+	viper.SetDefault("gk_nats_client_path_format", path.Join("%s", "client", "nats"))
+	viper.SetDefault("gk_amqp_path_format", path.Join("%s", "pkg", "amqp"))
+	viper.SetDefault("gk_amqp_client_path_format", path.Join("%s", "client", "amqp"))
+	viper.SetDefault("gk_jsonrpc_path_format", path.Join("%s", "pkg", "jsonrpc"))
+	viper.SetDefault("gk_jsonrpc_client_path_format", path.Join("%s", "client", "jsonrpc"))
 
 	viper.SetDefault("gk_service_file_name", "service.go")
 	viper.SetDefault("gk_service_middleware_file_name", "middleware.go")
@@ -66,11 +49,28 @@ func setDefaults() {
 	viper.SetDefault("gk_grpc_pb_file_name", "%s.proto")
 	viper.SetDefault("gk_grpc_base_file_name", "handler_gen.go")
 	viper.SetDefault("gk_grpc_file_name", "handler.go")
+	viper.SetDefault("gk_thrift_idl_file_name", "%s.thrift")
+	viper.SetDefault("gk_thrift_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_thrift_file_name", "handler.go")
+	viper.SetDefault("gk_thrift_client_file_name", "thrift.go")
+	viper.SetDefault("gk_nats_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_nats_file_name", "handler.go")
+	viper.SetDefault("gk_nats_client_file_name", "nats.go")
+	viper.SetDefault("gk_amqp_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_amqp_file_name", "handler.go")
+	viper.SetDefault("gk_amqp_client_file_name", "amqp.go")
+	viper.SetDefault("gk_jsonrpc_base_file_name", "handler_gen.go")
+	viper.SetDefault("gk_jsonrpc_file_name", "handler.go")
+	viper.SetDefault("gk_jsonrpc_client_file_name", "jsonrpc.go")
+	viper.SetDefault("gk_openapi_file_name", "openapi.yaml")
 	if runtime.GOOS == "windows" {
 		viper.SetDefault("gk_grpc_compile_file_name", "compile.bat")
+		viper.SetDefault("gk_thrift_compile_file_name", "compile.bat")
 	} else {
 		viper.SetDefault("gk_grpc_compile_file_name", "compile.sh")
+		viper.SetDefault("gk_thrift_compile_file_name", "compile.sh")
 	}
 	viper.SetDefault("gk_service_struct_prefix", "basic")
+	viper.SetDefault("gk_json_naming", "snake")
 
 }
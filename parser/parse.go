@@ -7,7 +7,9 @@ import (
 	"go/format"
 	"go/parser"
 	"go/token"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/kujtimiihoxha/kit/utils"
 	"github.com/sirupsen/logrus"
@@ -64,7 +66,7 @@ func (fp *FileParser) Parse(src []byte) (*File, error) {
 			case token.VAR:
 				f.Vars = append(f.Vars, fp.parseVars(dec.Specs)...)
 			case token.TYPE:
-				fp.parseType(dec.Specs, &f)
+				fp.parseType(dec, &f)
 			default:
 				logrus.Info("Skipping unknown Token Type")
 			}
@@ -73,23 +75,31 @@ func (fp *FileParser) Parse(src []byte) (*File, error) {
 	//fmt.Println(f.String())
 	return &f, nil
 }
-func (fp *FileParser) parseType(ds []ast.Spec, f *File) {
-	for _, sp := range ds {
+func (fp *FileParser) parseType(dec *ast.GenDecl, f *File) {
+	for _, sp := range dec.Specs {
 		tsp, ok := sp.(*ast.TypeSpec)
 		if !ok {
 			logrus.Debug("Type spec is not TypeSpec type, odd, skipping")
 			continue
 		}
+		// the doc of a declaration without parentheses belongs to the declaration.
+		doc := tsp.Doc
+		if doc == nil && !dec.Lparen.IsValid() {
+			doc = dec.Doc
+		}
 		switch tsp.Type.(type) {
 		case *ast.InterfaceType:
 			ift := tsp.Type.(*ast.InterfaceType)
 			mth := fp.parseFieldListAsMethods(ift.Methods)
 			intr := NewInterface(tsp.Name.Name, mth)
 			intr.Methods = mth
+			intr.Embedded = fp.parseEmbeddedInterfaces(ift.Methods)
+			intr.Comment = doc.Text()
 			f.Interfaces = append(f.Interfaces, intr)
 		case *ast.StructType:
 			st := tsp.Type.(*ast.StructType)
 			str := NewStruct(tsp.Name.Name, fp.parseFieldListAsNamedTypes(st.Fields))
+			str.Comment = doc.Text()
 			f.Structures = append(f.Structures, str)
 		case *ast.FuncType:
 			st := tsp.Type.(*ast.FuncType)
@@ -192,16 +202,15 @@ func (fp *FileParser) parseFieldListAsNamedTypes(list *ast.FieldList) []NamedTyp
 			}
 			if len(names) == 0 {
 				// Anonymous named type, give it a default name
-				if strings.HasPrefix(typ, "[]") {
-					names = append(names, utils.ToLowerFirstCamelCase(typ[2:3]+fmt.Sprintf("%d", i)))
-				} else if strings.HasPrefix(typ, "*") {
-					names = append(names, utils.ToLowerFirstCamelCase(typ[1:2]+fmt.Sprintf("%d", i)))
-				} else {
-					names = append(names, utils.ToLowerFirstCamelCase(typ[:1]+fmt.Sprintf("%d", i)))
-				}
+				names = append(names, utils.ToLowerFirstCamelCase(defaultName(typ)+fmt.Sprintf("%d", i)))
+			}
+			tag := ""
+			if p.Tag != nil {
+				tag, _ = strconv.Unquote(p.Tag.Value)
 			}
 			for _, name := range names {
 				namedType := NewNameType(name, typ)
+				namedType.Tag = tag
 				logrus.Debug(fmt.Sprintf("NamedType %+v", namedType))
 				ntv = append(ntv, namedType)
 			}
@@ -209,11 +218,37 @@ func (fp *FileParser) parseFieldListAsNamedTypes(list *ast.FieldList) []NamedTyp
 	}
 	return ntv
 }
+
+// defaultName returns the first letter of the element type of `typ`, it is used to
+// name the anonymous parameters and results.
+func defaultName(typ string) string {
+	if c := strings.Index(typ, "chan "); c != -1 && !strings.HasPrefix(typ, "func") {
+		typ = typ[c+5:]
+	}
+	for typ != "" && !unicode.IsLetter(rune(typ[0])) {
+		if typ[0] == '[' {
+			// skip the length of arrays.
+			if i := strings.Index(typ, "]"); i != -1 {
+				typ = typ[i+1:]
+				continue
+			}
+		}
+		typ = typ[1:]
+	}
+	if typ == "" {
+		return "v"
+	}
+	return typ[:1]
+}
+
 func (fp *FileParser) getTypeFromExp(e ast.Expr) string {
 	tp := ""
 	switch k := e.(type) {
 	case *ast.Ident:
 		tp = k.Name
+	case *ast.BasicLit:
+		// the length of an array.
+		tp = k.Value
 	case *ast.SelectorExpr:
 		logrus.Debug("Type Selector, i.e. a third-party type")
 		selectorIdent := fp.getTypeFromExp(k.X)
@@ -221,24 +256,108 @@ func (fp *FileParser) getTypeFromExp(e ast.Expr) string {
 	case *ast.StarExpr:
 		starIndent := fp.getTypeFromExp(k.X)
 		tp = "*" + starIndent
+	case *ast.ParenExpr:
+		tp = "(" + fp.getTypeFromExp(k.X) + ")"
+	case *ast.UnaryExpr:
+		// the approximation elements of a constraint e.x `~int`.
+		tp = k.Op.String() + fp.getTypeFromExp(k.X)
+	case *ast.BinaryExpr:
+		// constant expressions of array lengths and unions of constraints.
+		tp = fp.getTypeFromExp(k.X) + " " + k.Op.String() + " " + fp.getTypeFromExp(k.Y)
 	case *ast.ArrayType:
 		arrIndent := fp.getTypeFromExp(k.Elt)
 		tp = "[]" + arrIndent
+		if k.Len != nil {
+			tp = "[" + fp.getTypeFromExp(k.Len) + "]" + arrIndent
+		}
 	case *ast.MapType:
 		key := fp.getTypeFromExp(k.Key)
 		value := fp.getTypeFromExp(k.Value)
 		tp = "map[" + key + "]" + value
+	case *ast.IndexExpr:
+		// generic instantiations e.x `Page[User]`.
+		tp = fp.getTypeFromExp(k.X) + "[" + fp.getTypeFromExp(k.Index) + "]"
+	case *ast.IndexListExpr:
+		tp = fp.getTypeFromExp(k.X) + "[" + fp.joinTypes(k.Indices) + "]"
 	case *ast.InterfaceType:
-		tp = "interface{}"
+		tp = "interface{" + fp.joinFields(k.Methods, "; ", true) + "}"
+	case *ast.StructType:
+		tp = "struct{" + fp.joinFields(k.Fields, "; ", false) + "}"
+	case *ast.FuncType:
+		tp = "func" + fp.signature(k)
 	case *ast.Ellipsis:
 		t := fp.getTypeFromExp(k.Elt)
 		tp = "..." + t
+	case *ast.ChanType:
+		t := fp.getTypeFromExp(k.Value)
+		switch k.Dir {
+		case ast.RECV:
+			tp = "<-chan " + t
+		case ast.SEND:
+			tp = "chan<- " + t
+		default:
+			if c, ok := k.Value.(*ast.ChanType); ok && c.Dir == ast.RECV {
+				// `chan <-chan int` would be read as `chan<- chan int`.
+				t = "(" + t + ")"
+			}
+			tp = "chan " + t
+		}
 	default:
 		logrus.Info("Type Expresion not supported")
 		return ""
 	}
 	return tp
 }
+
+func (fp *FileParser) joinTypes(list []ast.Expr) string {
+	s := []string{}
+	for _, e := range list {
+		s = append(s, fp.getTypeFromExp(e))
+	}
+	return strings.Join(s, ", ")
+}
+
+// joinFields returns the source of the fields of a struct, an interface or a signature,
+// the fields of interfaces are methods if they are named.
+func (fp *FileParser) joinFields(list *ast.FieldList, sep string, methods bool) string {
+	if list == nil {
+		return ""
+	}
+	s := []string{}
+	for _, f := range list.List {
+		names := []string{}
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		fs := strings.Join(names, ", ")
+		if ft, ok := f.Type.(*ast.FuncType); ok && methods && len(names) > 0 {
+			fs += fp.signature(ft)
+		} else if fs != "" {
+			fs += " " + fp.getTypeFromExp(f.Type)
+		} else {
+			fs = fp.getTypeFromExp(f.Type)
+		}
+		if f.Tag != nil {
+			fs += " " + f.Tag.Value
+		}
+		s = append(s, fs)
+	}
+	return strings.Join(s, sep)
+}
+
+// signature returns the parameters and the results of a function type.
+func (fp *FileParser) signature(ft *ast.FuncType) string {
+	s := "(" + fp.joinFields(ft.Params, ", ", false) + ")"
+	if ft.Results == nil || len(ft.Results.List) == 0 {
+		return s
+	}
+	rs := fp.joinFields(ft.Results, ", ", false)
+	if len(ft.Results.List) == 1 && len(ft.Results.List[0].Names) == 0 {
+		return s + " " + rs
+	}
+	return s + " (" + rs + ")"
+}
+
 func (fp *FileParser) parseFieldListAsMethods(list *ast.FieldList) []Method {
 	mth := []Method{}
 	if list != nil {
@@ -246,11 +365,14 @@ func (fp *FileParser) parseFieldListAsMethods(list *ast.FieldList) []Method {
 			switch t := p.Type.(type) {
 			case *ast.FuncType:
 				m := Method{
-					Name: p.Names[0].Name,
+					Name:    p.Names[0].Name,
+					Comment: p.Doc.Text(),
 				}
 				m.Parameters = fp.parseFieldListAsNamedTypes(t.Params)
 				m.Results = fp.parseFieldListAsNamedTypes(t.Results)
 				mth = append(mth, m)
+			case *ast.Ident, *ast.SelectorExpr:
+				// embedded interfaces are parsed by parseEmbeddedInterfaces.
 			default:
 				logrus.Info("Skipping unknown type")
 			}
@@ -259,6 +381,25 @@ func (fp *FileParser) parseFieldListAsMethods(list *ast.FieldList) []Method {
 	return mth
 }
 
+// parseEmbeddedInterfaces returns the types of the interfaces embedded in the interface.
+func (fp *FileParser) parseEmbeddedInterfaces(list *ast.FieldList) []string {
+	emb := []string{}
+	if list != nil {
+		for _, p := range list.List {
+			switch p.Type.(type) {
+			case *ast.Ident, *ast.SelectorExpr:
+				emb = append(emb, fp.getTypeFromExp(p.Type))
+			}
+		}
+	}
+	return emb
+}
+
+// TypeString returns the go source of the type expression `e`.
+func TypeString(e ast.Expr) string {
+	return NewFileParser().getTypeFromExp(e)
+}
+
 // NewFileParser returns a new parser.
 func NewFileParser() *FileParser {
 	return &FileParser{}
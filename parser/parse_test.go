@@ -58,6 +58,144 @@ type MyService interface{
 		})
 	})
 }
+func TestFileParser_ParseChannels(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+
+type MyService interface{
+	Watch(ctx context.Context, in chan string, out chan<- int) (<-chan *Event, error)
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if channel types are parsed with their direction", func() {
+			m := f.Interfaces[0].Methods[0]
+			So(m.Parameters[1].Type, ShouldEqual, "chan string")
+			So(m.Parameters[2].Type, ShouldEqual, "chan<- int")
+			So(m.Results[0].Name, ShouldEqual, "e0")
+			So(m.Results[0].Type, ShouldEqual, "<-chan *Event")
+		})
+	})
+}
+func TestFileParser_ParseTypeExpressions(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+
+type MyService interface{
+	Do(ctx context.Context, a [4]byte, b [2 * N]int, c func(int, string) error, d func(x, y int) (r int, err error)) error
+	Types(ctx context.Context, s struct{ Name string ` + "`json:\"name\"`" + `; Age int }, p Page[User], m Map[string, *User]) (i interface{ Close() error }, e error)
+	Chans(ctx context.Context, c chan (<-chan int), f func(), v ...[]string) (<-chan func() []byte, error)
+	Anonymous(context.Context, [4]byte, func() error, struct{}) (error)
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the type expressions are parsed as they are written", func() {
+			m := f.Interfaces[0].Methods
+			So(m[0].Parameters[1].Type, ShouldEqual, "[4]byte")
+			So(m[0].Parameters[2].Type, ShouldEqual, "[2 * N]int")
+			So(m[0].Parameters[3].Type, ShouldEqual, "func(int, string) error")
+			So(m[0].Parameters[4].Type, ShouldEqual, "func(x, y int) (r int, err error)")
+			So(m[1].Parameters[1].Type, ShouldEqual, "struct{Name string `json:\"name\"`; Age int}")
+			So(m[1].Parameters[2].Type, ShouldEqual, "Page[User]")
+			So(m[1].Parameters[3].Type, ShouldEqual, "Map[string, *User]")
+			So(m[1].Results[0].Type, ShouldEqual, "interface{Close() error}")
+			So(m[2].Parameters[1].Type, ShouldEqual, "chan (<-chan int)")
+			So(m[2].Parameters[2].Type, ShouldEqual, "func()")
+			So(m[2].Parameters[3].Type, ShouldEqual, "...[]string")
+			So(m[2].Results[0].Type, ShouldEqual, "<-chan func() []byte")
+		})
+		Convey("Test if the anonymous parameters are named after their element type", func() {
+			m := f.Interfaces[0].Methods[3]
+			So(m.Parameters[1].Name, ShouldEqual, "b1")
+			So(m.Parameters[2].Name, ShouldEqual, "f2")
+			So(m.Parameters[3].Name, ShouldEqual, "s3")
+			So(m.Parameters[3].Type, ShouldEqual, "struct{}")
+		})
+	})
+}
+func TestFileParser_ParseEmbeddedInterfaces(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+
+type MyService interface{
+	ReadAPI
+	api.WriteAPI
+	Foo(ctx context.Context) error
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the embedded interfaces are parsed apart from the methods", func() {
+			So(f.Interfaces[0].Embedded, ShouldResemble, []string{"ReadAPI", "api.WriteAPI"})
+			So(f.Interfaces[0].Methods, ShouldHaveLength, 1)
+		})
+	})
+}
+func TestFileParser_ParseMethodComments(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(
+		`package parser
+
+import "context"
+
+// MyService manages users.
+type MyService interface{
+	// GetUser returns a user.
+	// @http GET /users/{id}
+	GetUser(ctx context.Context, id int) (string, error)
+	Foo(ctx context.Context) error
+}`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the doc comments of the interface methods are parsed", func() {
+			So(f.Interfaces[0].Methods[0].Comment, ShouldEqual, "GetUser returns a user.\n@http GET /users/{id}\n")
+			So(f.Interfaces[0].Methods[1].Comment, ShouldEqual, "")
+		})
+		Convey("Test if the doc comment of the interface is parsed", func() {
+			So(f.Interfaces[0].Comment, ShouldEqual, "MyService manages users.\n")
+		})
+	})
+}
+func TestFileParser_ParseTypeComments(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte(`package main
+
+type (
+	// User is a user.
+	User struct{}
+	Item struct{}
+)
+
+// Event is an event.
+type Event struct{}
+`))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the doc comments of the types in and outside of groups are parsed", func() {
+			So(f.Structures[0].Comment, ShouldEqual, "User is a user.\n")
+			So(f.Structures[1].Comment, ShouldEqual, "")
+			So(f.Structures[2].Comment, ShouldEqual, "Event is an event.\n")
+		})
+	})
+}
+func TestFileParser_ParseStructTags(t *testing.T) {
+	fp := NewFileParser()
+	f, err := fp.Parse([]byte("package main\ntype User struct{\nName string `json:\"name\"`\nAge int\n}"))
+	Convey("Test if parser parses file without errors", t, func() {
+		So(err, ShouldBeNil)
+		Convey("Test if the tags of the struct fields are parsed", func() {
+			So(f.Structures[0].Vars[0].Tag, ShouldEqual, `json:"name"`)
+			So(f.Structures[0].Vars[1].Tag, ShouldEqual, "")
+		})
+	})
+}
 func TestFileParser_ParseStructFunction(t *testing.T) {
 	fp := NewFileParser()
 	f, err := fp.Parse([]byte(`package main
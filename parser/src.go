@@ -12,6 +12,10 @@ type File struct {
 	Interfaces []Interface
 	Structures []Struct
 	Methods    []Method
+	// Qualifiers are the packages of the qualified types of the interfaces and the
+	// structures when the file is parsed with its package, the name is the qualifier
+	// used in the types and the type is the quoted import path.
+	Qualifiers []NamedTypeValue
 }
 
 // Struct stores go struct information.
@@ -33,6 +37,9 @@ type Interface struct {
 	Name    string
 	Comment string
 	Methods []Method
+	// Embedded are the types of the embedded interfaces e.x `ReadAPI` or `io.Closer`,
+	// their methods are not part of Methods.
+	Embedded []string
 }
 
 // Method stores go method information.
@@ -50,6 +57,8 @@ type NamedTypeValue struct {
 	Name  string
 	Type  string
 	Value string
+	// Tag is the tag of a struct field without the quotes e.x json:"name".
+	Tag string
 }
 
 // NewNameType create a NamedTypeValue without a value.
@@ -84,9 +93,10 @@ func NewMethod(name string, str NamedTypeValue, body string, parameters, results
 // NewInterface creates a new interface.
 func NewInterface(name string, methods []Method) Interface {
 	return Interface{
-		Name:    name,
-		Comment: "",
-		Methods: methods,
+		Name:     name,
+		Comment:  "",
+		Methods:  methods,
+		Embedded: []string{},
 	}
 }
 
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ConfigFileName is the name of the project configuration file.
+const ConfigFileName = ".kit.yaml"
+
+// Config represents the project configuration file, e.x:
+//
+//	paths:
+//	  service: "%s/internal/service"    # gk_service_path_format
+//	files:
+//	  http: transport.go                # gk_http_file_name
+//	service_struct_prefix: default      # gk_service_struct_prefix
+//	transport: grpc                     # the default transport of `kit g s` and `kit g c`
+//	middleware:
+//	  service: true                     # the default of --svc-mdw
+//	  endpoint: true                    # the default of --endpoint-mdw
+//	json_naming: camel                  # gk_json_naming, snake or camel
+//	on_conflict: skip                   # gk_on_conflict, ask, skip, overwrite, fail or backup
+type Config struct {
+	Paths               map[string]string `yaml:"paths"`
+	Files               map[string]string `yaml:"files"`
+	ServiceStructPrefix string            `yaml:"service_struct_prefix"`
+	Transport           string            `yaml:"transport"`
+	Middleware          struct {
+		Service  *bool `yaml:"service"`
+		Endpoint *bool `yaml:"endpoint"`
+	} `yaml:"middleware"`
+	JSONNaming string `yaml:"json_naming"`
+	OnConflict string `yaml:"on_conflict"`
+}
+
+// Settings returns the configuration keys that the file sets.
+func (c Config) Settings() (map[string]interface{}, error) {
+	s := map[string]interface{}{}
+	for k, v := range c.Paths {
+		key := "gk_" + k + "_path_format"
+		if !viper.IsSet(key) {
+			return nil, fmt.Errorf("unknown path `%s`", k)
+		}
+		s[key] = v
+	}
+	for k, v := range c.Files {
+		key := "gk_" + k + "_file_name"
+		if !viper.IsSet(key) {
+			return nil, fmt.Errorf("unknown file `%s`", k)
+		}
+		s[key] = v
+	}
+	if c.ServiceStructPrefix != "" {
+		s["gk_service_struct_prefix"] = c.ServiceStructPrefix
+	}
+	if c.Transport != "" {
+		s["g_s_transport"] = c.Transport
+		s["g_c_transport"] = c.Transport
+	}
+	if c.Middleware.Service != nil {
+		s["g_s_svc_mdw"] = *c.Middleware.Service
+	}
+	if c.Middleware.Endpoint != nil {
+		s["g_s_endpoint_mdw"] = *c.Middleware.Endpoint
+	}
+	switch c.JSONNaming {
+	case "":
+	case "snake", "camel":
+		s["gk_json_naming"] = c.JSONNaming
+	default:
+		return nil, fmt.Errorf("unknown json naming `%s`, use snake or camel", c.JSONNaming)
+	}
+	switch c.OnConflict {
+	case "":
+	case "ask", "skip", "overwrite", "fail", "backup":
+		s["gk_on_conflict"] = c.OnConflict
+	default:
+		return nil, fmt.Errorf("unknown conflict policy `%s`, use ask, skip, overwrite, fail or backup", c.OnConflict)
+	}
+	return s, nil
+}
+
+// FindConfigFile looks for the nearest .kit.yaml starting from `dir` and walking up
+// the folder tree, it returns an empty string if there is none.
+func FindConfigFile(dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	for {
+		pth := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(pth); err == nil {
+			return pth, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// flags are the flags bound to the configuration keys.
+var flags = map[string]*pflag.Flag{}
+
+// BindFlag binds the configuration key to the flag like viper.BindPFlag, the flag is
+// reported by ConfigSource when it is set.
+func BindFlag(key string, f *pflag.Flag) error {
+	flags[key] = f
+	return viper.BindPFlag(key, f)
+}
+
+// LoadConfig reads the nearest .kit.yaml of the project folder (the working directory or
+// the folder set with --folder), the values of the file take precedence over the defaults
+// but not over the environment and the flags. It is called after the flags are parsed.
+func LoadConfig() error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if f := viper.GetString("gk_folder"); filepath.IsAbs(f) {
+		wd = f
+	} else if f != "" {
+		wd = filepath.Join(wd, f)
+	}
+	pth, err := FindConfigFile(wd)
+	if err != nil || pth == "" {
+		return err
+	}
+	b, err := ioutil.ReadFile(pth)
+	if err != nil {
+		return err
+	}
+	c := Config{}
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return fmt.Errorf("%s: %s", pth, err)
+	}
+	s, err := c.Settings()
+	if err != nil {
+		return fmt.Errorf("%s: %s", pth, err)
+	}
+	viper.Set("gk_config_file", pth)
+	return viper.MergeConfigMap(s)
+}
+
+// ConfigSource returns where the value of the key comes from, a flag, the environment,
+// the configuration file or the defaults.
+func ConfigSource(key string) string {
+	if f, ok := flags[key]; ok && f.Changed {
+		return "flag"
+	}
+	if v, ok := os.LookupEnv(strings.ToUpper(key)); ok && v != "" {
+		return "env"
+	}
+	if viper.InConfig(key) {
+		return viper.GetString("gk_config_file")
+	}
+	return "default"
+}
+
+// ToJSONName returns the name of a JSON field in the naming set with gk_json_naming.
+func ToJSONName(s string) string {
+	if viper.GetString("gk_json_naming") == "camel" {
+		return ToLowerFirstCamelCase(s)
+	}
+	return ToLowerSnakeCase(s)
+}
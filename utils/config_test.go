@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestLoadConfig(t *testing.T) {
+	viper.SetDefault("gk_http_file_name", "handler.go")
+	viper.SetDefault("gk_service_path_format", filepath.Join("%s", "pkg", "service"))
+	dir, _ := ioutil.TempDir("", "kit")
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.MkdirAll(filepath.Join(dir, "hello"), os.ModePerm)
+	os.Chdir(filepath.Join(dir, "hello"))
+	Convey("Test if the nearest .kit.yaml is loaded", t, func() {
+		ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte(
+			"files:\n  http: transport.go\ntransport: grpc\nmiddleware:\n  endpoint: true\njson_naming: camel\n",
+		), os.ModePerm)
+		So(LoadConfig(), ShouldBeNil)
+		So(viper.GetString("gk_http_file_name"), ShouldEqual, "transport.go")
+		So(viper.GetString("gk_service_path_format"), ShouldEqual, filepath.Join("%s", "pkg", "service"))
+		So(viper.GetString("g_s_transport"), ShouldEqual, "grpc")
+		So(viper.GetBool("g_s_endpoint_mdw"), ShouldBeTrue)
+		So(ToJSONName("userName"), ShouldEqual, "userName")
+		So(ConfigSource("gk_http_file_name"), ShouldEqual, filepath.Join(dir, ConfigFileName))
+		So(ConfigSource("gk_service_path_format"), ShouldEqual, "default")
+		Convey("Test if the environment takes precedence", func() {
+			os.Setenv("GK_HTTP_FILE_NAME", "env.go")
+			defer os.Unsetenv("GK_HTTP_FILE_NAME")
+			viper.AutomaticEnv()
+			So(viper.GetString("gk_http_file_name"), ShouldEqual, "env.go")
+			So(ConfigSource("gk_http_file_name"), ShouldEqual, "env")
+		})
+	})
+	Convey("Test if the .kit.yaml of the --folder is loaded", t, func() {
+		os.MkdirAll(filepath.Join(dir, "other"), os.ModePerm)
+		ioutil.WriteFile(filepath.Join(dir, "other", ConfigFileName), []byte("files:\n  http: other.go\n"), os.ModePerm)
+		viper.Set("gk_folder", filepath.Join("..", "other"))
+		defer viper.Set("gk_folder", "")
+		So(LoadConfig(), ShouldBeNil)
+		So(viper.GetString("gk_http_file_name"), ShouldEqual, "other.go")
+		So(ConfigSource("gk_http_file_name"), ShouldEqual, filepath.Join(dir, "other", ConfigFileName))
+	})
+	Convey("Test if the flags are reported as the source", t, func() {
+		fs := pflag.NewFlagSet("kit", pflag.ContinueOnError)
+		fs.String("http-file", "", "")
+		So(BindFlag("gk_http_file_name", fs.Lookup("http-file")), ShouldBeNil)
+		defer delete(flags, "gk_http_file_name")
+		So(ConfigSource("gk_http_file_name"), ShouldNotEqual, "flag")
+		So(fs.Parse([]string{"--http-file", "flag.go"}), ShouldBeNil)
+		So(viper.GetString("gk_http_file_name"), ShouldEqual, "flag.go")
+		So(ConfigSource("gk_http_file_name"), ShouldEqual, "flag")
+	})
+	Convey("Test if unknown settings are rejected", t, func() {
+		ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte("paths:\n  bogus: x\n"), os.ModePerm)
+		So(LoadConfig(), ShouldNotBeNil)
+		ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte("colour: red\n"), os.ModePerm)
+		So(LoadConfig(), ShouldNotBeNil)
+		ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte("json_naming: kebab\n"), os.ModePerm)
+		So(LoadConfig(), ShouldNotBeNil)
+		ioutil.WriteFile(filepath.Join(dir, ConfigFileName), []byte("on_conflict: merge\n"), os.ModePerm)
+		So(LoadConfig(), ShouldNotBeNil)
+	})
+	viper.Set("gk_json_naming", "snake")
+}
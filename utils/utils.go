@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"fmt"
@@ -53,140 +56,191 @@ func GoImportsSource(path string, s string) (string, error) {
 	return string(is), err
 }
 
+// GetProjectImportPath returns the import path of the project folder.
+func GetProjectImportPath() (string, error) {
+	return getImportPath("")
+}
+
 // GetServiceImportPath returns the import path of the service interface.
 func GetServiceImportPath(name string) (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
-	pwd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
-	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	svcPath := fmt.Sprintf(viper.GetString("gk_service_path_format"), ToLowerSnakeCase(name))
-
-	svcPath = strings.Replace(svcPath, "\\", "/", -1)
-	serviceImport := projectPath + "/" + svcPath
-	return serviceImport, nil
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_service_path_format"), ToLowerSnakeCase(name)))
 }
 
 // GetCmdServiceImportPath returns the import path of the cmd service (used by cmd/main.go).
 func GetCmdServiceImportPath(name string) (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
-	pwd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
-	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	svcPath := fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), ToLowerSnakeCase(name))
-
-	svcPath = strings.Replace(svcPath, "\\", "/", -1)
-	serviceImport := projectPath + "/" + svcPath
-	return serviceImport, nil
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_cmd_service_path_format"), ToLowerSnakeCase(name)))
 }
 
 // GetEndpointImportPath returns the import path of the service endpoints.
 func GetEndpointImportPath(name string) (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
-	pwd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
-	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	epPath := fmt.Sprintf(viper.GetString("gk_endpoint_path_format"), ToLowerSnakeCase(name))
-
-	epPath = strings.Replace(epPath, "\\", "/", -1)
-	endpointImport := projectPath + "/" + epPath
-	return endpointImport, nil
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_endpoint_path_format"), ToLowerSnakeCase(name)))
 }
 
 // GetGRPCTransportImportPath returns the import path of the service grpc transport.
 func GetGRPCTransportImportPath(name string) (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
-	pwd, err := os.Getwd()
-	if err != nil {
-		return "", err
-	}
-	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
-	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	epPath := fmt.Sprintf(viper.GetString("gk_grpc_path_format"), ToLowerSnakeCase(name))
-
-	epPath = strings.Replace(epPath, "\\", "/", -1)
-	endpointImport := projectPath + "/" + epPath
-	return endpointImport, nil
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_grpc_path_format"), ToLowerSnakeCase(name)))
 }
 
 // GetPbImportPath returns the import path of the generated service grpc pb.
 func GetPbImportPath(name string) (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
-	pwd, err := os.Getwd()
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_grpc_pb_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetHTTPTransportImportPath returns the import path of the service http transport.
+func GetHTTPTransportImportPath(name string) (string, error) {
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_http_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetThriftTransportImportPath returns the import path of the service thrift transport.
+func GetThriftTransportImportPath(name string) (string, error) {
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_thrift_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetThriftGenImportPath returns the import path of the code generated by the thrift compiler.
+func GetThriftGenImportPath(name string) (string, error) {
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_thrift_gen_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetNATSTransportImportPath returns the import path of the service nats transport.
+func GetNATSTransportImportPath(name string) (string, error) {
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_nats_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetAMQPTransportImportPath returns the import path of the service amqp transport.
+func GetAMQPTransportImportPath(name string) (string, error) {
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_amqp_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetJSONRPCTransportImportPath returns the import path of the service json-rpc transport.
+func GetJSONRPCTransportImportPath(name string) (string, error) {
+	return getImportPath(fmt.Sprintf(viper.GetString("gk_jsonrpc_path_format"), ToLowerSnakeCase(name)))
+}
+
+// GetDockerFileProjectPath returns the path of the project.
+//
+// If the project folder can not be resolved to an import path (e.x every service
+// has its own go.mod) the name of the project folder is used.
+func GetDockerFileProjectPath() (string, error) {
+	projectPath, err := getImportPath("")
+	if err == nil {
+		return projectPath, nil
+	}
+	dir, err := GetProjectDir()
 	if err != nil {
 		return "", err
 	}
-	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
-	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	epPath := fmt.Sprintf(viper.GetString("gk_grpc_pb_path_format"), ToLowerSnakeCase(name))
-
-	epPath = strings.Replace(epPath, "\\", "/", -1)
-	endpointImport := projectPath + "/" + epPath
-	return endpointImport, nil
+	return filepath.Base(dir), nil
 }
 
-// GetHTTPTransportImportPath returns the import path of the service http transport.
-func GetHTTPTransportImportPath(name string) (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
+// GetProjectDir returns the absolute path of the project folder, that is the working
+// directory or the folder set with the `folder` flag.
+func GetProjectDir() (string, error) {
 	pwd, err := os.Getwd()
 	if err != nil {
 		return "", err
 	}
 	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
+		pwd = filepath.Join(pwd, viper.GetString("gk_folder"))
 	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	epPath := fmt.Sprintf(viper.GetString("gk_http_path_format"), ToLowerSnakeCase(name))
+	return pwd, nil
+}
+
+// stagedModFiles are the go.mod files created during the run that are not written
+// to the disk yet, by folder.
+var stagedModFiles = map[string][]byte{}
 
-	epPath = strings.Replace(epPath, "\\", "/", -1)
-	httpImports := projectPath + "/" + epPath
-	return httpImports, nil
+// StageModFile makes FindModFile find the go.mod `mod` in `dir` before it is written
+// to the disk e.x when a new service is generated in the same run it is created.
+func StageModFile(dir string, mod []byte) {
+	stagedModFiles[filepath.Clean(dir)] = mod
 }
 
-// GetDockerFileProjectPath returns the path of the project.
-func GetDockerFileProjectPath() (string, error) {
-	gosrc := GetGOPATH() + "/src/"
-	gosrc = strings.Replace(gosrc, "\\", "/", -1)
-	pwd, err := os.Getwd()
+// FindModFile looks for the nearest go.mod file starting from `dir` and walking up
+// the folder tree, it returns the folder that contains the go.mod and the module path.
+//
+// If no go.mod is found both of the returned strings are empty.
+func FindModFile(dir string) (modDir string, modPath string, err error) {
+	dir = filepath.Clean(dir)
+	for {
+		b, ok := stagedModFiles[dir]
+		if !ok {
+			b, err = ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		}
+		if ok || err == nil {
+			modPath = ModulePath(b)
+			if modPath == "" {
+				return "", "", fmt.Errorf("could not find the module path in `%s`", filepath.Join(dir, "go.mod"))
+			}
+			return dir, modPath, nil
+		} else if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+		dir = parent
+	}
+}
+
+// ModulePath returns the module path declared in the given go.mod source
+// or an empty string if the module directive is missing.
+func ModulePath(mod []byte) string {
+	for _, line := range strings.Split(string(mod), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "module"))
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if p, err := strconv.Unquote(line); err == nil {
+			return p
+		}
+		return line
+	}
+	return ""
+}
+
+// getImportPath returns the import path of the package that lives in the project relative
+// `pkgPath` folder.
+//
+// The import path is derived from the nearest go.mod, if the project is not part of a module
+// the path is derived from $GOPATH/src.
+func getImportPath(pkgPath string) (string, error) {
+	projectDir, err := GetProjectDir()
 	if err != nil {
 		return "", err
 	}
-	if viper.GetString("gk_folder") != "" {
-		pwd += "/" + viper.GetString("gk_folder")
+	pkgPath = filepath.FromSlash(strings.Replace(pkgPath, "\\", "/", -1))
+	dir := filepath.Join(projectDir, pkgPath)
+	modDir, modPath, err := FindModFile(dir)
+	if err != nil {
+		return "", err
+	}
+	if modPath != "" {
+		rel, err := filepath.Rel(modDir, dir)
+		if err != nil {
+			return "", err
+		}
+		if rel == "." {
+			return modPath, nil
+		}
+		return modPath + "/" + filepath.ToSlash(rel), nil
+	}
+	gosrc := filepath.Join(GetGOPATH(), "src")
+	if s, err := filepath.EvalSymlinks(gosrc); err == nil {
+		gosrc = s
+	}
+	if s, err := filepath.EvalSymlinks(projectDir); err == nil {
+		dir = filepath.Join(s, pkgPath)
+	}
+	rel, err := filepath.Rel(gosrc, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", errors.New("could not find a go.mod and the project is not in the $GOPATH/src folder")
 	}
-	pwd = strings.Replace(pwd, "\\", "/", -1)
-	projectPath := strings.Replace(pwd, gosrc, "", 1)
-	return projectPath, nil
+	return filepath.ToSlash(rel), nil
 }
 
 // GetGOPATH returns the gopath.
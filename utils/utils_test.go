@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/spf13/viper"
 )
 
 func TestToCamelCase(t *testing.T) {
@@ -28,3 +33,33 @@ func TestToUpperFirst(t *testing.T) {
 		So(ToUpperFirst("test"), ShouldEqual, "Test")
 	})
 }
+
+func TestModulePath(t *testing.T) {
+	Convey("Test if ModulePath finds the module path", t, func() {
+		So(ModulePath([]byte("module github.com/test/hello\n\ngo 1.12\n")), ShouldEqual, "github.com/test/hello")
+		So(ModulePath([]byte("// comment\nmodule \"hello\" // my module\n")), ShouldEqual, "hello")
+		So(ModulePath([]byte("go 1.12\n")), ShouldEqual, "")
+	})
+}
+
+func TestGetServiceImportPath(t *testing.T) {
+	viper.SetDefault("gk_service_path_format", path.Join("%s", "pkg", "service"))
+	dir, _ := ioutil.TempDir("", "kit")
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+	Convey("Test if the import path is derived from the nearest go.mod", t, func() {
+		ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/test/project\n"), os.ModePerm)
+		p, err := GetServiceImportPath("hello")
+		So(err, ShouldBeNil)
+		So(p, ShouldEqual, "github.com/test/project/hello/pkg/service")
+		Convey("Test if a go.mod inside the service folder takes precedence", func() {
+			os.MkdirAll(filepath.Join(dir, "hello"), os.ModePerm)
+			ioutil.WriteFile(filepath.Join(dir, "hello", "go.mod"), []byte("module hello\n"), os.ModePerm)
+			p, err := GetServiceImportPath("hello")
+			So(err, ShouldBeNil)
+			So(p, ShouldEqual, "hello/pkg/service")
+		})
+	})
+}